@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/service/export"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// runExport implements the export command: it streams stored rates matching the flags to a file or stdout.
+func runExport(ctx context.Context, svc export.Service, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		format   = fs.String("format", "", "csv, jsonl or xlsx; by default from the -o extension, else csv")
+		out      = fs.String("o", "", "output file, stdout if empty")
+		currency = fs.String("currency", "", "currency code, e.g. USD")
+		source   = fs.String("source", "", "source ID, as in /api/sources")
+		channel  = fs.String("channel", "", "cash, non_cash, card or official")
+		city     = fs.String("city", "", "city of office rates")
+		from     = fs.String("from", "", "start, YYYY-MM-DD or RFC 3339")
+		to       = fs.String("to", "", "end, YYYY-MM-DD (whole day) or RFC 3339")
+		tz       = fs.String("tz", "", "IANA zone of timestamps and dates, local zone if empty")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: app export [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	name := *format
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(*out), ".")
+		// Неизвестное расширение файла — не повод отказываться, пишем CSV
+		if _, err := export.ParseFormat(name); err != nil {
+			name = string(export.CSV)
+		}
+	}
+	f, err := export.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	loc := time.Local
+	if *tz != "" {
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return fmt.Errorf("tz: %w", err)
+		}
+	}
+	filter := &exrate.ExchangeRateFilter{
+		CurrencyCode: *currency,
+		Source:       *source,
+		Channel:      *channel,
+		City:         *city,
+	}
+	if filter.StartDate, err = export.ParseTime(*from, false, loc); err != nil {
+		return fmt.Errorf("from: %w", err)
+	}
+	if filter.EndDate, err = export.ParseTime(*to, true, loc); err != nil {
+		return fmt.Errorf("to: %w", err)
+	}
+
+	if *out == "" {
+		_, err = export.Export(ctx, svc, filter, stdout, f, loc)
+		return err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	n, err := export.Export(ctx, svc, filter, file, f, loc)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Обрезанный файл хуже отсутствующего
+		_ = os.Remove(*out)
+		return err
+	}
+	fmt.Fprintf(stderr, "exported %d rates to %s\n", n, *out)
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	"github.com/Mi7teR/exr/internal/infrastructure/repository/sqlite"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRunExport(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, err := sqlite.NewSQLiteExchangeRateRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	at := time.Date(2024, 11, 4, 20, 0, 0, 0, time.UTC) // в Алматы уже 5 ноября
+	for _, r := range []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "497.5", Sell: "506", CreatedAt: at},
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "498", Sell: "507", CreatedAt: at.Add(-24 * time.Hour)},
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "EUR", Buy: "540", Sell: "550", CreatedAt: at},
+	} {
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uc := exrate.NewExchangeRateUsecase(repo, nil)
+
+	var stdout, stderr bytes.Buffer
+	err = runExport(ctx, uc, []string{"-format", "jsonl", "-currency", "usd", "-from", "2024-11-05", "-tz", "Asia/Almaty"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("runExport() error = %v, stderr %s", err, stderr.String())
+	}
+	want := `{"created_at":"2024-11-05T01:00:00+05:00","source":"Halyk","branch":"","branch_name":"","channel":"cash","currency":"USD",` +
+		`"quote":"KZT","buy":"497.5000","sell":"506.0000","city":"","address":""}` + "\n"
+	if stdout.String() != want {
+		t.Errorf("stdout:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	// Формат по расширению файла
+	dir := t.TempDir()
+	out := filepath.Join(dir, "usd.xlsx")
+	stdout.Reset()
+	if err = runExport(ctx, uc, []string{"-o", out, "-currency", "USD"}, &stdout, &stderr); err != nil {
+		t.Fatalf("xlsx: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !bytes.HasPrefix(data, []byte("PK")) || stdout.Len() != 0 {
+		t.Errorf("xlsx file: %d bytes, err %v, stdout %q", len(data), err, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "exported 2 rates to "+out) {
+		t.Errorf("stderr %q", stderr.String())
+	}
+
+	for _, args := range [][]string{
+		{"-format", "xls"},
+		{"-from", "05.11.2024"},
+		{"-tz", "Mars/Olympus"},
+		{"-to", "2024-11-01", "-from", "2024-11-30", "-o", filepath.Join(dir, "bad.csv")},
+	} {
+		if err = runExport(ctx, uc, args, &stdout, &stderr); err == nil {
+			t.Errorf("%v: no error", args)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "bad.csv")); !os.IsNotExist(err) {
+		t.Errorf("failed export left a file: %v", err)
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/Mi7teR/exr/internal/driver"
 	"github.com/Mi7teR/exr/internal/infrastructure/httpclient"
 	infraLogger "github.com/Mi7teR/exr/internal/infrastructure/logger"
 	"github.com/Mi7teR/exr/internal/infrastructure/repository/sqlite"
+	"github.com/Mi7teR/exr/internal/service/digest"
 	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/service/pubsub"
+	"github.com/Mi7teR/exr/internal/service/webhook"
+	"github.com/Mi7teR/exr/internal/telegram"
 	"github.com/Mi7teR/exr/internal/webserver"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -31,30 +40,230 @@ func main() {
 		log.Fatalf("migrate repo: %v", err)
 	}
 
-	// HTTP client
-	cli := httpclient.NewNetHTTPClient(l)
+	// Выгрузка истории из командной строки (go run ./cmd/app export -h): драйверы и сервер не нужны
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		uc := exrate.NewExchangeRateUsecase(repo, nil)
+		if err = runExport(context.Background(), uc, os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		return
+	}
+
+	// HTTP-клиенты: драйверы с настройками выхода (прокси, UA, заголовки, TLS) из EXR_EGRESS получают свой клиент,
+	// остальные ходят напрямую через общий
+	egress, err := loadEgress(os.Getenv("EXR_EGRESS"))
+	if err != nil {
+		log.Fatalf("egress config: %v", err)
+	}
+	clients, err := httpclient.NewPool(l, egress)
+	if err != nil {
+		log.Fatalf("http clients: %v", err)
+	}
 
-	// Инициализируем драйверы
-	drivers := map[string]exrate.Driver{
-		"Kaspi":   driver.NewKaspi("https://guide.kaspi.kz/client/api/v2/intgr/currency/rate/aggregate", cli),
-		"Halyk":   driver.NewHalyk("https://back.halykbank.kz/common/currency-history", cli),
-		"Freedom": driver.NewFreedom("https://bankffin.kz/api/exchange-rates/getRates", cli),
-		"RBK":     driver.NewRBK("https://backend.bankrbk.kz/api/v1/modules/exchange_rates/data", cli),
-		"HomeKZ":  driver.NewHome("https://home.kz/api/public/getCurrency", cli),
-		"NBRK":    driver.NewNBRK("https://nationalbank.kz/rss/rates_all.xml", cli),
+	// Реестр драйверов: каждый драйвер сам описывает свой источник (id, название, каналы, расписание)
+	reg := exrate.NewRegistry()
+	for _, d := range []exrate.DescribedDriver{
+		driver.NewKaspi("https://guide.kaspi.kz/client/api/v2/intgr/currency/rate/aggregate", clients.For("Kaspi")),
+		driver.NewHalyk("https://back.halykbank.kz/common/currency-history", clients.For("Halyk")),
+		driver.NewFreedom("https://bankffin.kz/api/exchange-rates/getRates", clients.For("Freedom")),
+		driver.NewRBK("https://backend.bankrbk.kz/api/v1/modules/exchange_rates/data", clients.For("RBK")),
+		driver.NewHome("https://home.kz/api/public/getCurrency", clients.For("HomeKZ")),
+		driver.NewNBRK("https://nationalbank.kz/rss/rates_all.xml", clients.For("NBRK")),
+		driver.NewJusan("https://jusan.kz/api/v1/exchange-rates", clients.For("Jusan")),
+		driver.NewBCC("https://www.bcc.kz/api/v1/exchange-rates", clients.For("BCC")),
+		driver.NewForte("https://forte.kz/api/currency/rates", clients.For("Forte")),
+		driver.NewEurasian("https://eubank.kz/exchange-rates/", clients.For("Eurasian")),
+		driver.NewBereke("https://berekebank.kz/api/exchange-rates", clients.For("Bereke")),
+		driver.NewKursKZ("https://kurs.kz/api/punkts",
+			strings.Split(getenv("EXR_KURSKZ_CITIES", "almaty,astana,shymkent"), ","), clients.For("KursKZ")),
+		// Иностранные ЦБ для сверки кросс-курсов, котируют в RUB/EUR/KGS
+		driver.NewCBR("https://www.cbr.ru/scripts/XML_daily.asp", clients.For("CBR")),
+		driver.NewECB("https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml", clients.For("ECB")),
+		driver.NewNBKR("https://www.nbkr.kg/XML/daily.xml", clients.For("NBKR")),
+	} {
+		if err = reg.Register(d); err != nil {
+			log.Fatalf("register driver: %v", err)
+		}
+	}
+
+	// Декларативные JSON-драйверы из конфига (EXR_JSON_DRIVERS=path/to/drivers.json)
+	if path := os.Getenv("EXR_JSON_DRIVERS"); path != "" {
+		err = addConfiguredDrivers(reg, path, driver.LoadJSONConfigs,
+			func(cfg driver.JSONConfig) (exrate.DescribedDriver, error) {
+				return driver.NewJSON(cfg, clients.For(cfg.ID))
+			})
+		if err != nil {
+			log.Fatalf("json drivers: %v", err)
+		}
+	}
+	// Скрейпинг HTML-страниц по CSS-селекторам (EXR_HTML_DRIVERS=path/to/scrapers.json)
+	if path := os.Getenv("EXR_HTML_DRIVERS"); path != "" {
+		err = addConfiguredDrivers(reg, path, driver.LoadHTMLConfigs,
+			func(cfg driver.HTMLConfig) (exrate.DescribedDriver, error) {
+				return driver.NewHTML(cfg, clients.For(cfg.ID))
+			})
+		if err != nil {
+			log.Fatalf("html drivers: %v", err)
+		}
+	}
+
+	// Опечатка в id молча отправила бы геоограниченный источник напрямую
+	for id := range egress {
+		if _, ok := reg.Drivers()[id]; !ok {
+			log.Fatalf("egress config: unknown source %q", id)
+		}
 	}
 
 	// Usecase с драйверами
-	uc := exrate.NewExchangeRateUsecase(repo, drivers)
+	uc := exrate.NewExchangeRateUsecase(repo, reg.Drivers())
+	// Курсы, сохранённые до появления каналов, продолжают ряды с каналом источника по умолчанию
+	if err := uc.BackfillChannels(context.Background()); err != nil {
+		log.Fatalf("backfill channels: %v", err)
+	}
+	// Правила проверки курсов перед сохранением (EXR_VALIDATION=path/to/rules.json), иначе значения по умолчанию
+	if path := os.Getenv("EXR_VALIDATION"); path != "" {
+		rules, err := loadValidationRules(path)
+		if err != nil {
+			log.Fatalf("validation rules: %v", err)
+		}
+		uc.SetValidationRules(rules)
+	}
+	// Детектор аномалий по истории курсов (EXR_ANOMALY=path/to/anomaly.json)
+	if path := os.Getenv("EXR_ANOMALY"); path != "" {
+		cfg, err := loadAnomalyConfig(path)
+		if err != nil {
+			log.Fatalf("anomaly config: %v", err)
+		}
+		uc.SetAnomalyConfig(cfg)
+	}
+	// Курсы старше в подборе лучших не участвуют (EXR_MAX_RATE_AGE=12h, 0 — без ограничения)
+	if v := os.Getenv("EXR_MAX_RATE_AGE"); v != "" {
+		age, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("EXR_MAX_RATE_AGE: %v", err)
+		}
+		uc.SetMaxRateAge(age)
+	}
+
+	// Вебхуки: подписчики получают подписанные курсы сразу после сохранения, очередь повторов в базе
+	// Получатели только на публичных адресах: вебхук не должен ходить во внутреннюю сеть
+	hookClient := httpclient.NewNetHTTPClient(l.With("component", "webhooks"))
+	hookClient.Transport = httpclient.NewLogRoundTripper(l.With("component", "webhooks"), webhook.NewTransport())
+	webhooks := webhook.NewDispatcher(repo, hookClient, l)
+	uc.AddRatesListener(webhooks)
+	go webhooks.Run(context.Background())
+
+	// Живые обновления страницы и SSE-поток: новые курсы раздаются подписчикам в процессе
+	broker := pubsub.NewBroker()
+	uc.AddRatesListener(broker)
+
+	// Telegram-бот (EXR_TELEGRAM_TOKEN), EXR_TELEGRAM_API — свой сервер Bot API.
+	// Логирующий клиент не подходит: токен бота в URL запроса попал бы в логи
+	if token := os.Getenv("EXR_TELEGRAM_TOKEN"); token != "" {
+		client := telegram.NewClient(getenv("EXR_TELEGRAM_API", telegram.DefaultAPIURL), token,
+			&http.Client{Timeout: time.Minute})
+		bot := telegram.NewBot(client, uc, repo, l.With("component", "telegram"))
+		uc.AddAlertListener(bot)
+		go bot.Run(context.Background())
+	}
+
+	// Утренний дайджест на почту (EXR_DIGEST=path/to/digest.json), пароль SMTP можно вынести в EXR_SMTP_PASSWORD
+	if path := os.Getenv("EXR_DIGEST"); path != "" {
+		cfg, err := loadDigestConfig(path)
+		if err != nil {
+			log.Fatalf("digest config: %v", err)
+		}
+		if pw := os.Getenv("EXR_SMTP_PASSWORD"); pw != "" {
+			cfg.SMTP.Password = pw
+		}
+		gen := digest.NewGenerator(uc, digest.NewSMTPMailer(cfg.SMTP), cfg, l.With("component", "digest"))
+		go gen.Run(context.Background())
+	}
 
 	addr := getenv("EXR_HTTP_ADDR", ":8080")
 	server := webserver.NewServer(l, uc)
+	adminToken := os.Getenv("EXR_ADMIN_TOKEN")
+	if adminToken == "" {
+		l.Warn("admin endpoints are disabled, set EXR_ADMIN_TOKEN to enable them")
+	}
+	server.SetAdminToken(adminToken)
+	server.SetWebhooks(webhooks)
+	server.SetRateStream(broker)
 	l.Info("starting server", "addr", addr)
 	if err := server.Start(addr); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// addConfiguredDrivers loads driver configs from file and registers a driver per config.
+func addConfiguredDrivers[C any](
+	reg *exrate.Registry,
+	path string,
+	load func(io.Reader) ([]C, error),
+	build func(C) (exrate.DescribedDriver, error),
+) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cfgs, err := load(f)
+	if err != nil {
+		return err
+	}
+	for _, cfg := range cfgs {
+		d, err := build(cfg)
+		if err != nil {
+			return err
+		}
+		if err = reg.Register(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEgress reads per-driver egress settings, none if path is empty.
+func loadEgress(path string) (map[string]httpclient.Config, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return httpclient.LoadConfigs(f)
+}
+
+func loadValidationRules(path string) (exrate.ValidationRules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return exrate.ValidationRules{}, err
+	}
+	defer f.Close()
+	return exrate.LoadValidationRules(f)
+}
+
+func loadAnomalyConfig(path string) (exrate.AnomalyConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return exrate.AnomalyConfig{}, err
+	}
+	defer f.Close()
+	return exrate.LoadAnomalyConfig(f)
+}
+
+func loadDigestConfig(path string) (digest.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return digest.Config{}, err
+	}
+	defer f.Close()
+	return digest.LoadConfig(f)
+}
+
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -63,7 +63,7 @@ func TestSimpleIntegration_DatabaseOperations(t *testing.T) {
 	t.Logf("Rate added successfully")
 
 	// Тестируем получение последнего курса
-	latestRate, err := repo.GetLatestExchangeRate(context.Background(), "USD", "TestBank")
+	latestRate, err := repo.GetLatestExchangeRate(context.Background(), entity.SeriesKey{CurrencyCode: "USD", Source: "TestBank"})
 	if err != nil {
 		t.Fatalf("Failed to get latest rate: %v", err)
 	}
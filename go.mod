@@ -3,16 +3,21 @@ module github.com/Mi7teR/exr
 go 1.23.0
 
 require (
+	github.com/PuerkitoBio/goquery v1.9.2
 	github.com/a-h/templ v0.2.771
+	github.com/andybalholm/cascadia v1.3.2
 	github.com/go-chi/chi/v5 v5.1.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/stretchr/testify v1.9.0
 	go.uber.org/mock v0.5.2
+	golang.org/x/net v0.28.0
 	golang.org/x/sync v0.8.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,101 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// BCC driver fetches exchange rates from Bank CenterCredit API.
+// Default endpoint: https://www.bcc.kz/api/v1/exchange-rates
+// We take kind "cash" and pick supported currencies (USD, EUR, RUB) vs KZT.
+
+type BCC struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type bccResponse struct {
+	Rates []bccItem `json:"rates"`
+}
+
+type bccItem struct {
+	Code     string `json:"code"`
+	Kind     string `json:"kind"`
+	Purchase string `json:"purchase"`
+	Sale     string `json:"sale"`
+}
+
+const bccKindCash = "cash"
+
+// NewBCC creates Bank CenterCredit driver.
+func NewBCC(addr string, httpClient HTTPClient) *BCC {
+	return &BCC{addr: addr, httpClient: httpClient}
+}
+
+// Info describes Bank CenterCredit as a rate source.
+func (b *BCC) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "BCC",
+		Name:       "Bank CenterCredit",
+		Homepage:   "https://www.bcc.kz",
+		Logo:       "https://www.bcc.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
+// FetchRates returns cash rates for supported currencies.
+func (b *BCC) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var br bccResponse
+	drift, err := decodeJSON(resp.Body, &br)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	supported := map[string]struct{}{"USD": {}, "EUR": {}, "RUB": {}}
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, it := range br.Rates {
+		if it.Kind != bccKindCash {
+			continue
+		}
+		if _, ok := supported[it.Code]; !ok {
+			continue
+		}
+		if !isPositive(it.Purchase) || !isPositive(it.Sale) {
+			continue
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:       "BCC",
+			CurrencyCode: it.Code,
+			Buy:          it.Purchase,
+			Sell:         it.Sale,
+			CreatedAt:    now,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
+}
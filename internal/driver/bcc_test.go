@@ -0,0 +1,79 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBCC_FetchRates(t *testing.T) {
+	const base = `{"rates":[
+		{"code":"USD","kind":"cash","purchase":"538.00","sale":"543.00"},
+		{"code":"EUR","kind":"cash","purchase":"629.00","sale":"637.00"},
+		{"code":"RUB","kind":"cash","purchase":"6.45","sale":"7.05"},
+		{"code":"USD","kind":"cashless","purchase":"539.00","sale":"542.00"},
+		{"code":"GBP","kind":"cash","purchase":"700.00","sale":"730.00"}
+	]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(base))
+		}))
+		defer server.Close()
+		d := driver.NewBCC(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "BCC", rates[0].Source)
+		assert.Equal(t, "538.00", rates[0].Buy)
+		assert.Equal(t, "543.00", rates[0].Sell)
+	})
+
+	t.Run("unquoted sides are skipped", func(t *testing.T) {
+		// Null, пустой или нулевой курс — валюта не котируется, а не курс 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"rates":[{"code":"USD","kind":"cash","purchase":"0","sale":"542"},{"code":"EUR","kind":"cash","purchase":"","sale":"637"},{"code":"RUB","kind":"cash","purchase":"5.5","sale":"5.9"}]}`))
+		}))
+		defer server.Close()
+		d := driver.NewBCC(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "RUB", rates[0].CurrencyCode)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		d := driver.NewBCC(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("["))
+		}))
+		defer server.Close()
+		d := driver.NewBCC(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"rates":[{"code":"USD","kind":"cashless","purchase":"1","sale":"2"}]}`))
+		}))
+		defer server.Close()
+		d := driver.NewBCC(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,108 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Bereke driver fetches exchange rates from Bereke Bank API.
+// Default endpoint: https://berekebank.kz/api/exchange-rates
+// We pick supported currencies (USD, EUR, RUB) where toCurrency == KZT.
+
+type Bereke struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type berekeResponse struct {
+	Result []berekeItem `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type berekeItem struct {
+	FromCurrency string  `json:"fromCurrency"`
+	ToCurrency   string  `json:"toCurrency"`
+	Buy          float64 `json:"buy"`
+	Sell         float64 `json:"sell"`
+}
+
+// NewBereke creates Bereke Bank driver.
+func NewBereke(addr string, httpClient HTTPClient) *Bereke {
+	return &Bereke{addr: addr, httpClient: httpClient}
+}
+
+// Info describes Bereke Bank as a rate source.
+func (b *Bereke) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Bereke",
+		Name:       "Bereke Bank",
+		Homepage:   "https://berekebank.kz",
+		Logo:       "https://berekebank.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
+// FetchRates returns rates for supported currencies.
+func (b *Bereke) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var br berekeResponse
+	drift, err := decodeJSON(resp.Body, &br)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if br.Error != nil {
+		return nil, fmt.Errorf("bereke api error %d: %s", br.Error.Code, br.Error.Message)
+	}
+
+	supported := map[string]struct{}{"USD": {}, "EUR": {}, "RUB": {}}
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, it := range br.Result {
+		if it.ToCurrency != dstKZT {
+			continue
+		}
+		if _, ok := supported[it.FromCurrency]; !ok {
+			continue
+		}
+		// Отсутствующий или null курс декодируется в 0: валюта сейчас не котируется
+		if it.Buy <= 0 || it.Sell <= 0 {
+			continue
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:       "Bereke",
+			CurrencyCode: it.FromCurrency,
+			Buy:          strconv.FormatFloat(it.Buy, 'f', -1, 64),
+			Sell:         strconv.FormatFloat(it.Sell, 'f', -1, 64),
+			CreatedAt:    now,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
+}
@@ -0,0 +1,88 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBereke_FetchRates(t *testing.T) {
+	const base = `{"result":[
+		{"fromCurrency":"USD","toCurrency":"KZT","buy":536.5,"sell":543.5},
+		{"fromCurrency":"EUR","toCurrency":"KZT","buy":626,"sell":638},
+		{"fromCurrency":"RUB","toCurrency":"KZT","buy":6.35,"sell":7.15},
+		{"fromCurrency":"EUR","toCurrency":"USD","buy":1.06,"sell":1.12}
+	]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(base))
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "Bereke", rates[0].Source)
+		assert.Equal(t, "536.5", rates[0].Buy)
+		assert.Equal(t, "543.5", rates[0].Sell)
+	})
+
+	t.Run("unquoted sides are skipped", func(t *testing.T) {
+		// Null, пустой или нулевой курс — валюта не котируется, а не курс 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"result":[{"fromCurrency":"USD","toCurrency":"KZT","buy":null,"sell":541.5},{"fromCurrency":"EUR","toCurrency":"KZT","buy":0,"sell":637},{"fromCurrency":"RUB","toCurrency":"KZT","buy":5.4,"sell":5.9}],"error":null}`))
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "RUB", rates[0].CurrencyCode)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("{"))
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"result":null,"error":{"code":503,"message":"maintenance"}}`))
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"result":[{"fromCurrency":"EUR","toCurrency":"USD","buy":1,"sell":2}]}`))
+		}))
+		defer server.Close()
+		d := driver.NewBereke(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
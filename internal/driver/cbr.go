@@ -0,0 +1,142 @@
+package driver
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// CBR driver fetches official rates of the Central Bank of Russia.
+// Default endpoint: https://www.cbr.ru/scripts/XML_daily.asp
+// The feed is windows-1251 encoded, values use decimal comma and are given per Nominal units
+// (e.g. 100 KZT); we normalise to one unit quoted in RUB.
+
+type CBR struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type cbrValCurs struct {
+	XMLName xml.Name `xml:"ValCurs"`
+	Date    string   `xml:"Date,attr"` // 18.10.2024
+	Valute  []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+}
+
+const (
+	quoteRUB       = "RUB"
+	dottedDayFirst = "02.01.2006"
+)
+
+// effectiveAt returns the time a reference rate is stored at: the date it is set for, but not later
+// than now. Central banks publish tomorrow's rates in advance; dated in the future, they would stay
+// out of range queries until that day and sort after rates fetched later.
+func effectiveAt(layout, date string) time.Time {
+	now := time.Now().UTC()
+	d, err := time.Parse(layout, date)
+	if err != nil || d.After(now) {
+		return now
+	}
+	return d
+}
+
+// NewCBR creates Central Bank of Russia driver.
+func NewCBR(addr string, httpClient HTTPClient) *CBR {
+	return &CBR{addr: addr, httpClient: httpClient}
+}
+
+// Info describes Bank of Russia as a rate source.
+func (c *CBR) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "CBR",
+		Name:       "Банк России",
+		Homepage:   "https://www.cbr.ru",
+		Logo:       "https://www.cbr.ru/favicon.ico",
+		Kind:       entity.SourceKindCentralBank,
+		Currencies: []string{"USD", "EUR", "CNY", "KZT"},
+		Channels:   []string{entity.ChannelOfficial},
+		Schedule:   centralBankSchedule,
+	}
+}
+
+// FetchRates returns official RUB rates for USD, EUR, CNY and KZT.
+func (c *CBR) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var vc cbrValCurs
+	dec := xml.NewDecoder(resp.Body)
+	dec.CharsetReader = charset.NewReaderLabel
+	if err = decodeXML(dec, &vc); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(vc.Valute) == 0 {
+		return nil, &SchemaError{Missing: []string{"ValCurs.Valute"}}
+	}
+
+	createdAt := effectiveAt(dottedDayFirst, vc.Date)
+
+	supported := map[string]struct{}{"USD": {}, "EUR": {}, "CNY": {}, "KZT": {}}
+	var rates []*entity.ExchangeRate
+	for _, v := range vc.Valute {
+		if _, ok := supported[v.CharCode]; !ok {
+			continue
+		}
+		value, err := perUnit(v.Value, v.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.CharCode, err)
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:        "CBR",
+			CurrencyCode:  v.CharCode,
+			QuoteCurrency: quoteRUB,
+			Buy:           value,
+			Sell:          value,
+			CreatedAt:     createdAt,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("no supported currency rates found")
+	}
+	return rates, nil
+}
+
+// perUnit converts "19,5" per "100" units into the price of a single unit.
+func perUnit(value, nominal string) (string, error) {
+	v, err := ParseNumber(value)
+	if err != nil {
+		return "", err
+	}
+	if nominal == "" || nominal == "1" {
+		return v, nil
+	}
+	n, err := strconv.ParseFloat(nominal, 64)
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid nominal %q", nominal)
+	}
+	f, _ := strconv.ParseFloat(v, 64)
+	return strconv.FormatFloat(f/n, 'f', -1, 64), nil
+}
@@ -0,0 +1,92 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cbrDaily повторяет формат XML_daily.asp: windows-1251, десятичная запятая, Nominal.
+const cbrDaily = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs Date="18.10.2024" name="Foreign Currency Market">
+	<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>` +
+	"<Name>\xc4\xee\xeb\xeb\xe0\xf0 \xd1\xd8\xc0</Name>" + `<Value>96,9379</Value></Valute>
+	<Valute ID="R01239"><NumCode>978</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal><Name>Euro</Name><Value>105,2027</Value></Valute>
+	<Valute ID="R01335"><NumCode>398</NumCode><CharCode>KZT</CharCode><Nominal>100</Nominal><Name>Tenge</Name><Value>19,9713</Value></Valute>
+	<Valute ID="R01820"><NumCode>392</NumCode><CharCode>JPY</CharCode><Nominal>100</Nominal><Name>Yen</Name><Value>64,8520</Value></Valute>
+</ValCurs>`
+
+func TestCBR_FetchRates(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/xml; charset=windows-1251")
+			_, _ = w.Write([]byte(cbrDaily))
+		}))
+		defer server.Close()
+
+		rates, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+
+		usd := rates[0]
+		assert.Equal(t, "CBR", usd.Source)
+		assert.Equal(t, "USD", usd.CurrencyCode)
+		assert.Equal(t, "RUB", usd.QuoteCurrency)
+		assert.Equal(t, "96.9379", usd.Buy)
+		assert.Equal(t, usd.Buy, usd.Sell)
+		assert.Equal(t, time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC), usd.CreatedAt)
+
+		kzt := rates[2]
+		assert.Equal(t, "KZT", kzt.CurrencyCode)
+		assert.Equal(t, "0.199713", kzt.Buy) // за 1 тенге, а не за 100
+	})
+
+	t.Run("tomorrow's rates", func(t *testing.T) {
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("02.01.2006")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(strings.Replace(cbrDaily, "18.10.2024", tomorrow, 1)))
+		}))
+		defer server.Close()
+
+		before := time.Now().UTC()
+		rates, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.NoError(t, err)
+		// Курсы на завтра сохраняются временем опроса, а не будущей датой
+		assert.False(t, rates[0].CreatedAt.Before(before))
+		assert.False(t, rates[0].CreatedAt.After(time.Now().UTC()))
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		_, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("bad value", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<ValCurs Date="18.10.2024"><Valute><CharCode>USD</CharCode><Nominal>1</Nominal><Value>n/a</Value></Valute></ValCurs>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<ValCurs Date="18.10.2024"></ValCurs>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,141 @@
+package driver_test
+
+import (
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/driver/drivertest"
+)
+
+// TestConformance runs the driver contract against recorded upstream responses.
+// Re-record with EXR_RECORD_FIXTURES=1 (needs network; Kaspi answers only from KZ).
+func TestConformance(t *testing.T) {
+	suites := []drivertest.Suite{
+		{
+			Source:  "Kaspi",
+			URL:     "https://guide.kaspi.kz/client/api/v2/intgr/currency/rate/aggregate",
+			Fixture: "testdata/recorded/kaspi.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewKaspi(addr, cli)
+			},
+		},
+		{
+			Source:  "Halyk",
+			URL:     "https://back.halykbank.kz/common/currency-history",
+			Fixture: "testdata/recorded/halyk.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewHalyk(addr, cli)
+			},
+		},
+		{
+			Source:  "Freedom",
+			URL:     "https://bankffin.kz/api/exchange-rates/getRates",
+			Fixture: "testdata/recorded/freedom.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewFreedom(addr, cli)
+			},
+		},
+		{
+			Source:  "RBK",
+			URL:     "https://backend.bankrbk.kz/api/v1/modules/exchange_rates/data",
+			Fixture: "testdata/recorded/rbk.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewRBK(addr, cli)
+			},
+		},
+		{
+			Source:  "HomeKZ",
+			URL:     "https://home.kz/api/public/getCurrency",
+			Fixture: "testdata/recorded/home.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewHome(addr, cli)
+			},
+		},
+		{
+			Source:  "NBRK",
+			URL:     "https://nationalbank.kz/rss/rates_all.xml",
+			Fixture: "testdata/recorded/nbrk.xml",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewNBRK(addr, cli)
+			},
+		},
+		{
+			Source:  "Jusan",
+			URL:     "https://jusan.kz/api/v1/exchange-rates",
+			Fixture: "testdata/recorded/jusan.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewJusan(addr, cli)
+			},
+		},
+		{
+			Source:  "BCC",
+			URL:     "https://www.bcc.kz/api/v1/exchange-rates",
+			Fixture: "testdata/recorded/bcc.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewBCC(addr, cli)
+			},
+		},
+		{
+			Source:  "Forte",
+			URL:     "https://forte.kz/api/currency/rates",
+			Fixture: "testdata/recorded/forte.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewForte(addr, cli)
+			},
+		},
+		{
+			Source:  "Eurasian",
+			URL:     "https://eubank.kz/exchange-rates/",
+			Fixture: "testdata/html/eurasian.html",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewEurasian(addr, cli)
+			},
+		},
+		{
+			Source:  "Bereke",
+			URL:     "https://berekebank.kz/api/exchange-rates",
+			Fixture: "testdata/recorded/bereke.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewBereke(addr, cli)
+			},
+		},
+		{
+			Source:  "KursKZ",
+			URL:     "https://kurs.kz/api/punkts",
+			Fixture: "testdata/recorded/kurskz.json",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewKursKZ(addr, []string{"almaty"}, cli)
+			},
+		},
+		{
+			Source:  "CBR",
+			URL:     "https://www.cbr.ru/scripts/XML_daily.asp",
+			Fixture: "testdata/recorded/cbr.xml",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewCBR(addr, cli)
+			},
+		},
+		{
+			Source:  "ECB",
+			URL:     "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml",
+			Fixture: "testdata/recorded/ecb.xml",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewECB(addr, cli)
+			},
+		},
+		{
+			Source:  "NBKR",
+			URL:     "https://www.nbkr.kg/XML/daily.xml",
+			Fixture: "testdata/recorded/nbkr.xml",
+			New: func(addr string, cli driver.HTTPClient) drivertest.Driver {
+				return driver.NewNBKR(addr, cli)
+			},
+		},
+	}
+
+	for _, s := range suites {
+		t.Run(s.Source, func(t *testing.T) {
+			drivertest.Run(t, s)
+		})
+	}
+}
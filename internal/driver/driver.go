@@ -1,8 +1,51 @@
 package driver
 
-import "net/http"
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
 
 // HTTPClient is an interface that defines the methods that an HTTP client must implement.
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
+
+// Default polling schedules declared in drivers' source metadata.
+const (
+	bankSchedule        = 30 * time.Minute
+	officeSchedule      = 15 * time.Minute // обменники меняют курс чаще банков
+	centralBankSchedule = 6 * time.Hour    // официальные курсы публикуются раз в день
+)
+
+// retailCurrencies are the currencies local bank drivers pick.
+func retailCurrencies() []string {
+	return []string{"USD", "EUR", "RUB"}
+}
+
+var (
+	numberPattern       = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// isNumber reports whether an upstream rate is a plain decimal number; anything else
+// (empty, "-", "NaN", hex floats) means the bank does not quote the currency right now.
+func isNumber(s string) bool {
+	return numberPattern.MatchString(s)
+}
+
+// isPositive reports whether an upstream rate is a plain decimal number above zero; a zero side
+// is how some banks send a currency they do not quote.
+func isPositive(s string) bool {
+	if !isNumber(s) {
+		return false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return err == nil && f > 0
+}
+
+// isCurrencyCode reports whether s looks like an ISO 4217 code.
+func isCurrencyCode(s string) bool {
+	return currencyCodePattern.MatchString(s)
+}
@@ -0,0 +1,150 @@
+// Package drivertest is a conformance suite for rate drivers.
+//
+// Every driver runs the same contract against a recorded upstream response: it returns a
+// non-empty list of rates with valid currency codes, positive numbers, buy <= sell and its own
+// Source, honours context cancellation and rejects non-200 responses and malformed bodies.
+//
+// Fixtures are recorded from the live endpoint once and replayed offline:
+//
+//	EXR_RECORD_FIXTURES=1 go test ./internal/driver -run TestConformance/Halyk
+package drivertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// RecordEnv enables recording fixtures from live endpoints instead of replaying them.
+const RecordEnv = "EXR_RECORD_FIXTURES"
+
+const (
+	cancelAfter   = 50 * time.Millisecond
+	cancelTimeout = 2 * time.Second
+	// malformedBody is neither valid JSON, nor XML with a root element, nor a page with rate rows.
+	malformedBody = `{"data": [<html`
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Driver is the driver under test.
+type Driver interface {
+	FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
+}
+
+// Suite describes how to run the contract for one driver.
+type Suite struct {
+	// Source is expected on every produced rate.
+	Source string
+	// New builds the driver for the endpoint and client.
+	New func(addr string, cli driver.HTTPClient) Driver
+	// Fixture is the path of the recorded upstream response.
+	Fixture string
+	// URL is the live endpoint, used only when recording.
+	URL string
+}
+
+// Run records the fixture if requested and runs the conformance checks.
+func Run(t *testing.T, s Suite) {
+	t.Helper()
+	if os.Getenv(RecordEnv) != "" {
+		record(t, s)
+	}
+	fixture, err := os.ReadFile(s.Fixture)
+	require.NoError(t, err, "fixture is missing, record it with %s=1", RecordEnv)
+
+	t.Run("rates", func(t *testing.T) {
+		server := serve(t, http.StatusOK, fixture)
+		rates, err := s.New(server.URL, server.Client()).FetchRates(context.Background())
+		require.NoError(t, err)
+		CheckRates(t, s.Source, rates)
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		t.Cleanup(server.Close)
+		t.Cleanup(func() { close(release) })
+
+		ctx, cancel := context.WithTimeout(context.Background(), cancelAfter)
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			_, err := s.New(server.URL, server.Client()).FetchRates(ctx)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, context.DeadlineExceeded), "want context error, got %v", err)
+		case <-time.After(cancelTimeout):
+			t.Fatal("FetchRates ignores context cancellation")
+		}
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		// Тело валидное: драйвер обязан смотреть на статус, а не только на содержимое
+		server := serve(t, http.StatusServiceUnavailable, fixture)
+		_, err := s.New(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		server := serve(t, http.StatusOK, []byte(malformedBody))
+		_, err := s.New(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
+
+// CheckRates asserts the invariants every stored rate must satisfy.
+func CheckRates(t *testing.T, source string, rates []*entity.ExchangeRate) {
+	t.Helper()
+	require.NotEmpty(t, rates, "driver returned no rates")
+	for _, r := range rates {
+		require.NotNil(t, r)
+		assert.Equal(t, source, r.Source, "%s: source", r.CurrencyCode)
+		assert.Regexp(t, currencyCode, r.CurrencyCode)
+		if r.QuoteCurrency != "" {
+			assert.Regexp(t, currencyCode, r.QuoteCurrency, "%s: quote currency", r.CurrencyCode)
+			assert.NotEqual(t, r.CurrencyCode, r.QuoteCurrency, "%s: quoted in itself", r.CurrencyCode)
+		}
+		buy, errBuy := strconv.ParseFloat(r.Buy, 64)
+		sell, errSell := strconv.ParseFloat(r.Sell, 64)
+		if assert.NoError(t, errBuy, "%s: buy %q", r.CurrencyCode, r.Buy) {
+			assert.Positive(t, buy, "%s: buy", r.CurrencyCode)
+		}
+		if assert.NoError(t, errSell, "%s: sell %q", r.CurrencyCode, r.Sell) {
+			assert.Positive(t, sell, "%s: sell", r.CurrencyCode)
+		}
+		if errBuy == nil && errSell == nil {
+			assert.LessOrEqual(t, buy, sell, "%s: buy above sell", r.CurrencyCode)
+		}
+	}
+}
+
+func serve(t *testing.T, status int, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
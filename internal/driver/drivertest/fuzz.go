@@ -0,0 +1,98 @@
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// fuzzAddr is never dialled: the fuzz client answers every request with the fuzzed body.
+const fuzzAddr = "http://fuzz.invalid/rates"
+
+// Fuzz feeds arbitrary upstream bodies to the driver. The driver must never panic and
+// must either fail or return parsed rates, never silent garbage.
+func Fuzz(f *testing.F, newDriver func(addr string, cli driver.HTTPClient) Driver, seeds ...[]byte) {
+	f.Helper()
+	fuzz(f, newDriver, CheckParsed, seeds)
+}
+
+// FuzzQuoted is Fuzz for drivers that skip the currencies a bank does not quote: besides being
+// parsed, every returned rate must have both sides above zero.
+func FuzzQuoted(f *testing.F, newDriver func(addr string, cli driver.HTTPClient) Driver, seeds ...[]byte) {
+	f.Helper()
+	fuzz(f, newDriver, CheckQuoted, seeds)
+}
+
+func fuzz(
+	f *testing.F,
+	newDriver func(addr string, cli driver.HTTPClient) Driver,
+	check func(t *testing.T, rates []*entity.ExchangeRate),
+	seeds [][]byte,
+) {
+	f.Helper()
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		rates, err := newDriver(fuzzAddr, staticClient(body)).FetchRates(context.Background())
+		if err != nil {
+			return
+		}
+		check(t, rates)
+	})
+}
+
+// CheckParsed asserts that a successful fetch produced well-formed rates. Sanity of the values
+// themselves (positive, buy <= sell) is up to validation at ingestion.
+func CheckParsed(t *testing.T, rates []*entity.ExchangeRate) {
+	t.Helper()
+	if len(rates) == 0 {
+		t.Fatal("no error and no rates")
+	}
+	for _, r := range rates {
+		if r == nil {
+			t.Fatal("nil rate")
+		}
+		if !currencyCode.MatchString(r.CurrencyCode) {
+			t.Fatalf("invalid currency code %q", r.CurrencyCode)
+		}
+		for side, v := range map[string]string{"buy": r.Buy, "sell": r.Sell} {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+				t.Fatalf("%s %s is not a number: %q", r.CurrencyCode, side, v)
+			}
+		}
+	}
+}
+
+// CheckQuoted asserts CheckParsed and that both sides of every rate are above zero.
+func CheckQuoted(t *testing.T, rates []*entity.ExchangeRate) {
+	t.Helper()
+	CheckParsed(t, rates)
+	for _, r := range rates {
+		for side, v := range map[string]string{"buy": r.Buy, "sell": r.Sell} {
+			if n, _ := strconv.ParseFloat(v, 64); n <= 0 {
+				t.Fatalf("%s %s is not quoted: %q", r.CurrencyCode, side, v)
+			}
+		}
+	}
+}
+
+// staticClient answers every request with 200 and the same body.
+type staticClient []byte
+
+func (c staticClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(c)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+)
+
+const recordTimeout = 30 * time.Second
+
+// Recorder is an HTTP client that saves successful response bodies to a fixture file.
+type Recorder struct {
+	next driver.HTTPClient
+	path string
+}
+
+// NewRecorder wraps next; every 200 response body is written to path.
+func NewRecorder(next driver.HTTPClient, path string) *Recorder {
+	return &Recorder{next: next, path: path}
+}
+
+// Do performs the request and records the response body.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		if err = os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(r.path, body, 0o644); err != nil { //nolint:gosec // фикстуры в testdata
+			return nil, err
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// record fetches the live endpoint through the driver itself, so the request carries the
+// same method, body and headers as in production.
+func record(t *testing.T, s Suite) {
+	t.Helper()
+	if s.URL == "" {
+		t.Fatalf("%s: no live URL to record from", s.Source)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	rec := NewRecorder(&http.Client{Timeout: recordTimeout}, s.Fixture)
+	if _, err := s.New(s.URL, rec).FetchRates(ctx); err != nil {
+		t.Logf("%s: live fetch failed, fixture may be stale: %v", s.Source, err)
+		return
+	}
+	t.Logf("%s: recorded %s", s.Source, s.Fixture)
+}
@@ -0,0 +1,45 @@
+package drivertest_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Mi7teR/exr/internal/driver/drivertest"
+)
+
+func TestRecorder(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"rates":[]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "recorded", "bank.json")
+	rec := drivertest.NewRecorder(server.Client(), path)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := rec.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, `{"rates":[]}`, string(body), "body must still be readable by the driver")
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, body, saved)
+
+	// Ошибочные ответы не затирают фикстуру
+	require.NoError(t, os.WriteFile(path, []byte("good"), 0o644))
+	status = http.StatusBadGateway
+	_, err = rec.Do(req)
+	require.NoError(t, err)
+	saved, _ = os.ReadFile(path)
+	assert.Equal(t, "good", string(saved))
+}
@@ -0,0 +1,111 @@
+package driver
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// ECB driver fetches euro foreign exchange reference rates of the European Central Bank.
+// Default endpoint: https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+// ECB publishes how many units of a currency one euro buys (EUR/USD 1.0824); we store
+// the price of one unit of the currency in EUR to keep "CurrencyCode priced in QuoteCurrency".
+
+type ECB struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type ecbEnvelope struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Cube    struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+const (
+	quoteEUR     = "EUR"
+	ecbPrecision = 6
+)
+
+// NewECB creates European Central Bank driver.
+func NewECB(addr string, httpClient HTTPClient) *ECB {
+	return &ECB{addr: addr, httpClient: httpClient}
+}
+
+// Info describes European Central Bank as a rate source.
+func (e *ECB) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "ECB",
+		Name:       "European Central Bank",
+		Homepage:   "https://www.ecb.europa.eu",
+		Logo:       "https://www.ecb.europa.eu/favicon.ico",
+		Kind:       entity.SourceKindCentralBank,
+		Currencies: []string{"USD", "GBP", "CNY"},
+		Channels:   []string{entity.ChannelOfficial},
+		Schedule:   centralBankSchedule,
+	}
+}
+
+// FetchRates returns EUR reference rates for USD, GBP and CNY.
+func (e *ECB) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var env ecbEnvelope
+	if err = decodeXML(xml.NewDecoder(resp.Body), &env); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(env.Cube.Rates) == 0 {
+		return nil, &SchemaError{Missing: []string{"Envelope.Cube.Cube.Cube"}}
+	}
+
+	createdAt := effectiveAt(time.DateOnly, env.Cube.Time)
+
+	supported := map[string]struct{}{"USD": {}, "GBP": {}, "CNY": {}}
+	var rates []*entity.ExchangeRate
+	for _, r := range env.Cube.Rates {
+		if _, ok := supported[r.Currency]; !ok {
+			continue
+		}
+		perEUR, err := strconv.ParseFloat(r.Rate, 64)
+		if err != nil || perEUR <= 0 {
+			return nil, fmt.Errorf("%s: invalid rate %q", r.Currency, r.Rate)
+		}
+		value := strconv.FormatFloat(1/perEUR, 'f', ecbPrecision, 64)
+		rates = append(rates, &entity.ExchangeRate{
+			Source:        "ECB",
+			CurrencyCode:  r.Currency,
+			QuoteCurrency: quoteEUR,
+			Buy:           value,
+			Sell:          value,
+			CreatedAt:     createdAt,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("no supported currency rates found")
+	}
+	return rates, nil
+}
@@ -0,0 +1,66 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ecbDaily = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-10-18">
+			<Cube currency="USD" rate="1.0866"/>
+			<Cube currency="JPY" rate="162.53"/>
+			<Cube currency="GBP" rate="0.83208"/>
+			<Cube currency="CNY" rate="7.7150"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestECB_FetchRates(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(ecbDaily))
+		}))
+		defer server.Close()
+
+		rates, err := driver.NewECB(server.URL, server.Client()).FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+
+		usd := rates[0]
+		assert.Equal(t, "ECB", usd.Source)
+		assert.Equal(t, "USD", usd.CurrencyCode)
+		assert.Equal(t, "EUR", usd.QuoteCurrency)
+		assert.Equal(t, "0.920302", usd.Buy) // 1 / 1.0866
+		assert.Equal(t, usd.Buy, usd.Sell)
+		assert.Equal(t, time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC), usd.CreatedAt)
+		assert.Equal(t, "GBP", rates[1].CurrencyCode)
+	})
+
+	t.Run("zero rate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<Envelope><Cube><Cube time="2024-10-18"><Cube currency="USD" rate="0"/></Cube></Cube></Envelope>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewECB(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid xml", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<Envelope><Cube>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewECB(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package driver
+
+import "github.com/Mi7teR/exr/internal/entity"
+
+// Eurasian driver scrapes exchange rates from Eurasian Bank web page, the bank has no public API.
+// Default endpoint: https://eubank.kz/exchange-rates/
+// The page has a table per channel; we use the "cash" one and pick supported currencies (USD, EUR, RUB).
+
+type Eurasian struct {
+	*HTML
+}
+
+// NewEurasian creates Eurasian Bank driver.
+func NewEurasian(addr string, httpClient HTTPClient) *Eurasian {
+	h, err := NewHTML(HTMLConfig{
+		ID:       "Eurasian",
+		URL:      addr,
+		Row:      "table.exchange-rates[data-type=cash] tbody tr",
+		Currency: "td.exchange-rates__currency",
+		Buy:      "td.exchange-rates__buy",
+		Sell:     "td.exchange-rates__sell",
+	}, httpClient)
+	if err != nil {
+		panic(err) // селекторы константные и покрыты тестами
+	}
+	return &Eurasian{HTML: h}
+}
+
+// Info describes Eurasian Bank as a rate source.
+func (e *Eurasian) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Eurasian",
+		Name:       "Eurasian Bank",
+		Homepage:   "https://eubank.kz",
+		Logo:       "https://eubank.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
@@ -0,0 +1,46 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEurasian_FetchRates(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := htmlFixtureServer(t, "eurasian.html")
+		d := driver.NewEurasian(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "Eurasian", rates[0].Source)
+		assert.Equal(t, "USD", rates[0].CurrencyCode)
+		assert.Equal(t, "537", rates[0].Buy)
+		assert.Equal(t, "543.5", rates[0].Sell)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		d := driver.NewEurasian(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("layout changed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><div class="rates">USD 537</div></body></html>`))
+		}))
+		defer server.Close()
+		d := driver.NewEurasian(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
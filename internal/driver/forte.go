@@ -0,0 +1,101 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Forte driver fetches exchange rates from ForteBank API.
+// Default endpoint: https://forte.kz/api/currency/rates
+// Response groups rates by channel and then by currency code; we use "cash" vs KZT.
+
+type Forte struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type forteResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Cash   map[string]fortePair `json:"cash"`
+		Online map[string]fortePair `json:"online,omitempty"` // kept for potential future use
+	} `json:"data"`
+}
+
+type fortePair struct {
+	Buy  string `json:"buy"`
+	Sell string `json:"sell"`
+}
+
+const forteStatusOK = "ok"
+
+// NewForte creates ForteBank driver.
+func NewForte(addr string, httpClient HTTPClient) *Forte {
+	return &Forte{addr: addr, httpClient: httpClient}
+}
+
+// Info describes ForteBank as a rate source.
+func (f *Forte) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Forte",
+		Name:       "ForteBank",
+		Homepage:   "https://forte.kz",
+		Logo:       "https://forte.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
+// FetchRates returns cash rates for supported currencies.
+func (f *Forte) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var fr forteResponse
+	drift, err := decodeJSON(resp.Body, &fr)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if fr.Status != forteStatusOK {
+		return nil, fmt.Errorf("forte api status %q", fr.Status)
+	}
+
+	supported := []string{"USD", "EUR", "RUB"}
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, cur := range supported {
+		p, ok := fr.Data.Cash[cur]
+		if !ok || !isPositive(p.Buy) || !isPositive(p.Sell) {
+			continue
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:       "Forte",
+			CurrencyCode: cur,
+			Buy:          p.Buy,
+			Sell:         p.Sell,
+			CreatedAt:    now,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
+}
@@ -0,0 +1,91 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForte_FetchRates(t *testing.T) {
+	const base = `{"status":"ok","data":{
+		"cash":{
+			"USD":{"buy":"537.00","sell":"544.00"},
+			"EUR":{"buy":"627.00","sell":"637.00"},
+			"RUB":{"buy":"6.40","sell":"7.10"},
+			"CHF":{"buy":"600.00","sell":"640.00"}
+		},
+		"online":{"USD":{"buy":"539.00","sell":"542.00"}}
+	}}`
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(base))
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "Forte", rates[0].Source)
+		assert.Equal(t, "USD", rates[0].CurrencyCode)
+		assert.Equal(t, "537.00", rates[0].Buy)
+	})
+
+	t.Run("unquoted sides are skipped", func(t *testing.T) {
+		// Null, пустой или нулевой курс — валюта не котируется, а не курс 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"cash":{"USD":{"buy":"0.00","sell":"544.00"},"EUR":{"buy":"","sell":"637.00"},"RUB":{"buy":"6.40","sell":"7.10"}}}}`))
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "RUB", rates[0].CurrencyCode)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("{"))
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"error"}`))
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"cash":{"CHF":{"buy":"1","sell":"2"}}}}`))
+		}))
+		defer server.Close()
+		d := driver.NewForte(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -3,7 +3,6 @@ package driver
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -21,14 +20,14 @@ type Freedom struct {
 }
 
 type freedomResponse struct {
-	Success bool        `json:"success"`
-	Message interface{} `json:"message"`
+	Success bool            `json:"success"`
+	Message json.RawMessage `json:"message,omitempty"` // строка, объект с переводами или null — только для текста ошибки
 	Data    struct {
 		Cash    []freedomItem `json:"cash"`
-		Mobile  []freedomItem `json:"mobile"`
-		NonCash []freedomItem `json:"non_cash"`
+		Mobile  []freedomItem `json:"mobile,omitempty"`
+		NonCash []freedomItem `json:"non_cash,omitempty"`
 	} `json:"data"`
-	Status int `json:"status"`
+	Status int `json:"status,omitempty"`
 }
 
 type freedomItem struct {
@@ -43,6 +42,20 @@ func NewFreedom(addr string, httpClient HTTPClient) *Freedom {
 	return &Freedom{addr: addr, httpClient: httpClient}
 }
 
+// Info describes Freedom Bank as a rate source.
+func (f *Freedom) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Freedom",
+		Name:       "Freedom Bank",
+		Homepage:   "https://bankffin.kz",
+		Logo:       "https://bankffin.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
 // FetchRates fetches cash exchange rates for supported currencies.
 func (f *Freedom) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.addr, nil)
@@ -61,11 +74,12 @@ func (f *Freedom) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error
 	}
 
 	var fr freedomResponse
-	if err = json.NewDecoder(resp.Body).Decode(&fr); err != nil {
+	drift, err := decodeJSON(resp.Body, &fr)
+	if err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 	if !fr.Success {
-		return nil, errors.New("freedom api success false")
+		return nil, fmt.Errorf("freedom api success false: %s", fr.Message)
 	}
 	if fr.Status != 200 && fr.Status != 0 { // some responses might omit status
 		return nil, fmt.Errorf("freedom api status %d", fr.Status)
@@ -81,6 +95,9 @@ func (f *Freedom) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error
 		if _, ok := supported[it.BuyCode]; !ok {
 			continue
 		}
+		if !isNumber(it.BuyRate) || !isNumber(it.SellRate) {
+			continue
+		}
 		rates = append(rates, &entity.ExchangeRate{
 			Source:       "Freedom",
 			CurrencyCode: it.BuyCode,
@@ -90,7 +107,7 @@ func (f *Freedom) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error
 		})
 	}
 	if len(rates) == 0 {
-		return nil, errors.New("no supported currency rates found")
+		return nil, errNoRates(drift)
 	}
-	return rates, nil
+	return rates, drift.err()
 }
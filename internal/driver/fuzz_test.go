@@ -0,0 +1,169 @@
+package driver_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/driver/drivertest"
+)
+
+// Фаззинг разбора ответов: go test ./internal/driver -fuzz FuzzHalyk -fuzztime 30s
+// Найденные падения сохраняются в testdata/fuzz и дальше проверяются обычным go test.
+
+func seeds(f *testing.F, paths ...string) [][]byte {
+	f.Helper()
+	out := make([][]byte, 0, len(paths))
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func FuzzKaspi(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewKaspi(addr, cli)
+	}, append(seeds(f, "testdata/recorded/kaspi.json"),
+		[]byte(`{"status":"OK","message":"OK","body":[]}`),
+		[]byte(`{"status":"ERROR","message":"Bad request"}`),
+	)...)
+}
+
+func FuzzHalyk(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewHalyk(addr, cli)
+	}, append(seeds(f, "testdata/recorded/halyk.json"),
+		[]byte(`{"result":true,"data":{"currencyHistory":[{"privatePersons":{"USD/KZT":{"buy":537.6,"sell":544.6}}}]}}`),
+		[]byte(`{"result":true,"data":{"currencyHistory":"0"}}`),
+	)...)
+}
+
+func FuzzFreedom(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewFreedom(addr, cli)
+	}, append(seeds(f, "testdata/recorded/freedom.json"),
+		[]byte(`{"success":false,"message":{"ru":"Ошибка"},"status":500}`),
+	)...)
+}
+
+func FuzzRBK(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewRBK(addr, cli)
+	}, seeds(f, "testdata/recorded/rbk.json")...)
+}
+
+func FuzzHome(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewHome(addr, cli)
+	}, seeds(f, "testdata/recorded/home.json")...)
+}
+
+func FuzzNBRK(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewNBRK(addr, cli)
+	}, append(seeds(f, "testdata/recorded/nbrk.xml"),
+		[]byte(`<rss><channel></channel></rss>`),
+		[]byte(`<rss><channel><item><title>USD</title><description>abc</description></item></channel></rss>`),
+		[]byte(`<rss><channel><item><title>USD</title><description>0</description></item></channel></rss>`),
+	)...)
+}
+
+func FuzzJusan(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewJusan(addr, cli)
+	}, append(seeds(f, "testdata/recorded/jusan.json"),
+		[]byte(`{"success":true,"data":[{"currency_code":"USD","type":"individual","buy_rate":null},{"currency_code":"EUR","type":"individual","buy_rate":0,"sell_rate":636}]}`),
+	)...)
+}
+
+func FuzzBCC(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewBCC(addr, cli)
+	}, append(seeds(f, "testdata/recorded/bcc.json"),
+		[]byte(`{"rates":[{"code":"USD","kind":"cash","purchase":"0","sale":"542"},{"code":"EUR","kind":"cash","sale":"637"}]}`),
+	)...)
+}
+
+func FuzzForte(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewForte(addr, cli)
+	}, append(seeds(f, "testdata/recorded/forte.json"),
+		[]byte(`{"status":"ok","data":{"cash":{"USD":{"buy":"","sell":"541"},"EUR":{"buy":"0","sell":"636"},"RUB":{"buy":"abc","sell":"5.9"}}}}`),
+	)...)
+}
+
+func FuzzBereke(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewBereke(addr, cli)
+	}, append(seeds(f, "testdata/recorded/bereke.json"),
+		[]byte(`{"result":null,"error":{"code":503,"message":"maintenance"}}`),
+		[]byte(`{"result":[{"fromCurrency":"USD","toCurrency":"KZT","buy":null,"sell":541.5},{"fromCurrency":"EUR","toCurrency":"KZT"}],"error":null}`),
+	)...)
+}
+
+func FuzzEurasian(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewEurasian(addr, cli)
+	}, seeds(f, "testdata/html/eurasian.html", "testdata/html/broken_rates.html")...)
+}
+
+func FuzzKursKZ(f *testing.F) {
+	drivertest.FuzzQuoted(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewKursKZ(addr, []string{"almaty"}, cli)
+	}, seeds(f, "testdata/recorded/kurskz.json")...)
+}
+
+func FuzzCBR(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewCBR(addr, cli)
+	}, seeds(f, "testdata/recorded/cbr.xml")...)
+}
+
+func FuzzECB(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewECB(addr, cli)
+	}, seeds(f, "testdata/recorded/ecb.xml")...)
+}
+
+func FuzzNBKR(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		return driver.NewNBKR(addr, cli)
+	}, seeds(f, "testdata/recorded/nbkr.xml")...)
+}
+
+func FuzzJSON(f *testing.F) {
+	cfg := driver.JSONConfig{
+		ID:            "Fuzz",
+		Items:         "data.rates",
+		Currency:      "$key",
+		PairSeparator: "/",
+		Buy:           "buy",
+		Sell:          "sell",
+		Timestamp:     "$.date",
+	}
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		cfg.URL = addr
+		d, err := driver.NewJSON(cfg, cli)
+		if err != nil {
+			f.Fatal(err)
+		}
+		return d
+	},
+		[]byte(`{"date":"2024-11-01T12:00:00Z","data":{"rates":{"USD/KZT":{"buy":537.6,"sell":"544.6"}}}}`),
+		[]byte(`{"data":{"rates":[{"buy":1,"sell":2}]}}`),
+	)
+}
+
+func FuzzHTML(f *testing.F) {
+	drivertest.Fuzz(f, func(addr string, cli driver.HTTPClient) drivertest.Driver {
+		d, err := driver.NewHTML(testHTMLConfig(addr), cli)
+		if err != nil {
+			f.Fatal(err)
+		}
+		return d
+	}, seeds(f, "testdata/html/bank_rates.html", "testdata/html/annotated_rates.html")...)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -27,9 +28,9 @@ type Halyk struct {
 type halykHistoryEntry struct {
 	Date           string               `json:"date"`
 	PrivatePersons map[string]halykPair `json:"privatePersons"`
-	LegalPersons   map[string]halykPair `json:"legalPersons"`
-	Cards          map[string]halykPair `json:"cards"`
-	CrossCourses   map[string]halykPair `json:"crossCourses"`
+	LegalPersons   map[string]halykPair `json:"legalPersons,omitempty"`
+	Cards          map[string]halykPair `json:"cards,omitempty"`
+	CrossCourses   map[string]halykPair `json:"crossCourses,omitempty"`
 }
 
 type currencyHistory struct {
@@ -39,8 +40,8 @@ type currencyHistory struct {
 
 func (c *currencyHistory) UnmarshalJSON(b []byte) error {
 	bb := bytes.TrimSpace(b)
-	if len(bb) == 0 {
-		return nil
+	if len(bb) == 0 || bytes.Equal(bb, []byte("null")) {
+		return nil // пустая история, Latest() вернёт false
 	}
 	switch bb[0] {
 	case '{':
@@ -56,7 +57,18 @@ func (c *currencyHistory) UnmarshalJSON(b []byte) error {
 		}
 		c.list = l
 	default:
-		return fmt.Errorf("unexpected currencyHistory JSON")
+		return fmt.Errorf("unexpected currencyHistory JSON: starts with %q", bb[0])
+	}
+	return nil
+}
+
+// shapeOf accepts both history shapes for schema checks.
+func (currencyHistory) shapeOf(raw any) reflect.Type {
+	switch raw.(type) {
+	case map[string]any:
+		return reflect.TypeOf(map[string]halykHistoryEntry{})
+	case []any:
+		return reflect.TypeOf([]halykHistoryEntry{})
 	}
 	return nil
 }
@@ -89,6 +101,20 @@ func NewHalyk(addr string, httpClient HTTPClient) *Halyk {
 	return &Halyk{addr: addr, httpClient: httpClient}
 }
 
+// Info describes Halyk Bank as a rate source.
+func (h *Halyk) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Halyk",
+		Name:       "Halyk Bank",
+		Homepage:   "https://halykbank.kz",
+		Logo:       "https://halykbank.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
 const (
 	pairSeparator = "/"
 	splitLimit    = 2
@@ -112,7 +138,8 @@ func (h *Halyk) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 	}
 
 	var r halykResponse
-	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+	drift, err := decodeJSON(resp.Body, &r)
+	if err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 	if !r.Result {
@@ -141,7 +168,7 @@ func (h *Halyk) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 		})
 	}
 	if len(rates) == 0 {
-		return nil, errors.New("no supported currency rates found")
+		return nil, errNoRates(drift)
 	}
-	return rates, nil
+	return rates, drift.err()
 }
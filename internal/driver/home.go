@@ -2,8 +2,6 @@ package driver
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -25,7 +23,7 @@ type homeResponse struct {
 		ID      string `json:"p_curr_id"`
 		Buy     string `json:"p_rate_buy"`
 		Sell    string `json:"p_rate_sell"`
-		Updated string `json:"p_last_upd"`
+		Updated string `json:"p_last_upd,omitempty"`
 	} `json:"currency"`
 }
 
@@ -42,6 +40,20 @@ func NewHome(addr string, httpClient HTTPClient) *Home {
 	return &Home{addr: addr, httpClient: httpClient}
 }
 
+// Info describes Home Credit Bank as a rate source.
+func (h *Home) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "HomeKZ",
+		Name:       "Home Credit Bank",
+		Homepage:   "https://home.kz",
+		Logo:       "https://home.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
 // FetchRates returns supported currency rates.
 func (h *Home) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.addr, nil)
@@ -60,7 +72,8 @@ func (h *Home) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	}
 
 	var r homeResponse
-	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+	drift, err := decodeJSON(resp.Body, &r)
+	if err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -71,6 +84,9 @@ func (h *Home) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 		if !ok {
 			continue
 		}
+		if !isNumber(c.Buy) || !isNumber(c.Sell) {
+			continue
+		}
 		rates = append(rates, &entity.ExchangeRate{
 			Source:       "HomeKZ",
 			CurrencyCode: code,
@@ -80,7 +96,7 @@ func (h *Home) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 		})
 	}
 	if len(rates) == 0 {
-		return nil, errors.New("no supported currency rates found")
+		return nil, errNoRates(drift)
 	}
-	return rates, nil
+	return rates, drift.err()
 }
@@ -0,0 +1,289 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// HTML driver scrapes exchange rates from a web page for banks and exchange offices without an API.
+// Rows and cells are located with CSS selectors from HTMLConfig. A cell selector may end with
+// "@attr" to read an attribute instead of text, e.g. "img.flag@alt".
+
+const htmlAttrSeparator = "@"
+
+// HTMLConfig describes how to scrape a rates page.
+type HTMLConfig struct {
+	// ID is stamped as Source on every produced rate.
+	ID string `json:"id"`
+	SourceConfig
+
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Row selects one element per currency, e.g. "table.rates tbody tr".
+	Row string `json:"row"`
+	// Currency, Buy and Sell select cells inside a row, e.g. "td:nth-child(2)".
+	Currency string `json:"currency"`
+	Buy      string `json:"buy"`
+	Sell     string `json:"sell"`
+
+	// CurrencyMap translates page labels ("Доллар США") into ISO codes.
+	CurrencyMap map[string]string `json:"currency_map,omitempty"`
+	// Currencies limits the produced rates; USD, EUR and RUB by default.
+	Currencies []string `json:"currencies,omitempty"`
+}
+
+type htmlField struct {
+	sel  cascadia.Selector
+	attr string
+}
+
+// HTML is a CSS selector based scraping driver.
+type HTML struct {
+	cfg        HTMLConfig
+	row        cascadia.Selector
+	currency   htmlField
+	buy        htmlField
+	sell       htmlField
+	supported  map[string]struct{}
+	httpClient HTTPClient
+}
+
+// NewHTML creates a scraping driver, compiling selectors up front so that
+// configuration mistakes surface at startup rather than on every fetch.
+func NewHTML(cfg HTMLConfig, httpClient HTTPClient) (*HTML, error) {
+	if cfg.ID == "" || cfg.URL == "" {
+		return nil, errors.New("html driver: id and url are required")
+	}
+	if err := cfg.SourceConfig.validate(); err != nil {
+		return nil, fmt.Errorf("html driver %s: %w", cfg.ID, err)
+	}
+	if len(cfg.Currencies) == 0 {
+		cfg.Currencies = []string{"USD", "EUR", "RUB"}
+	}
+
+	h := &HTML{cfg: cfg, httpClient: httpClient, supported: make(map[string]struct{}, len(cfg.Currencies))}
+	for _, c := range cfg.Currencies {
+		h.supported[strings.ToUpper(c)] = struct{}{}
+	}
+
+	var err error
+	if h.row, err = cascadia.Compile(cfg.Row); err != nil {
+		return nil, fmt.Errorf("html driver %s: row selector: %w", cfg.ID, err)
+	}
+	if h.currency, err = compileHTMLField(cfg.Currency); err != nil {
+		return nil, fmt.Errorf("html driver %s: currency selector: %w", cfg.ID, err)
+	}
+	if h.buy, err = compileHTMLField(cfg.Buy); err != nil {
+		return nil, fmt.Errorf("html driver %s: buy selector: %w", cfg.ID, err)
+	}
+	if h.sell, err = compileHTMLField(cfg.Sell); err != nil {
+		return nil, fmt.Errorf("html driver %s: sell selector: %w", cfg.ID, err)
+	}
+	return h, nil
+}
+
+func compileHTMLField(s string) (htmlField, error) {
+	sel, attr := s, ""
+	if i := strings.LastIndex(s, htmlAttrSeparator); i >= 0 {
+		sel, attr = s[:i], s[i+1:]
+	}
+	c, err := cascadia.Compile(sel)
+	if err != nil {
+		return htmlField{}, err
+	}
+	return htmlField{sel: c, attr: attr}, nil
+}
+
+// Info describes the configured source.
+func (h *HTML) Info() entity.SourceInfo {
+	return h.cfg.SourceConfig.info(h.cfg.ID, h.cfg.Currencies)
+}
+
+// LoadHTMLConfigs reads a JSON array of scraping driver configs.
+func LoadHTMLConfigs(r io.Reader) ([]HTMLConfig, error) {
+	var cfgs []HTMLConfig
+	if err := json.NewDecoder(r).Decode(&cfgs); err != nil {
+		return nil, fmt.Errorf("decode html driver configs: %w", err)
+	}
+	return cfgs, nil
+}
+
+// FetchRates downloads the page and extracts supported currency rates.
+func (h *HTML) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	return h.extract(doc)
+}
+
+func (h *HTML) extract(doc *goquery.Document) ([]*entity.ExchangeRate, error) {
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	var parseErr error
+	rows := doc.FindMatcher(h.row)
+	if rows.Length() == 0 {
+		// вёрстка поменялась: селектор строк больше ничего не находит
+		return nil, &SchemaError{Missing: []string{h.cfg.Row}}
+	}
+	rows.Each(func(_ int, row *goquery.Selection) {
+		label := h.text(row, h.currency)
+		code := strings.ToUpper(label)
+		if mapped, ok := h.cfg.CurrencyMap[label]; ok {
+			code = mapped
+		}
+		if _, ok := h.supported[code]; !ok {
+			return
+		}
+
+		// Прочерк, пустая ячейка или «н/д» — банк сейчас не котирует валюту, остальные курсы берём
+		buy, err := ParseNumber(h.text(row, h.buy))
+		if err != nil {
+			parseErr = fmt.Errorf("%s buy: %w", code, err)
+			return
+		}
+		sell, err := ParseNumber(h.text(row, h.sell))
+		if err != nil {
+			parseErr = fmt.Errorf("%s sell: %w", code, err)
+			return
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:       h.cfg.ID,
+			CurrencyCode: code,
+			Buy:          buy,
+			Sell:         sell,
+			CreatedAt:    now,
+		})
+	})
+	if len(rates) == 0 {
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		return nil, errors.New("no supported currency rates found")
+	}
+	return rates, nil
+}
+
+func (h *HTML) text(row *goquery.Selection, f htmlField) string {
+	cell := row.FindMatcher(f.sel).First()
+	if f.attr != "" {
+		v, _ := cell.Attr(f.attr)
+		return strings.TrimSpace(v)
+	}
+	return strings.TrimSpace(cell.Text())
+}
+
+// ParseNumber normalises a human formatted number ("1 234,50", "536.5 ₸", "6,35")
+// into a plain decimal string. Only the first number in the text is taken, so notes
+// such as "536.50 (+1.2)" are ignored; spaces of any kind may group its thousands. When
+// both "," and "." are present the last one is the decimal separator, a single
+// separator is always decimal and repeated ones are treated as thousands grouping.
+// Text without a number, a negative number or a range like "1-2" is an error.
+func ParseNumber(s string) (string, error) {
+	rs := []rune(s)
+	start := slices.IndexFunc(rs, isDigit)
+	if start < 0 {
+		return "", fmt.Errorf("parse number %q: no digits", s)
+	}
+	if start > 0 && isMinus(rs[start-1]) {
+		return "", fmt.Errorf("parse number %q: negative", s)
+	}
+
+	var b strings.Builder
+	i := start
+token:
+	for ; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case isDigit(r):
+		case r == ',' || r == '.':
+			// разделитель — часть числа, только если за ним снова цифра
+			if i+1 == len(rs) || !isDigit(rs[i+1]) {
+				break token
+			}
+		case unicode.IsSpace(r):
+			// пробел (в т.ч. неразрывный) внутри числа отделяет ровно три цифры
+			if !thousandsGroup(rs[i+1:]) {
+				break token
+			}
+			continue
+		default:
+			break token
+		}
+		b.WriteRune(r)
+	}
+	if i+1 < len(rs) && isMinus(rs[i]) && isDigit(rs[i+1]) {
+		return "", fmt.Errorf("parse number %q: range", s)
+	}
+	n := b.String()
+
+	lastComma, lastDot := strings.LastIndex(n, ","), strings.LastIndex(n, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			n = strings.ReplaceAll(n, ".", "")
+			n = strings.Replace(n, ",", ".", 1)
+		} else {
+			n = strings.ReplaceAll(n, ",", "")
+		}
+	case strings.Count(n, ",") > 1:
+		n = strings.ReplaceAll(n, ",", "")
+	case lastComma >= 0:
+		n = strings.Replace(n, ",", ".", 1)
+	case strings.Count(n, ".") > 1:
+		n = strings.ReplaceAll(n, ".", "")
+	}
+
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return "", fmt.Errorf("parse number %q: %w", s, err)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
+// isMinus reports a hyphen, typographic minus or dash written before or between numbers.
+func isMinus(r rune) bool { return r == '-' || r == '−' || r == '–' }
+
+// thousandsGroup reports whether rs starts with exactly three digits.
+func thousandsGroup(rs []rune) bool {
+	if len(rs) < 3 || !isDigit(rs[0]) || !isDigit(rs[1]) || !isDigit(rs[2]) {
+		return false
+	}
+	return len(rs) == 3 || !isDigit(rs[3])
+}
@@ -0,0 +1,188 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func htmlFixtureServer(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	page, err := os.ReadFile(filepath.Join("testdata", "html", name))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testHTMLConfig(url string) driver.HTMLConfig {
+	return driver.HTMLConfig{
+		ID:       "Scraped",
+		URL:      url,
+		Row:      "table.rates tbody tr",
+		Currency: "img.flag@alt",
+		Buy:      "td.buy",
+		Sell:     "td.sell",
+	}
+}
+
+func TestHTML_FetchRates(t *testing.T) {
+	t.Run("fixture", func(t *testing.T) {
+		server := htmlFixtureServer(t, "bank_rates.html")
+		d, err := driver.NewHTML(testHTMLConfig(server.URL), server.Client())
+		require.NoError(t, err)
+
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+
+		got := map[string][2]string{}
+		for _, r := range rates {
+			assert.Equal(t, "Scraped", r.Source)
+			got[r.CurrencyCode] = [2]string{r.Buy, r.Sell}
+		}
+		assert.Equal(t, [2]string{"536.5", "539.8"}, got["USD"])
+		assert.Equal(t, [2]string{"1624.1", "1630"}, got["EUR"])
+		assert.Equal(t, [2]string{"6.35", "6.75"}, got["RUB"])
+	})
+
+	t.Run("currency map from text", func(t *testing.T) {
+		server := htmlFixtureServer(t, "bank_rates.html")
+		cfg := testHTMLConfig(server.URL)
+		cfg.Currency = "td.cur"
+		cfg.CurrencyMap = map[string]string{"Доллар США": "USD"}
+		d, err := driver.NewHTML(cfg, server.Client())
+		require.NoError(t, err)
+
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "USD", rates[0].CurrencyCode)
+	})
+
+	t.Run("nothing parsable", func(t *testing.T) {
+		server := htmlFixtureServer(t, "broken_rates.html")
+		d, err := driver.NewHTML(testHTMLConfig(server.URL), server.Client())
+		require.NoError(t, err)
+		_, err = d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("currencies without a quote are skipped", func(t *testing.T) {
+		server := htmlFixtureServer(t, "partial_rates.html")
+		d, err := driver.NewHTML(testHTMLConfig(server.URL), server.Client())
+		require.NoError(t, err)
+
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "USD", rates[0].CurrencyCode)
+		assert.Equal(t, "536.5", rates[0].Buy)
+	})
+
+	t.Run("annotated cells", func(t *testing.T) {
+		// Пометки и изменения рядом с курсом не склеиваются с ним, отрицательный курс отбрасывается
+		server := htmlFixtureServer(t, "annotated_rates.html")
+		d, err := driver.NewHTML(testHTMLConfig(server.URL), server.Client())
+		require.NoError(t, err)
+
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		got := map[string][2]string{}
+		for _, r := range rates {
+			got[r.CurrencyCode] = [2]string{r.Buy, r.Sell}
+		}
+		assert.Equal(t, map[string][2]string{
+			"USD": {"536.5", "539.8"},
+			"EUR": {"1624.1", "1630"},
+		}, got)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		server := htmlFixtureServer(t, "bank_rates.html")
+		cfg := testHTMLConfig(server.URL)
+		cfg.Row = "table.missing tr"
+		d, err := driver.NewHTML(cfg, server.Client())
+		require.NoError(t, err)
+		_, err = d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		d, err := driver.NewHTML(testHTMLConfig(server.URL), server.Client())
+		require.NoError(t, err)
+		_, err = d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		cfg := testHTMLConfig("http://localhost")
+		cfg.Buy = "td[["
+		_, err := driver.NewHTML(cfg, http.DefaultClient)
+		require.Error(t, err)
+	})
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "536.50", want: "536.5"},
+		{in: "536,50", want: "536.5"},
+		{in: "1 234,56", want: "1234.56"},
+		{in: "1 234,56 ₸", want: "1234.56"},
+		{in: "1 234.5", want: "1234.5"},
+		{in: "1.234,5", want: "1234.5"},
+		{in: "1,234.5", want: "1234.5"},
+		{in: "1,234,567", want: "1234567"},
+		{in: " 6,35 ", want: "6.35"},
+		{in: "536.50 (+1.2)", want: "536.5"},
+		{in: "от 1000: 536", want: "1000"},
+		{in: "536,50 ₸ 12", want: "536.5"},
+		{in: "12 34", want: "12"},
+		{in: "536.", want: "536"},
+		{in: "−0,5", wantErr: true},
+		{in: "-536", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "—", wantErr: true},
+		{in: "н/д", wantErr: true},
+		{in: "1-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.in), func(t *testing.T) {
+			got, err := driver.ParseNumber(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadHTMLConfigs(t *testing.T) {
+	cfgs, err := driver.LoadHTMLConfigs(strings.NewReader(`[
+		{"id":"Office","url":"https://example.kz/rates","row":"tr","currency":"td.c","buy":"td.b","sell":"td.s"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, cfgs, 1)
+	assert.Equal(t, "tr", cfgs[0].Row)
+}
@@ -0,0 +1,49 @@
+package driver_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Каждый встроенный драйвер должен описывать себя полностью: это читают реестр, UI и API.
+func TestBuiltinDrivers_Info(t *testing.T) {
+	cli := http.DefaultClient
+	const addr = "http://localhost"
+	drivers := []interface{ Info() entity.SourceInfo }{
+		driver.NewKaspi(addr, cli),
+		driver.NewHalyk(addr, cli),
+		driver.NewFreedom(addr, cli),
+		driver.NewRBK(addr, cli),
+		driver.NewHome(addr, cli),
+		driver.NewNBRK(addr, cli),
+		driver.NewJusan(addr, cli),
+		driver.NewBCC(addr, cli),
+		driver.NewForte(addr, cli),
+		driver.NewBereke(addr, cli),
+		driver.NewEurasian(addr, cli),
+		driver.NewKursKZ(addr, nil, cli),
+		driver.NewCBR(addr, cli),
+		driver.NewECB(addr, cli),
+		driver.NewNBKR(addr, cli),
+	}
+	seen := map[string]bool{}
+	for _, d := range drivers {
+		info := d.Info()
+		t.Run(info.ID, func(t *testing.T) {
+			assert.NotEmpty(t, info.ID)
+			assert.False(t, seen[info.ID], "duplicate id")
+			seen[info.ID] = true
+			assert.NotEmpty(t, info.Name)
+			assert.NotEmpty(t, info.Homepage)
+			assert.NotEmpty(t, info.Kind)
+			assert.NotEmpty(t, info.Currencies)
+			assert.Len(t, info.Channels, 1)
+			assert.Positive(t, info.Schedule)
+		})
+	}
+}
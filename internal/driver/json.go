@@ -0,0 +1,319 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// JSON driver fetches exchange rates from any bank that publishes them as JSON.
+// Everything bank-specific (request, where the list of rates lives, which fields hold
+// currency/buy/sell) is described by JSONConfig, so new sources are onboarded from config.
+//
+// Path expressions are dot separated: "data.cash" walks objects by key and arrays by index
+// ("data.history.0.rates"). Field paths are relative to a list item, with two special forms:
+//   - "$key"   — the key (or index) of the item when the list is an object, e.g. "USD/KZT";
+//   - "$.a.b"  — absolute path from the document root, e.g. a response-wide date.
+
+const (
+	jsonPathSeparator = "."
+	jsonPathKey       = "$key"
+	jsonPathRoot      = "$."
+	jsonTimestampUnix = "unix"
+)
+
+// JSONConfig describes how to request and parse a JSON rates endpoint.
+type JSONConfig struct {
+	// ID is stamped as Source on every produced rate.
+	ID string `json:"id"`
+	SourceConfig
+
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // GET by default
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+
+	// Items points to an array or object holding one entry per currency.
+	Items    string `json:"items"`
+	Currency string `json:"currency"`
+	Buy      string `json:"buy"`
+	Sell     string `json:"sell"`
+	// Quote is an optional path to the quote currency of the item; items quoted
+	// in anything but QuoteCurrency are skipped.
+	Quote string `json:"quote,omitempty"`
+	// Timestamp is an optional path to the rate time; parsed with TimestampLayout
+	// (RFC3339 by default, "unix" for epoch seconds).
+	Timestamp       string `json:"timestamp,omitempty"`
+	TimestampLayout string `json:"timestamp_layout,omitempty"`
+
+	// PairSeparator splits currency values like "USD/KZT" into base and quote.
+	PairSeparator string `json:"pair_separator,omitempty"`
+	// CurrencyMap translates bank-specific ids ("1", "17") into ISO codes.
+	CurrencyMap map[string]string `json:"currency_map,omitempty"`
+	// Currencies limits the produced rates; USD, EUR and RUB by default.
+	Currencies    []string `json:"currencies,omitempty"`
+	QuoteCurrency string   `json:"quote_currency,omitempty"` // KZT by default
+}
+
+// JSON is a declarative driver configured by JSONConfig.
+type JSON struct {
+	cfg        JSONConfig
+	supported  map[string]struct{}
+	httpClient HTTPClient
+}
+
+// NewJSON creates a driver from config, filling defaults and validating required fields.
+func NewJSON(cfg JSONConfig, httpClient HTTPClient) (*JSON, error) {
+	if cfg.ID == "" || cfg.URL == "" {
+		return nil, errors.New("json driver: id and url are required")
+	}
+	if cfg.Items == "" || cfg.Currency == "" || cfg.Buy == "" || cfg.Sell == "" {
+		return nil, fmt.Errorf("json driver %s: items, currency, buy and sell paths are required", cfg.ID)
+	}
+	if err := cfg.SourceConfig.validate(); err != nil {
+		return nil, fmt.Errorf("json driver %s: %w", cfg.ID, err)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+	if len(cfg.Currencies) == 0 {
+		cfg.Currencies = []string{"USD", "EUR", "RUB"}
+	}
+	if cfg.QuoteCurrency == "" {
+		cfg.QuoteCurrency = dstKZT
+	}
+	if cfg.TimestampLayout == "" {
+		cfg.TimestampLayout = time.RFC3339
+	}
+
+	supported := make(map[string]struct{}, len(cfg.Currencies))
+	for _, c := range cfg.Currencies {
+		supported[strings.ToUpper(c)] = struct{}{}
+	}
+	return &JSON{cfg: cfg, supported: supported, httpClient: httpClient}, nil
+}
+
+// Info describes the configured source.
+func (j *JSON) Info() entity.SourceInfo {
+	return j.cfg.SourceConfig.info(j.cfg.ID, j.cfg.Currencies)
+}
+
+// LoadJSONConfigs reads a JSON array of driver configs.
+func LoadJSONConfigs(r io.Reader) ([]JSONConfig, error) {
+	var cfgs []JSONConfig
+	if err := json.NewDecoder(r).Decode(&cfgs); err != nil {
+		return nil, fmt.Errorf("decode json driver configs: %w", err)
+	}
+	return cfgs, nil
+}
+
+// FetchRates requests the configured endpoint and extracts supported currency rates.
+func (j *JSON) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	var body io.Reader
+	if j.cfg.Body != "" {
+		body = bytes.NewBufferString(j.cfg.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, j.cfg.Method, j.cfg.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range j.cfg.Headers {
+		req.Header[k] = []string{v} // как есть, некоторые банки требуют неканонические заголовки
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var doc any
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err = dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return j.extract(doc)
+}
+
+type jsonItem struct {
+	key   string
+	value any
+}
+
+func (j *JSON) extract(doc any) ([]*entity.ExchangeRate, error) {
+	list, ok := lookupJSONPath(doc, j.cfg.Items)
+	if !ok {
+		return nil, &SchemaError{Missing: []string{j.cfg.Items}}
+	}
+
+	var items []jsonItem
+	switch l := list.(type) {
+	case []any:
+		for i, v := range l {
+			items = append(items, jsonItem{key: strconv.Itoa(i), value: v})
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(l))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			items = append(items, jsonItem{key: k, value: l[k]})
+		}
+	default:
+		return nil, &SchemaError{Mismatched: []string{j.cfg.Items + " (want array or object)"}}
+	}
+
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, it := range items {
+		if rate := j.extractItem(doc, it, now); rate != nil {
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("no supported currency rates found")
+	}
+	return rates, nil
+}
+
+// extractItem returns nil rate for items that are skipped (unsupported currency or quote).
+func (j *JSON) extractItem(doc any, it jsonItem, now time.Time) *entity.ExchangeRate {
+	code := j.field(doc, it, j.cfg.Currency)
+	quote := ""
+	if j.cfg.PairSeparator != "" {
+		parts := strings.SplitN(code, j.cfg.PairSeparator, splitLimit)
+		code = parts[0]
+		if len(parts) == splitLimit {
+			quote = parts[1]
+		}
+	}
+	if mapped, ok := j.cfg.CurrencyMap[code]; ok {
+		code = mapped
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := j.supported[code]; !ok {
+		return nil
+	}
+
+	if j.cfg.Quote != "" {
+		quote = j.field(doc, it, j.cfg.Quote)
+	}
+	if quote != "" && !strings.EqualFold(strings.TrimSpace(quote), j.cfg.QuoteCurrency) {
+		return nil
+	}
+
+	// Неразборчивое или будущее время не должно стоить источнику всего опроса: берём момент опроса
+	createdAt := now
+	if j.cfg.Timestamp != "" {
+		ts := j.field(doc, it, j.cfg.Timestamp)
+		if parsed, err := parseJSONTimestamp(ts, j.cfg.TimestampLayout); err == nil && !parsed.After(now) {
+			createdAt = parsed
+		}
+	}
+
+	buy, sell := j.field(doc, it, j.cfg.Buy), j.field(doc, it, j.cfg.Sell)
+	if !isNumber(buy) || !isNumber(sell) {
+		return nil // валюта сейчас не котируется
+	}
+
+	rate := &entity.ExchangeRate{
+		Source:       j.cfg.ID,
+		CurrencyCode: code,
+		Buy:          buy,
+		Sell:         sell,
+		CreatedAt:    createdAt,
+	}
+	if q := strings.ToUpper(j.cfg.QuoteCurrency); q != entity.QuoteKZT {
+		rate.QuoteCurrency = q
+	}
+	return rate
+}
+
+// field resolves a config path for the item and renders the value as string.
+func (j *JSON) field(doc any, it jsonItem, path string) string {
+	switch {
+	case path == jsonPathKey:
+		return it.key
+	case strings.HasPrefix(path, jsonPathRoot):
+		v, _ := lookupJSONPath(doc, strings.TrimPrefix(path, jsonPathRoot))
+		return jsonScalar(v)
+	default:
+		v, _ := lookupJSONPath(it.value, path)
+		return jsonScalar(v)
+	}
+}
+
+// lookupJSONPath walks a decoded JSON value by dot separated path.
+func lookupJSONPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, seg := range strings.Split(path, jsonPathSeparator) {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func jsonScalar(v any) string {
+	switch s := v.(type) {
+	case string:
+		return strings.TrimSpace(s)
+	case json.Number:
+		return s.String()
+	case bool:
+		return strconv.FormatBool(s)
+	default:
+		return ""
+	}
+}
+
+func parseJSONTimestamp(s, layout string) (time.Time, error) {
+	if layout == jsonTimestampUnix {
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
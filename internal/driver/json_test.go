@@ -0,0 +1,257 @@
+package driver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON_FetchRates(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       driver.JSONConfig
+		body      string
+		wantCodes []string
+		wantBuy   map[string]string
+	}{
+		{
+			name: "array with currency map (home.kz style)",
+			cfg: driver.JSONConfig{
+				Items:       "currency",
+				Currency:    "p_curr_id",
+				Buy:         "p_rate_buy",
+				Sell:        "p_rate_sell",
+				CurrencyMap: map[string]string{"1": "USD", "17": "EUR", "16": "RUB"},
+			},
+			body: `{"currency":[
+				{"p_curr_id":"1","p_rate_buy":"532.8","p_rate_sell":"534.8"},
+				{"p_curr_id":"17","p_rate_buy":"624.11","p_rate_sell":"627.11"},
+				{"p_curr_id":"20","p_rate_buy":"66.72","p_rate_sell":"78.72"}
+			]}`,
+			wantCodes: []string{"USD", "EUR"},
+			wantBuy:   map[string]string{"USD": "532.8", "EUR": "624.11"},
+		},
+		{
+			name: "array with quote field (freedom style)",
+			cfg: driver.JSONConfig{
+				Items:    "data.cash",
+				Currency: "buyCode",
+				Quote:    "sellCode",
+				Buy:      "buyRate",
+				Sell:     "sellRate",
+			},
+			body: `{"success":true,"data":{"cash":[
+				{"buyCode":"USD","sellCode":"KZT","buyRate":"540","sellRate":"545"},
+				{"buyCode":"EUR","sellCode":"USD","buyRate":"1.07","sellRate":"1.1"},
+				{"buyCode":"RUB","sellCode":"KZT","buyRate":"6.5","sellRate":"7"}
+			]}}`,
+			wantCodes: []string{"USD", "RUB"},
+			wantBuy:   map[string]string{"USD": "540", "RUB": "6.5"},
+		},
+		{
+			name: "indexed object with pair keys and numbers (halyk style)",
+			cfg: driver.JSONConfig{
+				Items:         "data.currencyHistory.0.privatePersons",
+				Currency:      "$key",
+				PairSeparator: "/",
+				Buy:           "buy",
+				Sell:          "sell",
+			},
+			body: `{"result":true,"data":{"currencyHistory":{"0":{"privatePersons":{
+				"USD/KZT":{"buy":537.6,"sell":544.6},
+				"XAU/USD":{"buy":3178.16,"sell":3568.7}
+			}}}}}`,
+			wantCodes: []string{"USD"},
+			wantBuy:   map[string]string{"USD": "537.6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cfg := tt.cfg
+			cfg.ID = "TestBank"
+			cfg.URL = server.URL
+			d, err := driver.NewJSON(cfg, server.Client())
+			require.NoError(t, err)
+
+			rates, err := d.FetchRates(context.Background())
+			require.NoError(t, err)
+			require.Len(t, rates, len(tt.wantCodes))
+			for _, r := range rates {
+				assert.Equal(t, "TestBank", r.Source)
+				assert.Contains(t, tt.wantCodes, r.CurrencyCode)
+				assert.Equal(t, tt.wantBuy[r.CurrencyCode], r.Buy)
+			}
+		})
+	}
+}
+
+func TestJSON_RequestAndTimestamp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "ru", r.Header.Get("gLanguage"))
+		b, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"rate_types":["BUY"]}`, string(b))
+		_, _ = w.Write([]byte(`{"date":"2024-11-01T12:00:00Z","body":[{"currency":"USD","buy":450,"sale":460}]}`))
+	}))
+	defer server.Close()
+
+	d, err := driver.NewJSON(driver.JSONConfig{
+		ID:        "Post",
+		URL:       server.URL,
+		Method:    http.MethodPost,
+		Headers:   map[string]string{"gLanguage": "ru"},
+		Body:      `{"rate_types":["BUY"]}`,
+		Items:     "body",
+		Currency:  "currency",
+		Buy:       "buy",
+		Sell:      "sale",
+		Timestamp: "$.date",
+	}, server.Client())
+	require.NoError(t, err)
+
+	rates, err := d.FetchRates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, "460", rates[0].Sell)
+	assert.Equal(t, time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC), rates[0].CreatedAt)
+}
+
+func TestJSON_TimestampFallsBackToNow(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[
+			{"c":"USD","b":"450","s":"460","t":"yesterday"},
+			{"c":"EUR","b":"520","s":"530","t":"` + future + `"},
+			{"c":"RUB","b":"5.5","s":"6","t":"2024-11-01T12:00:00Z"}
+		]}`))
+	}))
+	defer server.Close()
+
+	d, err := driver.NewJSON(driver.JSONConfig{
+		ID: "Stamped", URL: server.URL, Items: "items", Currency: "c", Buy: "b", Sell: "s", Timestamp: "t",
+	}, server.Client())
+	require.NoError(t, err)
+
+	before := time.Now().UTC()
+	rates, err := d.FetchRates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+	// Неразборчивое и будущее время заменяются моментом опроса, остальные курсы не теряются
+	for _, r := range rates[:2] {
+		assert.False(t, r.CreatedAt.Before(before.Truncate(time.Second)), r.CurrencyCode)
+		assert.False(t, r.CreatedAt.After(time.Now()), r.CurrencyCode)
+	}
+	assert.Equal(t, time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC), rates[2].CreatedAt)
+}
+
+func TestJSON_QuoteCurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"rates":[{"c":"USD","base":"RUB","b":"96.1","s":"97.3"},{"c":"EUR","base":"KZT","b":"520","s":"530"}]}`))
+	}))
+	defer server.Close()
+
+	d, err := driver.NewJSON(driver.JSONConfig{
+		ID: "Ref", URL: server.URL, Items: "rates", Currency: "c", Quote: "base", Buy: "b", Sell: "s",
+		QuoteCurrency: "RUB",
+	}, server.Client())
+	require.NoError(t, err)
+
+	rates, err := d.FetchRates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, "RUB", rates[0].QuoteCurrency)
+	assert.Equal(t, "RUB", rates[0].Quote())
+}
+
+func TestJSON_Errors(t *testing.T) {
+	cfg := driver.JSONConfig{ID: "Bad", Items: "items", Currency: "c", Buy: "b", Sell: "s"}
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "non 200", status: http.StatusBadGateway},
+		{name: "invalid json", status: http.StatusOK, body: "{"},
+		{name: "missing items", status: http.StatusOK, body: `{"other":[]}`},
+		{name: "items scalar", status: http.StatusOK, body: `{"items":"x"}`},
+		{name: "no supported", status: http.StatusOK, body: `{"items":[{"c":"XAU","b":"1","s":"2"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c := cfg
+			c.URL = server.URL
+			c.Timestamp = "t"
+			d, err := driver.NewJSON(c, server.Client())
+			require.NoError(t, err)
+			_, err = d.FetchRates(context.Background())
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := driver.NewJSON(driver.JSONConfig{ID: "NoURL"}, http.DefaultClient)
+		require.Error(t, err)
+		_, err = driver.NewJSON(driver.JSONConfig{ID: "NoPaths", URL: "http://localhost"}, http.DefaultClient)
+		require.Error(t, err)
+	})
+}
+
+func TestLoadJSONConfigs(t *testing.T) {
+	cfgs, err := driver.LoadJSONConfigs(strings.NewReader(`[
+		{"id":"HomeKZ","url":"https://home.kz/api/public/getCurrency","items":"currency",
+		 "currency":"p_curr_id","buy":"p_rate_buy","sell":"p_rate_sell","currency_map":{"1":"USD"}}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, cfgs, 1)
+	assert.Equal(t, "HomeKZ", cfgs[0].ID)
+	assert.Equal(t, "USD", cfgs[0].CurrencyMap["1"])
+
+	_, err = driver.LoadJSONConfigs(strings.NewReader(`{`))
+	require.Error(t, err)
+}
+
+func TestJSON_Info(t *testing.T) {
+	cfgs, err := driver.LoadJSONConfigs(strings.NewReader(`[
+		{"id":"Shinhan","name":"Shinhan Bank","homepage":"https://shinhan.kz","kind":"bank",
+		 "channel":"non_cash","schedule":"1h","url":"https://shinhan.kz/rates","items":"rates",
+		 "currency":"code","buy":"buy","sell":"sell"}
+	]`))
+	require.NoError(t, err)
+
+	d, err := driver.NewJSON(cfgs[0], http.DefaultClient)
+	require.NoError(t, err)
+	info := d.Info()
+	assert.Equal(t, "Shinhan", info.ID)
+	assert.Equal(t, "Shinhan Bank", info.Name)
+	assert.Equal(t, entity.SourceKindBank, info.Kind)
+	assert.Equal(t, []string{entity.ChannelNonCash}, info.Channels)
+	assert.Equal(t, time.Hour, info.Schedule)
+	assert.Equal(t, []string{"USD", "EUR", "RUB"}, info.Currencies)
+
+	cfg := cfgs[0]
+	cfg.Schedule = "hourly"
+	_, err = driver.NewJSON(cfg, http.DefaultClient)
+	require.Error(t, err)
+}
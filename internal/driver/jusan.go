@@ -0,0 +1,110 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Jusan driver fetches exchange rates from Jusan Bank API.
+// Default endpoint: https://jusan.kz/api/v1/exchange-rates
+// We take rates of type "individual" (retail) and pick supported currencies (USD, EUR, RUB) vs KZT.
+
+type Jusan struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type jusanResponse struct {
+	Success bool        `json:"success"`
+	Data    []jusanItem `json:"data"`
+}
+
+type jusanItem struct {
+	CurrencyCode string  `json:"currency_code"`
+	Type         string  `json:"type"`
+	BuyRate      float64 `json:"buy_rate"`
+	SellRate     float64 `json:"sell_rate"`
+}
+
+const jusanTypeIndividual = "individual"
+
+// NewJusan creates Jusan driver.
+func NewJusan(addr string, httpClient HTTPClient) *Jusan {
+	return &Jusan{addr: addr, httpClient: httpClient}
+}
+
+// Info describes Jusan Bank as a rate source.
+func (j *Jusan) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Jusan",
+		Name:       "Jusan Bank",
+		Homepage:   "https://jusan.kz",
+		Logo:       "https://jusan.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   bankSchedule,
+	}
+}
+
+// FetchRates returns retail rates for supported currencies.
+func (j *Jusan) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var jr jusanResponse
+	drift, err := decodeJSON(resp.Body, &jr)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if !jr.Success {
+		return nil, errors.New("jusan api success false")
+	}
+
+	supported := map[string]struct{}{"USD": {}, "EUR": {}, "RUB": {}}
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, it := range jr.Data {
+		if it.Type != jusanTypeIndividual {
+			continue
+		}
+		code := strings.ToUpper(it.CurrencyCode)
+		if _, ok := supported[code]; !ok {
+			continue
+		}
+		// Отсутствующий или null курс декодируется в 0: валюта сейчас не котируется
+		if it.BuyRate <= 0 || it.SellRate <= 0 {
+			continue
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:       "Jusan",
+			CurrencyCode: code,
+			Buy:          strconv.FormatFloat(it.BuyRate, 'f', -1, 64),
+			Sell:         strconv.FormatFloat(it.SellRate, 'f', -1, 64),
+			CreatedAt:    now,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
+}
@@ -0,0 +1,89 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJusan_FetchRates(t *testing.T) {
+	const base = `{"success":true,"data":[
+		{"currency_code":"USD","type":"individual","buy_rate":537.5,"sell_rate":542.5},
+		{"currency_code":"EUR","type":"individual","buy_rate":628,"sell_rate":636},
+		{"currency_code":"rub","type":"individual","buy_rate":6.5,"sell_rate":7},
+		{"currency_code":"USD","type":"legal","buy_rate":538,"sell_rate":541},
+		{"currency_code":"CNY","type":"individual","buy_rate":72,"sell_rate":77}
+	]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(base))
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "Jusan", rates[0].Source)
+		assert.Equal(t, "537.5", rates[0].Buy)
+		assert.Equal(t, "RUB", rates[2].CurrencyCode)
+	})
+
+	t.Run("unquoted sides are skipped", func(t *testing.T) {
+		// Null, пустой или нулевой курс — валюта не котируется, а не курс 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"currency_code":"USD","type":"individual","buy_rate":null,"sell_rate":542.5},{"currency_code":"EUR","type":"individual","buy_rate":0,"sell_rate":636},{"currency_code":"RUB","type":"individual","buy_rate":6.5,"sell_rate":7}]}`))
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "RUB", rates[0].CurrencyCode)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("{"))
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("success false", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"success":false,"data":[]}`))
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"currency_code":"CNY","type":"individual","buy_rate":72,"sell_rate":77}]}`))
+		}))
+		defer server.Close()
+		d := driver.NewJusan(server.URL, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -3,7 +3,6 @@ package driver
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -36,7 +35,7 @@ type KaspiResponse struct {
 
 // NewKaspi creates a new Kaspi driver.
 // Default addr is "https://guide.kaspi.kz/client/api/v2/intgr/currency/rate/aggregate".
-// Seems that addr works only from KZ location.
+// Seems that addr works only from KZ location; route it through an egress node with EXR_EGRESS.
 func NewKaspi(addr string, httpClient HTTPClient) *Kaspi {
 	return &Kaspi{
 		addr:       addr,
@@ -44,6 +43,20 @@ func NewKaspi(addr string, httpClient HTTPClient) *Kaspi {
 	}
 }
 
+// Info describes Kaspi Bank as a rate source.
+func (k *Kaspi) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "Kaspi",
+		Name:       "Kaspi Bank",
+		Homepage:   "https://kaspi.kz",
+		Logo:       "https://kaspi.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelNonCash},
+		Schedule:   bankSchedule,
+	}
+}
+
 // FetchRates fetches exchange rates from the Kaspi API.
 func (k *Kaspi) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	reqBody := bytes.NewBufferString(kaspiRequestBody)
@@ -67,7 +80,8 @@ func (k *Kaspi) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 	}
 
 	var res KaspiResponse
-	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+	drift, err := decodeJSON(resp.Body, &res)
+	if err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -82,6 +96,9 @@ func (k *Kaspi) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 	now := time.Now().UTC()
 	var rates []*entity.ExchangeRate
 	for _, item := range res.Body {
+		if !isCurrencyCode(item.Currency) {
+			continue
+		}
 		rates = append(rates, &entity.ExchangeRate{
 			Source:       "Kaspi",
 			CurrencyCode: item.Currency,
@@ -91,5 +108,8 @@ func (k *Kaspi) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 		})
 	}
 
-	return rates, nil
+	if len(rates) == 0 {
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
 }
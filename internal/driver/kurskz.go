@@ -0,0 +1,146 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// KursKZ driver fetches cash rates of individual exchange offices from a kurs.kz style aggregator.
+// Default endpoint: https://kurs.kz/api/punkts (queried once per city: ?city=almaty).
+// Every office becomes a separate Branch of source "KursKZ", keyed by the aggregator's office id
+// (names repeat across and within cities), with its name as BranchName, City and Address;
+// rates are pairs [buy, sell] vs KZT, zero means the office does not trade the currency.
+
+type KursKZ struct {
+	addr       string
+	cities     []string
+	httpClient HTTPClient
+}
+
+type kursKZOffice struct {
+	ID         int64                 `json:"id"`
+	Name       string                `json:"name,omitempty"`
+	City       string                `json:"city,omitempty"`
+	Address    string                `json:"address,omitempty"`
+	ActualTime int64                 `json:"actualTime,omitempty"` // unix seconds
+	Data       map[string][2]float64 `json:"data"`
+}
+
+const kursKZCityParam = "city"
+
+// NewKursKZ creates aggregator driver for the given city slugs (almaty, astana, shymkent, ...).
+func NewKursKZ(addr string, cities []string, httpClient HTTPClient) *KursKZ {
+	return &KursKZ{addr: addr, cities: cities, httpClient: httpClient}
+}
+
+// Info describes kurs.kz as a rate source.
+func (k *KursKZ) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "KursKZ",
+		Name:       "kurs.kz",
+		Homepage:   "https://kurs.kz",
+		Logo:       "https://kurs.kz/favicon.ico",
+		Kind:       entity.SourceKindExchangeOffice,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelCash},
+		Schedule:   officeSchedule,
+	}
+}
+
+// FetchRates returns per-office rates for supported currencies in all configured cities.
+// A city that fails is skipped; the fetch fails only when no city returned rates.
+func (k *KursKZ) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	var rates []*entity.ExchangeRate
+	var drift *SchemaError
+	var cityErrs []error
+	for _, city := range k.cities {
+		offices, cityDrift, err := k.fetchCity(ctx, city)
+		if err != nil {
+			cityErrs = append(cityErrs, fmt.Errorf("city %s: %w", city, err))
+			continue
+		}
+		if drift == nil {
+			drift = cityDrift // все города отдаёт один API, достаточно первого отклонения
+		}
+		rates = append(rates, officeRates(offices)...)
+	}
+	if len(rates) == 0 {
+		if len(cityErrs) > 0 {
+			return nil, errors.Join(cityErrs...)
+		}
+		return nil, errNoRates(drift)
+	}
+	return rates, drift.err()
+}
+
+func (k *KursKZ) fetchCity(ctx context.Context, city string) ([]kursKZOffice, *SchemaError, error) {
+	u, err := url.Parse(k.addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse addr: %w", err)
+	}
+	q := u.Query()
+	q.Set(kursKZCityParam, city)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var offices []kursKZOffice
+	drift, err := decodeJSON(resp.Body, &offices)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode response: %w", err)
+	}
+	return offices, drift, nil
+}
+
+func officeRates(offices []kursKZOffice) []*entity.ExchangeRate {
+	supported := []string{"USD", "EUR", "RUB"}
+	now := time.Now().UTC()
+	var rates []*entity.ExchangeRate
+	for _, o := range offices {
+		createdAt := now
+		// Время обновления из будущего (часы агрегатора спешат) считаем текущим
+		if actual := time.Unix(o.ActualTime, 0).UTC(); o.ActualTime > 0 && actual.Before(now) {
+			createdAt = actual
+		}
+		// Названия обменников повторяются, серию различаем по id
+		branch := strconv.FormatInt(o.ID, 10)
+		for _, cur := range supported {
+			pair, ok := o.Data[cur]
+			if !ok || pair[0] <= 0 || pair[1] <= 0 {
+				continue
+			}
+			rates = append(rates, &entity.ExchangeRate{
+				Source:       "KursKZ",
+				Branch:       branch,
+				BranchName:   o.Name,
+				City:         o.City,
+				Address:      o.Address,
+				CurrencyCode: cur,
+				Buy:          strconv.FormatFloat(pair[0], 'f', -1, 64),
+				Sell:         strconv.FormatFloat(pair[1], 'f', -1, 64),
+				CreatedAt:    createdAt,
+			})
+		}
+	}
+	return rates
+}
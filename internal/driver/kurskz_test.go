@@ -0,0 +1,120 @@
+package driver_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKursKZ_FetchRates(t *testing.T) {
+	byCity := map[string]string{
+		"almaty": `[
+			{"id":1,"name":"Алтын","city":"Алматы","address":"пр. Абая, 10","actualTime":1729240000,
+			 "data":{"USD":[536.5,539],"EUR":[628,636],"RUB":[0,0],"CNY":[72,76]}},
+			{"id":2,"name":"","city":"Алматы","address":"ул. Сатпаева, 5","actualTime":0,
+			 "data":{"USD":[537,539.5]}}
+		]`,
+		"astana": `[
+			{"id":3,"name":"Алтын","city":"Астана","address":"пр. Республики, 1","actualTime":1729240000,
+			 "data":{"USD":[535,540]}}
+		]`,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(byCity[r.URL.Query().Get("city")]))
+		}))
+		defer server.Close()
+
+		d := driver.NewKursKZ(server.URL+"/api/punkts", []string{"almaty", "astana"}, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 4)
+
+		first := rates[0]
+		assert.Equal(t, "KursKZ", first.Source)
+		assert.Equal(t, "1", first.Branch)
+		assert.Equal(t, "Алтын", first.BranchName)
+		assert.Equal(t, "Алтын", first.BranchLabel())
+		assert.Equal(t, "Алматы", first.City)
+		assert.Equal(t, "пр. Абая, 10", first.Address)
+		assert.Equal(t, "USD", first.CurrencyCode)
+		assert.Equal(t, "536.5", first.Buy)
+		assert.Equal(t, time.Unix(1729240000, 0).UTC(), first.CreatedAt)
+
+		assert.Equal(t, "2", rates[2].BranchLabel()) // без имени — id офиса
+		// Одноимённый обменник в другом городе — отдельная серия
+		assert.Equal(t, "Астана", rates[3].City)
+		assert.Equal(t, "Алтын", rates[3].BranchName)
+		assert.NotEqual(t, first.Key(), rates[3].Key())
+	})
+
+	t.Run("failed city is skipped", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("city") == "almaty" {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte(byCity[r.URL.Query().Get("city")]))
+		}))
+		defer server.Close()
+
+		d := driver.NewKursKZ(server.URL, []string{"almaty", "astana"}, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "Астана", rates[0].City)
+	})
+
+	t.Run("future update time is clamped to now", func(t *testing.T) {
+		future := time.Now().Add(24 * time.Hour).Unix()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprintf(w, `[{"id":1,"name":"x","actualTime":%d,"data":{"USD":[536,539]}}]`, future)
+		}))
+		defer server.Close()
+
+		d := driver.NewKursKZ(server.URL, []string{"almaty"}, server.Client())
+		rates, err := d.FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.False(t, rates[0].CreatedAt.After(time.Now()))
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		d := driver.NewKursKZ(server.URL, []string{"almaty", "astana"}, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.ErrorContains(t, err, "city almaty")
+		require.ErrorContains(t, err, "city astana")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("[{"))
+		}))
+		defer server.Close()
+		d := driver.NewKursKZ(server.URL, []string{"almaty"}, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`[{"id":1,"name":"x","data":{"CNY":[72,76]}}]`))
+		}))
+		defer server.Close()
+		d := driver.NewKursKZ(server.URL, []string{"almaty"}, server.Client())
+		_, err := d.FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,103 @@
+package driver
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// NBKR driver fetches official rates of the National Bank of the Kyrgyz Republic.
+// Default endpoint: https://www.nbkr.kg/XML/daily.xml
+// Values are KGS per Nominal units with decimal comma.
+
+type NBKR struct {
+	addr       string
+	httpClient HTTPClient
+}
+
+type nbkrRates struct {
+	XMLName  xml.Name `xml:"CurrencyRates"`
+	Date     string   `xml:"Date,attr"` // 18.10.2024
+	Currency []struct {
+		ISOCode string `xml:"ISOCode,attr"`
+		Nominal string `xml:"Nominal"`
+		Value   string `xml:"Value"`
+	} `xml:"Currency"`
+}
+
+const quoteKGS = "KGS"
+
+// NewNBKR creates National Bank of Kyrgyzstan driver.
+func NewNBKR(addr string, httpClient HTTPClient) *NBKR {
+	return &NBKR{addr: addr, httpClient: httpClient}
+}
+
+// Info describes National Bank of the Kyrgyz Republic as a rate source.
+func (n *NBKR) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "NBKR",
+		Name:       "Национальный банк КР",
+		Homepage:   "https://www.nbkr.kg",
+		Logo:       "https://www.nbkr.kg/favicon.ico",
+		Kind:       entity.SourceKindCentralBank,
+		Currencies: []string{"USD", "EUR", "RUB", "KZT"},
+		Channels:   []string{entity.ChannelOfficial},
+		Schedule:   centralBankSchedule,
+	}
+}
+
+// FetchRates returns official KGS rates for USD, EUR, RUB and KZT.
+func (n *NBKR) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var nr nbkrRates
+	if err = decodeXML(xml.NewDecoder(resp.Body), &nr); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(nr.Currency) == 0 {
+		return nil, &SchemaError{Missing: []string{"CurrencyRates.Currency"}}
+	}
+
+	createdAt := effectiveAt(dottedDayFirst, nr.Date)
+
+	supported := map[string]struct{}{"USD": {}, "EUR": {}, "RUB": {}, "KZT": {}}
+	var rates []*entity.ExchangeRate
+	for _, c := range nr.Currency {
+		if _, ok := supported[c.ISOCode]; !ok {
+			continue
+		}
+		value, err := perUnit(c.Value, c.Nominal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.ISOCode, err)
+		}
+		rates = append(rates, &entity.ExchangeRate{
+			Source:        "NBKR",
+			CurrencyCode:  c.ISOCode,
+			QuoteCurrency: quoteKGS,
+			Buy:           value,
+			Sell:          value,
+			CreatedAt:     createdAt,
+		})
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("no supported currency rates found")
+	}
+	return rates, nil
+}
@@ -0,0 +1,58 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nbkrDaily = `<?xml version="1.0" encoding="UTF-8"?>
+<CurrencyRates Name="Daily Exchange Rates" Date="18.10.2024">
+	<Currency ISOCode="USD"><Nominal>1</Nominal><Value>85,8100</Value></Currency>
+	<Currency ISOCode="EUR"><Nominal>1</Nominal><Value>93,1845</Value></Currency>
+	<Currency ISOCode="KZT"><Nominal>1</Nominal><Value>0,1768</Value></Currency>
+	<Currency ISOCode="RUB"><Nominal>1</Nominal><Value>0,8878</Value></Currency>
+</CurrencyRates>`
+
+func TestNBKR_FetchRates(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(nbkrDaily))
+		}))
+		defer server.Close()
+
+		rates, err := driver.NewNBKR(server.URL, server.Client()).FetchRates(context.Background())
+		require.NoError(t, err)
+		require.Len(t, rates, 4)
+
+		assert.Equal(t, "NBKR", rates[0].Source)
+		assert.Equal(t, "KGS", rates[0].QuoteCurrency)
+		assert.Equal(t, "85.81", rates[0].Buy)
+		assert.Equal(t, "0.1768", rates[2].Sell)
+		assert.Equal(t, time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC), rates[0].CreatedAt)
+	})
+
+	t.Run("non 200", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		_, err := driver.NewNBKR(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("no supported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<CurrencyRates Date="18.10.2024"><Currency ISOCode="UZS"><Nominal>1</Nominal><Value>0,0067</Value></Currency></CurrencyRates>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewNBKR(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+	})
+}
@@ -3,7 +3,9 @@ package driver
 import (
 	"context"
 	"encoding/xml"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Mi7teR/exr/internal/entity"
 	"github.com/Mi7teR/exr/internal/errors"
@@ -24,6 +26,23 @@ func NewNBRK(addr string, client HTTPClient) *NBRK {
 	}
 }
 
+// Info describes National Bank of Kazakhstan as a rate source.
+func (n *NBRK) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "NBRK",
+		Name:       "Национальный Банк РК",
+		Homepage:   "https://nationalbank.kz",
+		Logo:       "https://nationalbank.kz/favicon.ico",
+		Kind:       entity.SourceKindCentralBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelOfficial},
+		Schedule:   centralBankSchedule,
+	}
+}
+
+// nbrkDateLayout is the pubDate format of the feed, e.g. "01.11.24".
+const nbrkDateLayout = "02.01.06"
+
 type rss struct {
 	XMLName xml.Name `xml:"rss"`
 	Channel struct {
@@ -54,9 +73,13 @@ func (n *NBRK) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
 	// Parse the response body.
 	var rssData rss
-	if err = xml.NewDecoder(resp.Body).Decode(&rssData); err != nil {
+	if err = decodeXML(xml.NewDecoder(resp.Body), &rssData); err != nil {
 		return nil, err
 	}
 
@@ -66,11 +89,17 @@ func (n *NBRK) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 		if !canPerformCurrency(item.Title) {
 			continue
 		}
+		// Официальный курс один на обе стороны; без числа валюту пропускаем
+		value := strings.TrimSpace(item.Description)
+		if !isPositive(value) {
+			continue
+		}
 		rate := &entity.ExchangeRate{
 			Source:       "NBRK",
 			CurrencyCode: item.Title,
-			Buy:          item.Description,
-			Sell:         item.Description,
+			Buy:          value,
+			Sell:         value,
+			CreatedAt:    effectiveAt(nbrkDateLayout, strings.TrimSpace(item.PubDate)),
 		}
 
 		rates = append(rates, rate)
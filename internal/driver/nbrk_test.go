@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Mi7teR/exr/internal/entity"
 	internalErrors "github.com/Mi7teR/exr/internal/errors"
@@ -67,17 +68,17 @@ func TestNBRK_FetchRates(t *testing.T) {
 					<channel>
 						<item>
 							<title>USD</title>
-							<pubDate>Mon, 01 Nov 2024 12:00:00 +0000</pubDate>
+							<pubDate>01.11.24</pubDate>
 							<description>456.00</description>
 						</item>
 						<item>
 							<title>EUR</title>
-							<pubDate>Mon, 01 Nov 2024 12:00:00 +0000</pubDate>
+							<pubDate>01.11.24</pubDate>
 							<description>512.00</description>
 						</item>
 						<item>
 							<title>RUB</title>
-							<pubDate>Mon, 01 Nov 2024 12:00:00 +0000</pubDate>
+							<pubDate>01.11.24</pubDate>
 							<description>6.00</description>
 						</item>
 					</channel>
@@ -90,22 +91,45 @@ func TestNBRK_FetchRates(t *testing.T) {
 					CurrencyCode: "USD",
 					Buy:          "456.00",
 					Sell:         "456.00",
+					CreatedAt:    time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
 				},
 				{
 					Source:       "NBRK",
 					CurrencyCode: "EUR",
 					Buy:          "512.00",
 					Sell:         "512.00",
+					CreatedAt:    time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
 				},
 				{
 					Source:       "NBRK",
 					CurrencyCode: "RUB",
 					Buy:          "6.00",
 					Sell:         "6.00",
+					CreatedAt:    time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
 				},
 			},
 			expectedError: nil,
 		},
+		{
+			name: "Currency without a number is skipped",
+			responseBody: `
+				<rss>
+					<channel>
+						<item><title>USD</title><pubDate>01.11.24</pubDate><description>abc</description></item>
+						<item><title>EUR</title><pubDate>01.11.24</pubDate><description> 512.00 </description></item>
+						<item><title>RUB</title><pubDate>01.11.24</pubDate><description>0</description></item>
+					</channel>
+				</rss>
+			`,
+			responseStatus: http.StatusOK,
+			expectedRates: []*entity.ExchangeRate{{
+				Source:       "NBRK",
+				CurrencyCode: "EUR",
+				Buy:          "512.00",
+				Sell:         "512.00",
+				CreatedAt:    time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
 		{
 			name:           "Empty response",
 			responseBody:   "<rss><channel></channel></rss>",
@@ -2,8 +2,6 @@ package driver
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -32,7 +30,7 @@ type rbkResponse struct {
 			Buy  []rbkItem `json:"buy"`
 			Sell []rbkItem `json:"sell"`
 			Date string    `json:"date"`
-		} `json:"branch"`
+		} `json:"branch,omitempty"`
 	} `json:"data"`
 }
 
@@ -48,6 +46,20 @@ func NewRBK(addr string, httpClient HTTPClient) *RBK {
 	return &RBK{addr: addr, httpClient: httpClient}
 }
 
+// Info describes Bank RBK as a rate source.
+func (r *RBK) Info() entity.SourceInfo {
+	return entity.SourceInfo{
+		ID:         "RBK",
+		Name:       "Bank RBK",
+		Homepage:   "https://bankrbk.kz",
+		Logo:       "https://bankrbk.kz/favicon.ico",
+		Kind:       entity.SourceKindBank,
+		Currencies: retailCurrencies(),
+		Channels:   []string{entity.ChannelNonCash},
+		Schedule:   bankSchedule,
+	}
+}
+
 const (
 	dstKZT = "KZT"
 )
@@ -70,7 +82,8 @@ func (r *RBK) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	}
 
 	var rr rbkResponse
-	if err = json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+	drift, err := decodeJSON(resp.Body, &rr)
+	if err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 	if rr.Error != 0 {
@@ -105,7 +118,7 @@ func (r *RBK) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 	for cur := range supported {
 		buy, bok := buyMap[cur]
 		sell, sok := sellMap[cur]
-		if !bok || !sok {
+		if !bok || !sok || !isNumber(buy) || !isNumber(sell) {
 			continue // skip if one side missing
 		}
 		rates = append(rates, &entity.ExchangeRate{
@@ -117,7 +130,7 @@ func (r *RBK) FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error) {
 		})
 	}
 	if len(rates) == 0 {
-		return nil, errors.New("no supported currency rates found")
+		return nil, errNoRates(drift)
 	}
-	return rates, nil
+	return rates, drift.err()
 }
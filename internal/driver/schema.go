@@ -0,0 +1,239 @@
+package driver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// SchemaError reports that an upstream payload drifted from the shape the driver expects.
+// It unwraps to errors.ErrSchemaChanged. When the driver still managed to extract rates it
+// returns them together with the error, so the change is noticed before it breaks parsing.
+type SchemaError struct {
+	Missing    []string // обязательные поля, которых нет в ответе
+	Mismatched []string // поля другого типа, например объект вместо массива
+	Unknown    []string // новые поля, о которых драйвер не знает
+}
+
+func (e *SchemaError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Mismatched) > 0 {
+		parts = append(parts, "mismatched "+strings.Join(e.Mismatched, ", "))
+	}
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown "+strings.Join(e.Unknown, ", "))
+	}
+	return fmt.Sprintf("%s: %s", internalErrors.ErrSchemaChanged, strings.Join(parts, "; "))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return internalErrors.ErrSchemaChanged
+}
+
+// Breaking reports whether fields the driver relies on are gone or changed type.
+func (e *SchemaError) Breaking() bool {
+	return len(e.Missing) > 0 || len(e.Mismatched) > 0
+}
+
+// err returns the tolerated drift as error, nil if the payload matched the expected shape.
+func (e *SchemaError) err() error {
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
+// errNoRates is returned when a payload carried none of the supported currencies.
+// If the shape drifted, the drift is the likelier cause and is returned instead.
+func errNoRates(drift *SchemaError) error {
+	if drift != nil {
+		return drift
+	}
+	return errors.New("no supported currency rates found")
+}
+
+// shaped is implemented by types with custom UnmarshalJSON that accept several shapes;
+// it returns the type the raw value is decoded as, nil if the value is not acceptable.
+type shaped interface {
+	shapeOf(raw any) reflect.Type
+}
+
+var (
+	shapedType      = reflect.TypeOf((*shaped)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// decodeJSON decodes the payload into v and checks it against the shape of v. The drift
+// (nil when the shape matches) is returned separately, so drivers can still report API errors
+// carried by the payload; when the payload does not decode at all the drift is the error.
+// Fields tagged omitempty are optional.
+func decodeJSON(r io.Reader, v any) (*SchemaError, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if err = json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	drift := &SchemaError{}
+	drift.check("", raw, reflect.TypeOf(v).Elem())
+	drift.sort()
+	if err = json.Unmarshal(body, v); err != nil {
+		if len(drift.Mismatched) > 0 {
+			return nil, drift
+		}
+		return nil, err
+	}
+	if len(drift.Missing)+len(drift.Mismatched)+len(drift.Unknown) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
+
+// decodeXML decodes an XML feed; another root element means the feed was replaced.
+func decodeXML(dec *xml.Decoder, v any) error {
+	err := dec.Decode(v)
+	var ue xml.UnmarshalError
+	if errors.As(err, &ue) {
+		return &SchemaError{Mismatched: []string{string(ue)}}
+	}
+	return err
+}
+
+// check walks a generically decoded JSON value along the expected type.
+func (e *SchemaError) check(path string, raw any, t reflect.Type) {
+	if raw == nil {
+		return // null декодируется в нулевое значение любого типа
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(shapedType) {
+		if t = reflect.Zero(t).Interface().(shaped).shapeOf(raw); t == nil {
+			e.add(&e.Mismatched, fieldPath(path))
+			return
+		}
+	} else if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return // json.RawMessage и прочие типы со своим разбором принимают что угодно
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want object)")
+			return
+		}
+		known := make(map[string]struct{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, optional, ok := jsonField(t.Field(i))
+			if !ok {
+				continue
+			}
+			known[name] = struct{}{}
+			v, present := obj[name]
+			if !present {
+				if !optional {
+					e.add(&e.Missing, joinPath(path, name))
+				}
+				continue
+			}
+			e.check(joinPath(path, name), v, t.Field(i).Type)
+		}
+		for name := range obj {
+			if _, ok := known[name]; !ok {
+				e.add(&e.Unknown, joinPath(path, name))
+			}
+		}
+	case reflect.Map:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want object)")
+			return
+		}
+		for _, v := range obj {
+			e.check(joinPath(path, "*"), v, t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := raw.([]any)
+		if !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want array)")
+			return
+		}
+		for _, v := range list {
+			e.check(path+"[]", v, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want string)")
+		}
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want bool)")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := raw.(float64); !ok {
+			e.add(&e.Mismatched, fieldPath(path)+" (want number)")
+		}
+	}
+}
+
+func (e *SchemaError) add(list *[]string, path string) {
+	for _, p := range *list {
+		if p == path {
+			return // элементы массива дают одинаковые пути
+		}
+	}
+	*list = append(*list, path)
+}
+
+func (e *SchemaError) sort() {
+	sort.Strings(e.Missing)
+	sort.Strings(e.Mismatched)
+	sort.Strings(e.Unknown)
+}
+
+// jsonField returns the JSON name of an exported struct field and whether it is optional.
+func jsonField(f reflect.StructField) (name string, optional, ok bool) {
+	if !f.IsExported() {
+		return "", false, false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty"), true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// fieldPath names the document root for mismatches found at the top level.
+func fieldPath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
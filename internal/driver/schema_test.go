@@ -0,0 +1,122 @@
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/driver"
+	"github.com/Mi7teR/exr/internal/driver/drivertest"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDrift(t *testing.T) {
+	tests := []struct {
+		name       string
+		newDriver  func(addr string, cli driver.HTTPClient) drivertest.Driver
+		body       string
+		rates      int // курсы, которые драйвер всё ещё смог разобрать
+		missing    []string
+		mismatched []string
+		unknown    []string
+	}{
+		{
+			name:       "halyk history became a string",
+			newDriver:  func(addr string, cli driver.HTTPClient) drivertest.Driver { return driver.NewHalyk(addr, cli) },
+			body:       `{"result":true,"data":{"currencyHistory":"2024-11-01"}}`,
+			mismatched: []string{"data.currencyHistory"},
+		},
+		{
+			name:      "halyk pairs became strings",
+			newDriver: func(addr string, cli driver.HTTPClient) drivertest.Driver { return driver.NewHalyk(addr, cli) },
+			body: `{"result":true,"data":{"currencyHistory":[
+				{"date":"2024-11-01","privatePersons":{"USD/KZT":{"buy":"537.6","sell":"544.6"}}}]}}`,
+			mismatched: []string{"data.currencyHistory[].privatePersons.*.buy (want number)",
+				"data.currencyHistory[].privatePersons.*.sell (want number)"},
+		},
+		{
+			name:      "bcc renamed list",
+			newDriver: func(addr string, cli driver.HTTPClient) drivertest.Driver { return driver.NewBCC(addr, cli) },
+			body:      `{"items":[{"code":"USD","kind":"cash","purchase":"537","sale":"542"}]}`,
+			missing:   []string{"rates"},
+			unknown:   []string{"items"},
+		},
+		{
+			name:      "bcc renamed field",
+			newDriver: func(addr string, cli driver.HTTPClient) drivertest.Driver { return driver.NewBCC(addr, cli) },
+			body:      `{"rates":[{"code":"USD","type":"cash","purchase":"537","sale":"542"}]}`,
+			missing:   []string{"rates[].kind"},
+			unknown:   []string{"rates[].type"},
+		},
+		{
+			name:      "forte added field",
+			newDriver: func(addr string, cli driver.HTTPClient) drivertest.Driver { return driver.NewForte(addr, cli) },
+			body:      `{"status":"ok","data":{"cash":{"USD":{"buy":"537","sell":"541","updated":"12:00"}}}}`,
+			rates:     1,
+			unknown:   []string{"data.cash.*.updated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			rates, err := tt.newDriver(server.URL, server.Client()).FetchRates(context.Background())
+			require.ErrorIs(t, err, internalErrors.ErrSchemaChanged)
+			assert.Len(t, rates, tt.rates)
+
+			var drift *driver.SchemaError
+			require.True(t, errors.As(err, &drift))
+			assert.Equal(t, tt.missing, drift.Missing)
+			assert.Equal(t, tt.mismatched, drift.Mismatched)
+			assert.Equal(t, tt.unknown, drift.Unknown)
+			assert.Equal(t, tt.missing != nil || tt.mismatched != nil, drift.Breaking())
+		})
+	}
+}
+
+func TestSchemaDrift_Feeds(t *testing.T) {
+	t.Run("cbr feed replaced", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><html><body>Сервис недоступен</body></html>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewCBR(server.URL, server.Client()).FetchRates(context.Background())
+		require.ErrorIs(t, err, internalErrors.ErrSchemaChanged)
+	})
+
+	t.Run("ecb without rates", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<Envelope><Cube><Cube time="2024-11-01"></Cube></Cube></Envelope>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewECB(server.URL, server.Client()).FetchRates(context.Background())
+		require.ErrorIs(t, err, internalErrors.ErrSchemaChanged)
+	})
+
+	t.Run("page layout changed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><div class="rates-v2">USD 537 542</div></body></html>`))
+		}))
+		defer server.Close()
+		_, err := driver.NewEurasian(server.URL, server.Client()).FetchRates(context.Background())
+		require.ErrorIs(t, err, internalErrors.ErrSchemaChanged)
+	})
+
+	t.Run("no supported currencies is not drift", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"rates":[{"code":"GBP","kind":"cash","purchase":"690","sale":"712"}]}`))
+		}))
+		defer server.Close()
+		_, err := driver.NewBCC(server.URL, server.Client()).FetchRates(context.Background())
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, internalErrors.ErrSchemaChanged)
+	})
+}
@@ -0,0 +1,51 @@
+package driver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// SourceConfig is optional source metadata of a config-driven driver, embedded into JSONConfig and HTMLConfig.
+type SourceConfig struct {
+	Name     string `json:"name,omitempty"`
+	Homepage string `json:"homepage,omitempty"`
+	Logo     string `json:"logo,omitempty"`
+	Kind     string `json:"kind,omitempty"`     // bank, central_bank, exchange_office; bank by default
+	Channel  string `json:"channel,omitempty"`  // cash by default
+	Schedule string `json:"schedule,omitempty"` // Go duration, "30m" by default
+}
+
+func (c SourceConfig) validate() error {
+	if c.Schedule == "" {
+		return nil
+	}
+	if _, err := time.ParseDuration(c.Schedule); err != nil {
+		return fmt.Errorf("schedule: %w", err)
+	}
+	return nil
+}
+
+func (c SourceConfig) info(id string, currencies []string) entity.SourceInfo {
+	info := entity.SourceInfo{
+		ID:         id,
+		Name:       c.Name,
+		Homepage:   c.Homepage,
+		Logo:       c.Logo,
+		Kind:       entity.SourceKind(c.Kind),
+		Currencies: currencies,
+		Channels:   []string{c.Channel},
+		Schedule:   bankSchedule,
+	}
+	if info.Kind == "" {
+		info.Kind = entity.SourceKindBank
+	}
+	if c.Channel == "" {
+		info.Channels = []string{entity.ChannelCash}
+	}
+	if d, err := time.ParseDuration(c.Schedule); err == nil {
+		info.Schedule = d
+	}
+	return info
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// Alert rule kinds.
+const (
+	AlertPrice  = "price"  // сторона курса выше или ниже порога
+	AlertChange = "change" // изменение курса больше порога, в процентах
+	AlertSpread = "spread" // спред выше или ниже порога, в процентах
+)
+
+// Alert comparison operators for price and spread rules.
+const (
+	AlertBelow = "below"
+	AlertAbove = "above"
+)
+
+// AlertRule is a user-defined condition on rates, evaluated after every fetch. Empty Source,
+// Channel and Side match any. A rule fires once when its condition starts to hold and stays
+// firing until it clears; Cooldown is the minimum time between two firings.
+type AlertRule struct {
+	ID           int64
+	Name         string
+	Kind         string
+	CurrencyCode string
+	Source       string
+	Channel      string
+	Side         string  // buy или sell; для change пусто — любая сторона
+	Op           string  // below или above; для change не используется
+	Threshold    float64 // курс в тенге для price, проценты для change и spread
+	Cooldown     time.Duration
+	Firing       bool
+	LastFiredAt  time.Time
+	CreatedAt    time.Time
+}
+
+// AlertEvent is a firing of an alert rule, caused by the rate of one series.
+type AlertEvent struct {
+	ID           int64
+	RuleID       int64
+	Source       string
+	Channel      string
+	Branch       string
+	CurrencyCode string
+	Value        float64 // курс, изменение или спред, сработавшие по правилу
+	Message      string
+	RateAt       time.Time
+	FiredAt      time.Time
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Rate sides.
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Anomaly is an unusual move of one side of a rate series compared to its recent history.
+type Anomaly struct {
+	ID           int64
+	Source       string
+	Channel      string
+	Branch       string
+	BranchName   string
+	CurrencyCode string
+	Side         string  // buy или sell
+	Value        float64 // новое значение
+	Baseline     float64 // медиана (mad) или среднее (zscore) окна истории
+	Score        float64 // во сколько «типичных отклонений» новое значение ушло от базы, со знаком
+	Method       string  // mad или zscore
+	RateAt       time.Time
+	DetectedAt   time.Time
+}
+
+// DeviationPct returns the move from the baseline in percent.
+func (a *Anomaly) DeviationPct() float64 {
+	if a.Baseline == 0 {
+		return 0
+	}
+	return (a.Value - a.Baseline) / a.Baseline * 100
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// Offer is a place to exchange a currency at a rate.
+type Offer struct {
+	Source     string
+	Branch     string // обменник внутри агрегатора, пусто для банка
+	BranchName string
+	City       string
+	Address    string
+	Rate       float64
+	ChangedAt  time.Time // когда курс изменился в последний раз
+	CheckedAt  time.Time // когда источник последний раз опрошен, ноль если после запуска ещё не опрашивался
+}
+
+// BestRate lists the best places to exchange a currency in one channel, best offer first.
+type BestRate struct {
+	CurrencyCode string
+	Channel      string
+	Buy          []Offer // где купить валюту: по возрастанию курса продажи
+	Sell         []Offer // где продать валюту: по убыванию курса покупки
+}
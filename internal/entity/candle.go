@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// CandleInterval is the length of a candle period.
+type CandleInterval string
+
+// Candle intervals; weeks start on Monday.
+const (
+	CandleHour  CandleInterval = "hour"
+	CandleDay   CandleInterval = "day"
+	CandleWeek  CandleInterval = "week"
+	CandleMonth CandleInterval = "month"
+)
+
+// Valid reports whether the interval is known.
+func (i CandleInterval) Valid() bool {
+	switch i {
+	case CandleHour, CandleDay, CandleWeek, CandleMonth:
+		return true
+	}
+	return false
+}
+
+// Start returns the start of the period containing t in loc.
+func (i CandleInterval) Start(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	switch i {
+	case CandleHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	case CandleWeek:
+		// понедельник — первый день недели
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case CandleMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// Next returns the start of the period after the one starting at start.
+func (i CandleInterval) Next(start time.Time) time.Time {
+	switch i {
+	case CandleHour:
+		return start.Add(time.Hour)
+	case CandleWeek:
+		return start.AddDate(0, 0, 7)
+	case CandleMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Candle is open/high/low/close of one side of a rate series over a period.
+type Candle struct {
+	Source       string
+	Channel      string
+	CurrencyCode string
+	Side         string // buy или sell
+	Interval     CandleInterval
+	Start        time.Time
+	Open         float64
+	High         float64
+	Low          float64
+	Close        float64
+	Changes      int // изменений курса за период; 0 — значение перенесено из прошлого периода
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// ConversionOption is the result of exchanging an amount at one source.
+type ConversionOption struct {
+	Source     string
+	Branch     string
+	BranchName string
+	Channel    string
+	City       string
+	Rate       float64 // единиц To за единицу From
+	Result     float64
+	Via        string    // валюта промежуточного шага (KZT), пусто для прямого обмена
+	ChangedAt  time.Time // самое старое изменение из использованных курсов
+}
+
+// Conversion is an amount converted at every source, best option first.
+type Conversion struct {
+	From     string
+	To       string
+	Amount   float64
+	Official *ConversionOption // по курсу НБРК, nil если курса нет
+	Options  []ConversionOption
+}
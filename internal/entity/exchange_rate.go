@@ -2,13 +2,56 @@ package entity
 
 import "time"
 
-// ExchangeRate represents an exchange rate.
+// QuoteKZT is the quote currency of local banks; rates without QuoteCurrency are quoted in it.
+const QuoteKZT = "KZT"
+
+// ExchangeRate represents an exchange rate: price of one unit of CurrencyCode in QuoteCurrency.
 type ExchangeRate struct {
 	CurrencyCode   string
+	QuoteCurrency  string // пусто означает KZT; иностранные ЦБ котируют в своей валюте (RUB, EUR, KGS)
 	Buy            string
 	Sell           string
 	Source         string
+	Channel        string // cash, non_cash, card, official; пусто — не указан
+	Branch         string // офис/обменник внутри источника, пусто для курса по всему банку
+	BranchName     string // название офиса для показа, пусто — показываем Branch
+	City           string // город офиса, пусто если курс действует по всей стране
+	Address        string
 	CreatedAt      time.Time
 	BuyChangePrev  float64 // текущее Buy - предыдущее Buy (0 если предыдущего нет)
 	SellChangePrev float64 // текущее Sell - предыдущее Sell (0 если предыдущего нет)
 }
+
+// SeriesKey identifies a time series of rates: one currency quoted by one source (office, channel).
+type SeriesKey struct {
+	Source       string
+	Branch       string
+	Channel      string
+	CurrencyCode string
+}
+
+// Key returns the series the rate belongs to.
+func (r *ExchangeRate) Key() SeriesKey {
+	return SeriesKey{Source: r.Source, Branch: r.Branch, Channel: r.Channel, CurrencyCode: r.CurrencyCode}
+}
+
+// BranchLabel returns the name of the office to show, Branch if it has none.
+func (r *ExchangeRate) BranchLabel() string {
+	return BranchLabel(r.Branch, r.BranchName)
+}
+
+// BranchLabel returns the office name, falling back to its id.
+func BranchLabel(branch, name string) string {
+	if name != "" {
+		return name
+	}
+	return branch
+}
+
+// Quote returns the quote currency, defaulting to KZT.
+func (r *ExchangeRate) Quote() string {
+	if r.QuoteCurrency == "" {
+		return QuoteKZT
+	}
+	return r.QuoteCurrency
+}
@@ -0,0 +1,12 @@
+package entity
+
+// ImpliedRate is a KZT price of a currency derived from a foreign reference source
+// (e.g. USD/RUB from CBR times RUB/KZT) and compared against the NBRK official rate.
+type ImpliedRate struct {
+	Reference    string // источник кросс-курса: CBR, ECB, NBKR
+	Via          string // валюта котировки источника, через которую считаем кросс
+	CurrencyCode string
+	Rate         float64 // KZT за единицу CurrencyCode
+	Official     float64 // официальный курс НБРК, 0 если неизвестен
+	DeviationPct float64 // (Rate - Official) / Official * 100
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Validation rules a fetched rate can violate.
+const (
+	RuleNonPositive       = "non_positive"       // пустое, нулевое или отрицательное значение
+	RuleBuyAboveSell      = "buy_above_sell"     // покупка дороже продажи
+	RuleSpread            = "spread"             // спред шире порога
+	RuleJump              = "jump"               // скачок от последнего сохранённого курса
+	RuleOfficialDeviation = "official_deviation" // отклонение от официального курса НБРК
+)
+
+// QuarantinedRate is a fetched rate that failed validation and is kept for review
+// instead of being stored as a regular rate. Repeated identical values bump Hits.
+type QuarantinedRate struct {
+	ID        int64
+	Rate      *ExchangeRate
+	Rule      string
+	Reason    string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Hits      int
+}
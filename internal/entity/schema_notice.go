@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// SchemaNotice tells admins that a source's upstream payload drifted from the expected shape.
+type SchemaNotice struct {
+	Source   string
+	Detail   string // текст ошибки драйвера: какие поля пропали, поменяли тип или появились
+	Breaking bool   // драйвер не смог извлечь ни одного курса
+	Count    int    // сколько опросов подряд с отклонением
+	Since    time.Time
+	LastSeen time.Time
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// SourceKind tells what kind of institution publishes the rates.
+type SourceKind string
+
+const (
+	SourceKindBank           SourceKind = "bank"
+	SourceKindCentralBank    SourceKind = "central_bank"
+	SourceKindExchangeOffice SourceKind = "exchange_office"
+)
+
+// Channels a rate can be quoted for.
+const (
+	ChannelCash     = "cash"
+	ChannelNonCash  = "non_cash"
+	ChannelCard     = "card"
+	ChannelOfficial = "official" // официальный курс ЦБ, по нему не торгуют
+)
+
+// SourceInfo describes a rate source; every driver declares one about itself.
+type SourceInfo struct {
+	ID         string // совпадает с Source у курсов драйвера
+	Name       string
+	Homepage   string
+	Logo       string
+	Kind       SourceKind
+	Currencies []string
+	Channels   []string
+	Schedule   time.Duration // как часто опрашивать источник, 0 — при каждом обновлении
+}
+
+// DisplayName returns Name falling back to ID.
+func (s SourceInfo) DisplayName() string {
+	if s.Name == "" {
+		return s.ID
+	}
+	return s.Name
+}
+
+// DefaultChannel returns the channel of a single-channel source, empty otherwise.
+func (s SourceInfo) DefaultChannel() string {
+	if len(s.Channels) == 1 {
+		return s.Channels[0]
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+// Spread is the gap between sell and buy of a rate: what a round trip costs a customer.
+type Spread struct {
+	Source       string
+	Branch       string
+	BranchName   string
+	City         string
+	Channel      string
+	CurrencyCode string
+	Buy          float64
+	Sell         float64
+	Abs          float64 // Sell - Buy
+	Pct          float64 // Abs в процентах от середины (Buy+Sell)/2
+	At           time.Time
+}
+
+// NewSpread computes the spread of a rate; false if a side is missing.
+func NewSpread(r *ExchangeRate) (Spread, bool) {
+	buy, errBuy := strconv.ParseFloat(r.Buy, 64)
+	sell, errSell := strconv.ParseFloat(r.Sell, 64)
+	if errBuy != nil || errSell != nil || buy <= 0 || sell <= 0 {
+		return Spread{}, false
+	}
+	abs := sell - buy
+	return Spread{
+		Source:       r.Source,
+		Branch:       r.Branch,
+		BranchName:   r.BranchName,
+		City:         r.City,
+		Channel:      r.Channel,
+		CurrencyCode: r.CurrencyCode,
+		Buy:          buy,
+		Sell:         sell,
+		Abs:          abs,
+		Pct:          abs / ((buy + sell) / 2) * 100,
+		At:           r.CreatedAt,
+	}, true
+}
+
+// SpreadDay is the spread of a series over the rate changes of a day, in percent of the mid rate.
+type SpreadDay struct {
+	Source       string
+	Channel      string
+	CurrencyCode string
+	Day          time.Time
+	AvgPct       float64
+	MinPct       float64
+	MaxPct       float64
+	Changes      int
+}
+
+// SpreadWidening is how much wider than usual a series quoted on a day.
+type SpreadWidening struct {
+	Source      string
+	Channel     string
+	AvgPct      float64 // средний спред в этот день
+	BaselinePct float64 // средний спред в спокойные дни периода
+}
+
+// VolatileDay is a day the official rate moved noticeably, with the series that widened spreads.
+type VolatileDay struct {
+	Day             time.Time
+	OfficialMovePct float64 // дневной размах официального курса в процентах
+	Widened         []SpreadWidening
+}
+
+// SpreadHistory is the daily spread of banks over a range and the days the market was volatile.
+type SpreadHistory struct {
+	Days         []*SpreadDay
+	VolatileDays []*VolatileDay
+}
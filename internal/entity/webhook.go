@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"   // ждёт первой или повторной попытки
+	DeliveryDelivered = "delivered" // получатель ответил 2xx
+	DeliveryFailed    = "failed"    // попытки исчерпаны
+)
+
+// Webhook is a subscription of an external receiver to stored rates. Empty CurrencyCode,
+// Source and Channel match any.
+type Webhook struct {
+	ID           int64
+	URL          string
+	Secret       string // ключ HMAC-подписи запросов
+	CurrencyCode string
+	Source       string
+	Channel      string
+	CreatedAt    time.Time
+}
+
+// Matches reports whether the rate passes the webhook filters.
+func (w *Webhook) Matches(r *ExchangeRate) bool {
+	return (w.CurrencyCode == "" || strings.EqualFold(w.CurrencyCode, r.CurrencyCode)) &&
+		(w.Source == "" || w.Source == r.Source) &&
+		(w.Channel == "" || strings.EqualFold(w.Channel, r.Channel))
+}
+
+// WebhookDelivery is a payload queued for a webhook together with the log of its attempts.
+type WebhookDelivery struct {
+	ID            int64
+	WebhookID     int64
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastStatus    int    // HTTP-статус последней попытки, 0 если ответа не было
+	LastError     string // ошибка последней попытки
+	CreatedAt     time.Time
+	DeliveredAt   time.Time
+}
@@ -9,4 +9,6 @@ var (
 	ErrInvalidArgument = errors.New("invalid argument")
 	// ErrInternal is returned when an internal error occurs.
 	ErrInternal = errors.New("internal error")
+	// ErrSchemaChanged is returned when an upstream payload no longer matches the expected shape.
+	ErrSchemaChanged = errors.New("upstream schema changed")
 )
@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+)
+
+// Config describes how requests of one driver leave the host: through a proxy, with which
+// User-Agent, extra headers and TLS settings. Zero value means direct connection with defaults.
+type Config struct {
+	// Proxy is http://, https:// or socks5:// URL of an egress node, credentials go into userinfo.
+	Proxy     string            `json:"proxy,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	TLS       TLSConfig         `json:"tls"`
+	Timeout   string            `json:"timeout,omitempty"` // Go duration, DefaultTimeout by default
+}
+
+// TLSConfig holds TLS settings of a driver's connections.
+type TLSConfig struct {
+	CAFile             string `json:"ca_file,omitempty"`     // PEM с дополнительными корневыми сертификатами
+	ServerName         string `json:"server_name,omitempty"` // SNI, если ходим по IP или через TLS-терминатор
+	MinVersion         string `json:"min_version,omitempty"` // "1.2" или "1.3"
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// LoadConfigs reads per-driver egress settings keyed by source id.
+func LoadConfigs(r io.Reader) (map[string]Config, error) {
+	var cfgs map[string]Config
+	if err := json.NewDecoder(r).Decode(&cfgs); err != nil {
+		return nil, fmt.Errorf("decode egress config: %w", err)
+	}
+	return cfgs, nil
+}
+
+// NewConfiguredHTTPClient creates a logging client that routes requests as the config says.
+func NewConfiguredHTTPClient(l logger.Logger, cfg Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("proxy: unsupported scheme %q", u.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	tlsCfg, err := cfg.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+	transport.TLSClientConfig = tlsCfg
+
+	timeout := DefaultTimeout
+	if cfg.Timeout != "" {
+		if timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return nil, fmt.Errorf("timeout: %w", err)
+		}
+	}
+
+	var next http.RoundTripper = transport
+	if cfg.UserAgent != "" || len(cfg.Headers) > 0 {
+		next = &headerRoundTripper{userAgent: cfg.UserAgent, headers: cfg.Headers, transport: transport}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &LogRoundTripper{l: l, transport: next},
+	}, nil
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, // только по явной настройке драйвера
+	}
+	if c.MinVersion != "" {
+		v, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported min_version %q", c.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// headerRoundTripper sets configured User-Agent and headers, overriding what the driver sent.
+type headerRoundTripper struct {
+	userAgent string
+	headers   map[string]string
+	transport http.RoundTripper
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context()) // RoundTripper не должен менять чужой запрос
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+	if h.userAgent != "" {
+		req.Header.Set("User-Agent", h.userAgent)
+	}
+	return h.transport.RoundTrip(req)
+}
+
+// Pool hands out HTTP clients per driver: drivers with egress settings get their own client,
+// the rest share the default one.
+type Pool struct {
+	def     *http.Client
+	clients map[string]*http.Client
+}
+
+// NewPool builds clients for every configured driver.
+func NewPool(l logger.Logger, cfgs map[string]Config) (*Pool, error) {
+	p := &Pool{def: NewNetHTTPClient(l), clients: make(map[string]*http.Client, len(cfgs))}
+	for id, cfg := range cfgs {
+		cli, err := NewConfiguredHTTPClient(l.With("driver", id), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("egress %s: %w", id, err)
+		}
+		p.clients[id] = cli
+	}
+	return p, nil
+}
+
+// For returns the client of the driver, the default one if the driver has no egress settings.
+func (p *Pool) For(id string) *http.Client {
+	if cli, ok := p.clients[id]; ok {
+		return cli
+	}
+	return p.def
+}
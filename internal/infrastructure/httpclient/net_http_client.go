@@ -20,6 +20,11 @@ func NewNetHTTPClient(l logger.Logger) *http.Client {
 	}
 }
 
+// NewLogRoundTripper logs requests made through transport.
+func NewLogRoundTripper(l logger.Logger, transport http.RoundTripper) *LogRoundTripper {
+	return &LogRoundTripper{l: l, transport: transport}
+}
+
 type LogRoundTripper struct {
 	l         logger.Logger
 	transport http.RoundTripper
@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// alertSchema keeps user alert rules with their firing state and the events they fired;
+// a rule fires on the rate of a series once.
+const alertSchema = `CREATE TABLE IF NOT EXISTS alert_rules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL DEFAULT '',
+	kind TEXT NOT NULL,
+	currency_code TEXT NOT NULL,
+	source TEXT NOT NULL DEFAULT '',
+	channel TEXT NOT NULL DEFAULT '',
+	side TEXT NOT NULL DEFAULT '',
+	op TEXT NOT NULL DEFAULT '',
+	threshold REAL NOT NULL,
+	cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+	firing INTEGER NOT NULL DEFAULT 0,
+	last_fired_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS alert_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	rule_id INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	channel TEXT NOT NULL DEFAULT '',
+	branch TEXT NOT NULL DEFAULT '',
+	currency_code TEXT NOT NULL,
+	value REAL NOT NULL,
+	message TEXT NOT NULL,
+	rate_at TIMESTAMP NOT NULL,
+	fired_at TIMESTAMP NOT NULL,
+	UNIQUE(rule_id, source, channel, branch, currency_code, rate_at)
+);
+CREATE INDEX IF NOT EXISTS idx_alert_events_fired_at ON alert_events(fired_at);`
+
+const alertRuleColumns = `id, name, kind, currency_code, source, channel, side, op, threshold, cooldown_seconds,
+	firing, last_fired_at, created_at`
+
+// AddAlertRule stores a new alert rule and sets its ID.
+func (r *SQLiteExchangeRateRepository) AddAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now().UTC()
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO alert_rules(
+			name, kind, currency_code, source, channel, side, op, threshold, cooldown_seconds, created_at
+		) VALUES(?,?,?,?,?,?,?,?,?,?)`,
+		rule.Name, rule.Kind, rule.CurrencyCode, rule.Source, rule.Channel, rule.Side, rule.Op, rule.Threshold,
+		int64(rule.Cooldown/time.Second), rule.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rule.ID, err = res.LastInsertId()
+	return err
+}
+
+// GetAlertRules returns all alert rules in creation order.
+func (r *SQLiteExchangeRateRepository) GetAlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.AlertRule
+	for rows.Next() {
+		var (
+			rule     entity.AlertRule
+			cooldown int64
+			fired    sql.NullTime
+		)
+		if err = rows.Scan(
+			&rule.ID, &rule.Name, &rule.Kind, &rule.CurrencyCode, &rule.Source, &rule.Channel, &rule.Side,
+			&rule.Op, &rule.Threshold, &cooldown, &rule.Firing, &fired, &rule.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rule.Cooldown = time.Duration(cooldown) * time.Second
+		rule.LastFiredAt = fired.Time
+		out = append(out, &rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// DeleteAlertRule removes the rule together with its events.
+func (r *SQLiteExchangeRateRepository) DeleteAlertRule(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM alert_events WHERE rule_id = ?`, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return internalErrors.ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// UpdateAlertRuleState saves whether the rule is firing and when it last fired.
+func (r *SQLiteExchangeRateRepository) UpdateAlertRuleState(
+	ctx context.Context,
+	id int64,
+	firing bool,
+	lastFiredAt time.Time,
+) error {
+	fired := sql.NullTime{Time: lastFiredAt, Valid: !lastFiredAt.IsZero()}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE alert_rules SET firing = ?, last_fired_at = ? WHERE id = ?`, firing, fired, id)
+	return err
+}
+
+// AddAlertEvent stores an alert event and sets its ID; false if the rule already fired on this rate.
+func (r *SQLiteExchangeRateRepository) AddAlertEvent(ctx context.Context, e *entity.AlertEvent) (bool, error) {
+	if e.FiredAt.IsZero() {
+		e.FiredAt = time.Now().UTC()
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO alert_events(
+			rule_id, source, channel, branch, currency_code, value, message, rate_at, fired_at
+		) VALUES(?,?,?,?,?,?,?,?,?)`,
+		e.RuleID, e.Source, e.Channel, e.Branch, e.CurrencyCode, e.Value, e.Message, e.RateAt, e.FiredAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	e.ID, err = res.LastInsertId()
+	return err == nil, err
+}
+
+// GetAlertEvents returns alert events fired in range, newest first.
+func (r *SQLiteExchangeRateRepository) GetAlertEvents(
+	ctx context.Context,
+	startDate, endDate time.Time,
+) ([]*entity.AlertEvent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, rule_id, source, channel, branch, currency_code, value, message, rate_at, fired_at
+		FROM alert_events
+		WHERE fired_at BETWEEN ? AND ?
+		ORDER BY fired_at DESC, id DESC`,
+		normalizeStart(startDate), normalizeEnd(endDate),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.AlertEvent
+	for rows.Next() {
+		e := &entity.AlertEvent{}
+		if err = rows.Scan(
+			&e.ID, &e.RuleID, &e.Source, &e.Channel, &e.Branch, &e.CurrencyCode, &e.Value, &e.Message,
+			&e.RateAt, &e.FiredAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
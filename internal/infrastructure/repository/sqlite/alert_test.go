@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_Alerts(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err = repo.GetAlertRules(ctx); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Fatalf("empty rules: err = %v, want ErrNotFound", err)
+	}
+
+	rule := &entity.AlertRule{
+		Name: "дешёвый доллар", Kind: entity.AlertPrice, CurrencyCode: "USD", Side: entity.SideSell,
+		Op: entity.AlertBelow, Threshold: 480, Cooldown: time.Hour,
+	}
+	if err = repo.AddAlertRule(ctx, rule); err != nil || rule.ID == 0 {
+		t.Fatalf("add rule: id %d, err %v", rule.ID, err)
+	}
+
+	got, err := repo.GetAlertRules(ctx)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("get rules: %v, %v", got, err)
+	}
+	if r := got[0]; r.Cooldown != time.Hour || r.Firing || !r.LastFiredAt.IsZero() || r.Threshold != 480 || r.Op != entity.AlertBelow {
+		t.Errorf("unexpected rule %+v", r)
+	}
+
+	at := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	if err = repo.UpdateAlertRuleState(ctx, rule.ID, true, at); err != nil {
+		t.Fatalf("update state: %v", err)
+	}
+	got, _ = repo.GetAlertRules(ctx)
+	if !got[0].Firing || !got[0].LastFiredAt.Equal(at) {
+		t.Errorf("state not saved: %+v", got[0])
+	}
+
+	// Правило срабатывает на курс серии один раз
+	event := func() *entity.AlertEvent {
+		return &entity.AlertEvent{
+			RuleID: rule.ID, Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Value: 479.5,
+			Message: "USD продажа 479.50 < 480", RateAt: at, FiredAt: at,
+		}
+	}
+	for i, want := range []bool{true, false} {
+		added, err := repo.AddAlertEvent(ctx, event())
+		if err != nil || added != want {
+			t.Fatalf("add event #%d: added %v, err %v", i, added, err)
+		}
+	}
+	events, err := repo.GetAlertEvents(ctx, time.Time{}, time.Time{})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("get events: %v, %v", events, err)
+	}
+	if e := events[0]; e.RuleID != rule.ID || e.Value != 479.5 || !e.RateAt.Equal(at) {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	if err = repo.DeleteAlertRule(ctx, rule.ID); err != nil {
+		t.Fatalf("delete rule: %v", err)
+	}
+	if _, err = repo.GetAlertEvents(ctx, time.Time{}, time.Time{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("events of deleted rule kept: err = %v", err)
+	}
+	if err = repo.DeleteAlertRule(ctx, rule.ID); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("delete missing rule: err = %v, want ErrNotFound", err)
+	}
+}
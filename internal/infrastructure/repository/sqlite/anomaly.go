@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// anomalySchema keeps anomaly events; one rate side is reported once.
+const anomalySchema = `CREATE TABLE IF NOT EXISTS anomalies (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	channel TEXT NOT NULL DEFAULT '',
+	branch TEXT NOT NULL DEFAULT '',
+	branch_name TEXT NOT NULL DEFAULT '',
+	currency_code TEXT NOT NULL,
+	side TEXT NOT NULL,
+	value REAL NOT NULL,
+	baseline REAL NOT NULL,
+	score REAL NOT NULL,
+	method TEXT NOT NULL,
+	rate_at TIMESTAMP NOT NULL,
+	detected_at TIMESTAMP NOT NULL,
+	UNIQUE(source, channel, branch, currency_code, side, rate_at)
+);
+CREATE INDEX IF NOT EXISTS idx_anomalies_detected_at ON anomalies(detected_at);`
+
+// AddAnomaly stores an anomaly event; a repeated report of the same rate side is ignored.
+func (r *SQLiteExchangeRateRepository) AddAnomaly(ctx context.Context, a *entity.Anomaly) error {
+	if a.DetectedAt.IsZero() {
+		a.DetectedAt = time.Now().UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO anomalies(
+			source, channel, branch, branch_name, currency_code, side, value, baseline, score, method, rate_at,
+			detected_at
+		) VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`,
+		a.Source, a.Channel, a.Branch, a.BranchName, a.CurrencyCode, a.Side, a.Value, a.Baseline, a.Score, a.Method,
+		a.RateAt, a.DetectedAt,
+	)
+	return err
+}
+
+// GetAnomalies returns anomaly events detected in range, newest first.
+func (r *SQLiteExchangeRateRepository) GetAnomalies(
+	ctx context.Context,
+	startDate, endDate time.Time,
+) ([]*entity.Anomaly, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, source, channel, branch, branch_name, currency_code, side, value, baseline, score, method, rate_at,
+			detected_at
+		FROM anomalies
+		WHERE detected_at BETWEEN ? AND ?
+		ORDER BY detected_at DESC, id DESC`,
+		normalizeStart(startDate), normalizeEnd(endDate),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.Anomaly
+	for rows.Next() {
+		a := &entity.Anomaly{}
+		if err = rows.Scan(
+			&a.ID, &a.Source, &a.Channel, &a.Branch, &a.BranchName, &a.CurrencyCode, &a.Side, &a.Value, &a.Baseline,
+			&a.Score, &a.Method, &a.RateAt, &a.DetectedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_Anomalies(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err = repo.GetAnomalies(ctx, time.Time{}, time.Time{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Fatalf("empty anomalies: err = %v, want ErrNotFound", err)
+	}
+
+	at := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	spike := func(side string, detected time.Time) *entity.Anomaly {
+		return &entity.Anomaly{
+			Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Side: side,
+			Value: 580, Baseline: 537, Score: 12.5, Method: "mad", RateAt: at, DetectedAt: detected,
+		}
+	}
+	// Повторный прогон детектора по тому же курсу не дублирует событие
+	for _, a := range []*entity.Anomaly{
+		spike(entity.SideBuy, at), spike(entity.SideBuy, at.Add(time.Minute)), spike(entity.SideSell, at.Add(time.Second)),
+	} {
+		if err = repo.AddAnomaly(ctx, a); err != nil {
+			t.Fatalf("add anomaly: %v", err)
+		}
+	}
+
+	got, err := repo.GetAnomalies(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("get anomalies: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d anomalies, want 2", len(got))
+	}
+	if got[0].Side != entity.SideSell || got[1].Side != entity.SideBuy {
+		t.Errorf("order = %s, %s; want newest first", got[0].Side, got[1].Side)
+	}
+	if a := got[1]; a.Value != 580 || a.Baseline != 537 || a.Score != 12.5 || !a.RateAt.Equal(at) || !a.DetectedAt.Equal(at) {
+		t.Errorf("unexpected anomaly %+v", a)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// GetCandles aggregates rate changes of the source's currency in range into candles per channel and
+// side, sorted by channel, side and start. Periods are cut in loc. The rate in force at startDate
+// opens the first period, so a period may have a candle without changes. Offices of aggregators
+// are left out.
+func (r *SQLiteExchangeRateRepository) GetCandles(
+	ctx context.Context,
+	currencyCode, source string,
+	interval entity.CandleInterval,
+	loc *time.Location,
+	startDate, endDate time.Time,
+) ([]*entity.Candle, error) {
+	start, end := normalizeStart(startDate), normalizeEnd(endDate)
+	// Последний курс до начала диапазона по каждому каналу и изменения внутри диапазона
+	q := `SELECT ` + rateColumns + ` FROM (
+			SELECT ` + rateColumns + `,
+			ROW_NUMBER() OVER (PARTITION BY channel ORDER BY created_at DESC) rn
+			FROM exchange_rates
+			WHERE currency_code = ? AND source = ? AND branch = '' AND created_at < ?
+		) WHERE rn = 1
+		UNION ALL
+		SELECT ` + rateColumns + `
+		FROM exchange_rates
+		WHERE currency_code = ? AND source = ? AND branch = '' AND created_at BETWEEN ? AND ?
+		ORDER BY created_at`
+	rates, err := r.queryRates(ctx, q, currencyCode, source, start, currencyCode, source, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		channel, side string
+		start         time.Time
+	}
+	type series struct{ channel, side string }
+	candles := make(map[key]*entity.Candle)
+	closes := make(map[series]float64) // закрытие предыдущего периода серии
+	var out []*entity.Candle
+	for _, rate := range rates {
+		at, changed := rate.CreatedAt, true
+		if at.Before(start) {
+			at, changed = start, false
+		}
+		for _, side := range []string{entity.SideBuy, entity.SideSell} {
+			raw := rate.Buy
+			if side == entity.SideSell {
+				raw = rate.Sell
+			}
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil || v <= 0 {
+				continue
+			}
+			k := key{rate.Channel, side, interval.Start(at, loc)}
+			c, ok := candles[k]
+			if !ok {
+				// Период открывается курсом, действовавшим на его начало, а не первым изменением в нём
+				open, seeded := closes[series{rate.Channel, side}]
+				if !seeded {
+					open = v
+				}
+				c = &entity.Candle{
+					Source:       rate.Source,
+					Channel:      rate.Channel,
+					CurrencyCode: rate.CurrencyCode,
+					Side:         side,
+					Interval:     interval,
+					Start:        k.start,
+					Open:         open,
+					High:         open,
+					Low:          open,
+				}
+				candles[k] = c
+				out = append(out, c)
+			}
+			closes[series{rate.Channel, side}] = v
+			c.High = max(c.High, v)
+			c.Low = min(c.Low, v)
+			c.Close = v
+			if changed {
+				c.Changes++
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Channel != out[j].Channel {
+			return out[i].Channel < out[j].Channel
+		}
+		if out[i].Side != out[j].Side {
+			return out[i].Side < out[j].Side
+		}
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out, nil
+}
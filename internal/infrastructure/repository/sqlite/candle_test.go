@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_GetCandles(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, almaty) // понедельник
+
+	for _, r := range []*entity.ExchangeRate{
+		{Buy: "490", Sell: "495", CreatedAt: day.Add(-20 * time.Hour)}, // действует на начало диапазона
+		{Buy: "492", Sell: "497", CreatedAt: day.Add(9 * time.Hour)},
+		{Buy: "488", Sell: "499", CreatedAt: day.Add(12 * time.Hour)},
+		{Buy: "491", Sell: "496", CreatedAt: day.Add(18 * time.Hour)},
+		// Во вторник изменений нет, в среду одно — в UTC это ещё вторник
+		{Buy: "495", Sell: "500", CreatedAt: day.Add(48*time.Hour + 2*time.Hour)},
+		{Branch: "Обменник", Buy: "1", Sell: "2", CreatedAt: day.Add(10 * time.Hour)},
+	} {
+		r.CurrencyCode, r.Source, r.Channel = "USD", "Halyk", "cash"
+		r.CreatedAt = r.CreatedAt.UTC()
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	got, err := repo.GetCandles(ctx, "USD", "Halyk", entity.CandleDay, almaty, day.UTC(), day.Add(72*time.Hour).UTC())
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	want := []entity.Candle{
+		{Side: "buy", Start: day, Open: 490, High: 492, Low: 488, Close: 491, Changes: 3},
+		// Среда открывается закрытием понедельника
+		{Side: "buy", Start: day.AddDate(0, 0, 2), Open: 491, High: 495, Low: 491, Close: 495, Changes: 1},
+		{Side: "sell", Start: day, Open: 495, High: 499, Low: 495, Close: 496, Changes: 3},
+		{Side: "sell", Start: day.AddDate(0, 0, 2), Open: 496, High: 500, Low: 496, Close: 500, Changes: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candles, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Side != w.Side || !g.Start.Equal(w.Start) || g.Open != w.Open || g.High != w.High || g.Low != w.Low ||
+			g.Close != w.Close || g.Changes != w.Changes || g.Channel != "cash" || g.Interval != entity.CandleDay {
+			t.Errorf("candle[%d] = %+v, want %+v", i, *g, w)
+		}
+	}
+
+	week, err := repo.GetCandles(ctx, "USD", "Halyk", entity.CandleWeek, almaty, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetCandles(week) error = %v", err)
+	}
+	// Воскресное изменение относится к прошлой неделе, и неделя открывается им
+	if len(week) != 4 || !week[1].Start.Equal(day) || week[1].Open != 490 || week[1].Low != 488 || week[1].Changes != 4 {
+		t.Errorf("weekly buy candles = %+v %+v", *week[0], *week[1])
+	}
+
+	if _, err = repo.GetCandles(ctx, "EUR", "Halyk", entity.CandleDay, almaty, time.Time{}, time.Time{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("no rates: err = %v, want ErrNotFound", err)
+	}
+}
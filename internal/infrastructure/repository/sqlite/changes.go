@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// GetRateChanges returns up to limit rates stored since startDate, newest first, each with its change
+// against the previous rate of its series. Empty currencyCode or source means any.
+func (r *SQLiteExchangeRateRepository) GetRateChanges(
+	ctx context.Context,
+	currencyCode, source string,
+	startDate time.Time,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	// Сначала отбираем последние изменения по индексу, предыдущий курс ищем только для них
+	q := `WITH recent AS (
+		SELECT ` + rateColumns + `
+		FROM exchange_rates
+		WHERE (? = '' OR currency_code = ?) AND (? = '' OR source = ?) AND created_at >= ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	)
+	SELECT ` + latestColumns + `
+	FROM recent l
+	ORDER BY l.created_at DESC`
+	return r.queryRatesWithPrev(ctx, q,
+		currencyCode, currencyCode, source, source, normalizeStart(startDate), limit,
+	)
+}
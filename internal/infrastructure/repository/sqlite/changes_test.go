@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_GetRateChanges(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	base := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	for _, r := range []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "495", Sell: "505", CreatedAt: base},
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "497.5", Sell: "506", CreatedAt: base.Add(time.Hour)},
+		{Source: "Halyk", Channel: "card", CurrencyCode: "USD", Buy: "500", Sell: "503", CreatedAt: base.Add(2 * time.Hour)},
+		{Source: "BCC", Channel: "cash", CurrencyCode: "USD", Buy: "496", Sell: "504", CreatedAt: base.Add(3 * time.Hour)},
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "EUR", Buy: "540", Sell: "550", CreatedAt: base.Add(4 * time.Hour)},
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "494", Sell: "504", CreatedAt: base.Add(5 * time.Hour)},
+	} {
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	got, err := repo.GetRateChanges(ctx, "USD", "Halyk", time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetRateChanges() error = %v", err)
+	}
+	want := []struct {
+		channel, buy string
+		buyChange    float64
+		sellChange   float64
+	}{
+		{"cash", "494", -3.5, -2},
+		{"card", "500", 0, 0},
+		{"cash", "497.5", 2.5, 1},
+		{"cash", "495", 0, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Channel != w.channel || g.Buy != w.buy || g.BuyChangePrev != w.buyChange || g.SellChangePrev != w.sellChange {
+			t.Errorf("change[%d] = %+v, want %+v", i, *g, w)
+		}
+	}
+
+	// Лимит и отсечка по дате не теряют предыдущий курс серии
+	got, err = repo.GetRateChanges(ctx, "", "", base.Add(5*time.Hour), 1)
+	if err != nil || len(got) != 1 || got[0].BuyChangePrev != -3.5 {
+		t.Fatalf("limited: %+v, err %v", got, err)
+	}
+	if got, err = repo.GetRateChanges(ctx, "EUR", "", time.Time{}, 10); err != nil || len(got) != 1 {
+		t.Errorf("EUR: %d changes, err %v", len(got), err)
+	}
+	if _, err = repo.GetRateChanges(ctx, "RUB", "", time.Time{}, 10); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("RUB: err = %v, want ErrNotFound", err)
+	}
+}
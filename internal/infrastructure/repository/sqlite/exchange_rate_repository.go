@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Mi7teR/exr/internal/entity"
@@ -17,6 +18,27 @@ type SQLiteExchangeRateRepository struct {
 	db *sql.DB
 }
 
+const (
+	rateColumns = `currency_code, quote_currency, buy, sell, source, channel, branch, branch_name, city, address, created_at`
+	// latestColumns selects a rate from "latest l" CTE together with the previous value of its series.
+	latestColumns = `l.currency_code, l.quote_currency, l.buy, l.sell, l.source, l.channel, l.branch, l.branch_name, l.city,
+		l.address, l.created_at,
+		(
+			SELECT p.buy FROM exchange_rates p
+			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.branch = l.branch
+				AND p.channel = l.channel
+				AND p.created_at < l.created_at
+			ORDER BY p.created_at DESC LIMIT 1
+		) AS prev_buy,
+		(
+			SELECT p.sell FROM exchange_rates p
+			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.branch = l.branch
+				AND p.channel = l.channel
+				AND p.created_at < l.created_at
+			ORDER BY p.created_at DESC LIMIT 1
+		) AS prev_sell`
+)
+
 // NewSQLiteExchangeRateRepository creates repository and applies schema.
 func NewSQLiteExchangeRateRepository(db *sql.DB) (*SQLiteExchangeRateRepository, error) {
 	repo := &SQLiteExchangeRateRepository{db: db}
@@ -30,9 +52,15 @@ func (r *SQLiteExchangeRateRepository) migrate() error {
 	const schema = `CREATE TABLE IF NOT EXISTS exchange_rates (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		currency_code TEXT NOT NULL,
+		quote_currency TEXT NOT NULL DEFAULT 'KZT',
 		buy TEXT NOT NULL,
 		sell TEXT NOT NULL,
 		source TEXT NOT NULL,
+		channel TEXT NOT NULL DEFAULT '',
+		branch TEXT NOT NULL DEFAULT '',
+		branch_name TEXT NOT NULL DEFAULT '',
+		city TEXT NOT NULL DEFAULT '',
+		address TEXT NOT NULL DEFAULT '',
 		created_at TIMESTAMP NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_exchange_rates_created_at ON exchange_rates(created_at);
@@ -44,7 +72,68 @@ func (r *SQLiteExchangeRateRepository) migrate() error {
 			source, 
 			created_at
 		);`
-	_, err := r.db.ExecContext(context.Background(), schema)
+	ctx := context.Background()
+	if _, err := r.db.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+
+	// Колонки, добавленные после первой версии схемы: у существующих баз их нет.
+	for _, c := range []struct{ name, def string }{
+		{"quote_currency", "TEXT NOT NULL DEFAULT 'KZT'"},
+		{"branch", "TEXT NOT NULL DEFAULT ''"},
+		{"city", "TEXT NOT NULL DEFAULT ''"},
+		{"address", "TEXT NOT NULL DEFAULT ''"},
+		{"channel", "TEXT NOT NULL DEFAULT ''"},
+		{"branch_name", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := r.addColumnIfMissing(ctx, "exchange_rates", c.name, c.def); err != nil {
+			return err
+		}
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`CREATE INDEX IF NOT EXISTS idx_exchange_rates_city ON exchange_rates(city)`); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, quarantineSchema); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, anomalySchema); err != nil {
+		return err
+	}
+	for _, table := range []string{"quarantined_rates", "anomalies"} {
+		if err := r.addColumnIfMissing(ctx, table, "branch_name", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	if _, err := r.db.ExecContext(ctx, alertSchema); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, webhookSchema); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, telegramSchema)
+	return err
+}
+
+func (r *SQLiteExchangeRateRepository) addColumnIfMissing(ctx context.Context, table, column, def string) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+def)
 	return err
 }
 
@@ -58,22 +147,32 @@ func (r *SQLiteExchangeRateRepository) AddExchangeRate(
 	}
 	_, err := r.db.ExecContext(
 		ctx,
-		`INSERT INTO exchange_rates(currency_code, buy, sell, source, created_at) VALUES(?,?,?,?,?)`,
-		rate.CurrencyCode, rate.Buy, rate.Sell, rate.Source, rate.CreatedAt,
+		`INSERT INTO exchange_rates(
+			`+rateColumns+`
+		) VALUES(?,?,?,?,?,?,?,?,?,?,?)`,
+		rate.CurrencyCode, rate.Quote(), rate.Buy, rate.Sell, rate.Source, rate.Channel, rate.Branch, rate.BranchName,
+		rate.City, rate.Address, rate.CreatedAt,
 	)
 	return err
 }
 
-// GetLatestExchangeRate returns the most recent exchange rate for currency+source.
+// BackfillChannel sets the channel of the source's rates stored without one, before the column existed.
+func (r *SQLiteExchangeRateRepository) BackfillChannel(ctx context.Context, source, channel string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE exchange_rates SET channel = ? WHERE source = ? AND channel = ''`, channel, source)
+	return err
+}
+
+// GetLatestExchangeRate returns the most recent exchange rate of the series.
 func (r *SQLiteExchangeRateRepository) GetLatestExchangeRate(
 	ctx context.Context,
-	currencyCode, source string,
+	key entity.SeriesKey,
 ) (*entity.ExchangeRate, error) {
-	q := `SELECT currency_code, buy, sell, source, created_at
+	q := `SELECT ` + rateColumns + `
 		FROM exchange_rates
-		WHERE currency_code = ? AND source = ?
+		WHERE currency_code = ? AND source = ? AND branch = ? AND channel = ?
 		ORDER BY created_at DESC LIMIT 1`
-	rates, err := r.queryRates(ctx, q, currencyCode, source)
+	rates, err := r.queryRates(ctx, q, key.CurrencyCode, key.Source, key.Branch, key.Channel)
 	if err != nil {
 		return nil, err
 	}
@@ -83,28 +182,33 @@ func (r *SQLiteExchangeRateRepository) GetLatestExchangeRate(
 	return rates[0], nil
 }
 
+// GetSeriesRates returns up to limit rates of the series stored in [startDate, endDate), newest first.
+func (r *SQLiteExchangeRateRepository) GetSeriesRates(
+	ctx context.Context,
+	key entity.SeriesKey,
+	startDate, endDate time.Time,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	q := `SELECT ` + rateColumns + `
+		FROM exchange_rates
+		WHERE currency_code = ? AND source = ? AND branch = ? AND channel = ? AND created_at >= ? AND created_at < ?
+		ORDER BY created_at DESC LIMIT ?`
+	return r.queryRates(ctx, q, key.CurrencyCode, key.Source, key.Branch, key.Channel,
+		normalizeStart(startDate), normalizeEnd(endDate), limit)
+}
+
 // GetExchangeRates returns latest rates per currency+source in range with prev change.
 func (r *SQLiteExchangeRateRepository) GetExchangeRates(
 	ctx context.Context,
 	startDate, endDate time.Time,
 ) ([]*entity.ExchangeRate, error) {
 	q := `WITH latest AS (
-		SELECT id, currency_code, buy, sell, source, created_at,
-		ROW_NUMBER() OVER (PARTITION BY currency_code, source ORDER BY created_at DESC) rn
+		SELECT id, ` + rateColumns + `,
+		ROW_NUMBER() OVER (PARTITION BY currency_code, source, branch, channel ORDER BY created_at DESC) rn
 		FROM exchange_rates
 		WHERE created_at BETWEEN ? AND ?
 	)
-	SELECT l.currency_code, l.buy, l.sell, l.source, l.created_at,
-		(
-			SELECT p.buy FROM exchange_rates p
-			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.created_at < l.created_at
-			ORDER BY p.created_at DESC LIMIT 1
-		) AS prev_buy,
-		(
-			SELECT p.sell FROM exchange_rates p
-			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.created_at < l.created_at
-			ORDER BY p.created_at DESC LIMIT 1
-		) AS prev_sell
+	SELECT ` + latestColumns + `
 	FROM latest l WHERE l.rn = 1
 	ORDER BY l.created_at DESC`
 	return r.queryRatesWithPrev(ctx, q, normalizeStart(startDate), normalizeEnd(endDate))
@@ -117,34 +221,56 @@ func (r *SQLiteExchangeRateRepository) GetExchangeRatesByCurrencyCode(
 	startDate, endDate time.Time,
 ) ([]*entity.ExchangeRate, error) {
 	q := `WITH latest AS (
-		SELECT id, currency_code, buy, sell, source, created_at,
-		ROW_NUMBER() OVER (PARTITION BY source ORDER BY created_at DESC) rn
+		SELECT id, ` + rateColumns + `,
+		ROW_NUMBER() OVER (PARTITION BY source, branch, channel ORDER BY created_at DESC) rn
 		FROM exchange_rates
 		WHERE currency_code = ? AND created_at BETWEEN ? AND ?
 	)
-	SELECT l.currency_code, l.buy, l.sell, l.source, l.created_at,
-		(
-			SELECT p.buy FROM exchange_rates p
-			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.created_at < l.created_at
-			ORDER BY p.created_at DESC LIMIT 1
-		) AS prev_buy,
-		(
-			SELECT p.sell FROM exchange_rates p
-			WHERE p.currency_code = l.currency_code AND p.source = l.source AND p.created_at < l.created_at
-			ORDER BY p.created_at DESC LIMIT 1
-		) AS prev_sell
+	SELECT ` + latestColumns + `
 	FROM latest l WHERE l.rn = 1
 	ORDER BY l.created_at DESC`
 	return r.queryRatesWithPrev(ctx, q, currencyCode, normalizeStart(startDate), normalizeEnd(endDate))
 }
 
+// GetLatestExchangeRates returns the latest rate of every series of the currency and source with its change
+// against the previous rate; empty currencyCode or source means any.
+func (r *SQLiteExchangeRateRepository) GetLatestExchangeRates(
+	ctx context.Context,
+	currencyCode, source string,
+) ([]*entity.ExchangeRate, error) {
+	var where []string
+	var args []any
+	if currencyCode != "" {
+		where = append(where, "currency_code = ?")
+		args = append(args, currencyCode)
+	}
+	if source != "" {
+		where = append(where, "source = ?")
+		args = append(args, source)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+	q := `WITH latest AS (
+		SELECT id, ` + rateColumns + `,
+		ROW_NUMBER() OVER (PARTITION BY currency_code, source, branch, channel ORDER BY created_at DESC) rn
+		FROM exchange_rates
+		` + cond + `
+	)
+	SELECT ` + latestColumns + `
+	FROM latest l WHERE l.rn = 1
+	ORDER BY l.created_at DESC`
+	return r.queryRatesWithPrev(ctx, q, args...)
+}
+
 // GetExchangeRatesByCurrencyCodeAndSource returns rates filtered by currency & source.
 func (r *SQLiteExchangeRateRepository) GetExchangeRatesByCurrencyCodeAndSource(
 	ctx context.Context,
 	currencyCode, source string,
 	startDate, endDate time.Time,
 ) ([]*entity.ExchangeRate, error) {
-	q := `SELECT currency_code, buy, sell, source, created_at
+	q := `SELECT ` + rateColumns + `
 		FROM exchange_rates
 		WHERE currency_code = ? AND source = ? AND created_at BETWEEN ? AND ?
 		ORDER BY created_at DESC`
@@ -157,7 +283,7 @@ func (r *SQLiteExchangeRateRepository) GetExchangeRatesBySource(
 	source string,
 	startDate, endDate time.Time,
 ) ([]*entity.ExchangeRate, error) {
-	q := `SELECT currency_code, buy, sell, source, created_at
+	q := `SELECT ` + rateColumns + `
 		FROM exchange_rates
 		WHERE source = ? AND created_at BETWEEN ? AND ?
 		ORDER BY created_at DESC`
@@ -180,9 +306,15 @@ func (r *SQLiteExchangeRateRepository) queryRates(
 		var rate entity.ExchangeRate
 		if err = rows.Scan(
 			&rate.CurrencyCode,
+			&rate.QuoteCurrency,
 			&rate.Buy,
 			&rate.Sell,
 			&rate.Source,
+			&rate.Channel,
+			&rate.Branch,
+			&rate.BranchName,
+			&rate.City,
+			&rate.Address,
 			&rate.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -215,9 +347,15 @@ func (r *SQLiteExchangeRateRepository) queryRatesWithPrev(
 		var prevBuy, prevSell sql.NullString
 		if err = rows.Scan(
 			&rate.CurrencyCode,
+			&rate.QuoteCurrency,
 			&rate.Buy,
 			&rate.Sell,
 			&rate.Source,
+			&rate.Channel,
+			&rate.Branch,
+			&rate.BranchName,
+			&rate.City,
+			&rate.Address,
 			&rate.CreatedAt,
 			&prevBuy,
 			&prevSell,
@@ -136,3 +136,139 @@ func TestSQLiteExchangeRateRepository_CRUD(t *testing.T) {
 		t.Fatalf("expected ErrNotFound for RUB, got %v", err)
 	}
 }
+
+func TestSQLiteExchangeRateRepository_Branches(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for _, r := range []*entity.ExchangeRate{
+		{CurrencyCode: "USD", Buy: "536", Sell: "539", Source: "KursKZ", Branch: "A", City: "Алматы", Address: "Абая 10", CreatedAt: now.Add(-2 * time.Hour)},
+		{CurrencyCode: "USD", Buy: "537", Sell: "540", Source: "KursKZ", Branch: "A", BranchName: "Алтын", City: "Алматы", Address: "Абая 10", CreatedAt: now.Add(-time.Hour)},
+		{CurrencyCode: "USD", Buy: "535", Sell: "541", Source: "KursKZ", Branch: "B", City: "Астана", CreatedAt: now.Add(-30 * time.Minute)},
+	} {
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	latest, err := repo.GetExchangeRatesByCurrencyCode(ctx, "USD", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("get usd: %v", err)
+	}
+	if len(latest) != 2 {
+		t.Fatalf("expected latest rate per office (2), got %d", len(latest))
+	}
+	for _, r := range latest {
+		if r.Branch == "A" {
+			if r.City != "Алматы" || r.Address != "Абая 10" || r.BranchName != "Алтын" {
+				t.Fatalf("location not stored: %+v", r)
+			}
+			if r.BuyChangePrev != 1 {
+				t.Fatalf("office A BuyChangePrev expected 1 got %v", r.BuyChangePrev)
+			}
+		}
+		if r.Branch == "B" && r.BuyChangePrev != 0 {
+			t.Fatalf("office B must not use office A as previous, got %v", r.BuyChangePrev)
+		}
+	}
+
+	b, err := repo.GetLatestExchangeRate(ctx, entity.SeriesKey{Source: "KursKZ", Branch: "B", CurrencyCode: "USD"})
+	if err != nil {
+		t.Fatalf("latest B: %v", err)
+	}
+	if b.Buy != "535" {
+		t.Fatalf("expected office B rate, got %+v", b)
+	}
+	if _, err = repo.GetLatestExchangeRate(ctx, entity.SeriesKey{Source: "KursKZ", CurrencyCode: "USD"}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Fatalf("bank-wide series must be separate from offices, got %v", err)
+	}
+	// История одного офиса: только его курсы до конца периода, от свежих, не больше лимита
+	keyA := entity.SeriesKey{Source: "KursKZ", Branch: "A", CurrencyCode: "USD"}
+	seriesA, err := repo.GetSeriesRates(ctx, keyA, now.Add(-3*time.Hour), now, 1)
+	if err != nil || len(seriesA) != 1 || seriesA[0].Buy != "537" {
+		t.Fatalf("series A limited to 1: %+v, %v", seriesA, err)
+	}
+	seriesA, err = repo.GetSeriesRates(ctx, keyA, now.Add(-3*time.Hour), now.Add(-time.Hour), 10)
+	if err != nil || len(seriesA) != 1 || seriesA[0].Buy != "536" {
+		t.Fatalf("series A before its latest rate: %+v, %v", seriesA, err)
+	}
+
+	// Каналы одного банка — разные ряды
+	for _, r := range []*entity.ExchangeRate{
+		{CurrencyCode: "EUR", Buy: "520", Sell: "530", Source: "Halyk", Channel: entity.ChannelCash, CreatedAt: now.Add(-time.Hour)},
+		{CurrencyCode: "EUR", Buy: "524", Sell: "526", Source: "Halyk", Channel: entity.ChannelCard, CreatedAt: now.Add(-time.Minute)},
+	} {
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	cash, err := repo.GetLatestExchangeRate(ctx, entity.SeriesKey{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "EUR"})
+	if err != nil || cash.Buy != "520" || cash.Channel != entity.ChannelCash {
+		t.Fatalf("latest cash rate: %+v, %v", cash, err)
+	}
+	eur, err := repo.GetExchangeRatesByCurrencyCode(ctx, "EUR", time.Time{}, time.Time{})
+	if err != nil || len(eur) != 2 {
+		t.Fatalf("expected latest rate per channel (2), got %d, %v", len(eur), err)
+	}
+}
+
+func TestSQLiteExchangeRateRepository_MigratesLegacySchema(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	if _, err := db.ExecContext(ctx, `CREATE TABLE exchange_rates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		currency_code TEXT NOT NULL,
+		buy TEXT NOT NULL,
+		sell TEXT NOT NULL,
+		source TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	INSERT INTO exchange_rates(currency_code, buy, sell, source, created_at)
+	VALUES('USD', '490', '495', 'Kaspi', '2024-11-01 12:00:00+00:00');`); err != nil {
+		t.Fatalf("legacy schema: %v", err)
+	}
+
+	repo, err := NewSQLiteExchangeRateRepository(db)
+	if err != nil {
+		t.Fatalf("migrate legacy: %v", err)
+	}
+	got, err := repo.GetLatestExchangeRate(ctx, entity.SeriesKey{Source: "Kaspi", CurrencyCode: "USD"})
+	if err != nil {
+		t.Fatalf("latest after migration: %v", err)
+	}
+	if got.Buy != "490" || got.Branch != "" || got.City != "" || got.Quote() != entity.QuoteKZT {
+		t.Fatalf("unexpected migrated row: %+v", got)
+	}
+
+	// Старые курсы продолжают ряд источника с его каналом
+	if err = repo.BackfillChannel(ctx, "Kaspi", entity.ChannelCard); err != nil {
+		t.Fatalf("backfill channel: %v", err)
+	}
+	if err = repo.AddExchangeRate(ctx, &entity.ExchangeRate{
+		CurrencyCode: "USD", Buy: "491", Sell: "496", Source: "Kaspi", Channel: entity.ChannelCard,
+	}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	latest, err := repo.GetLatestExchangeRates(ctx, "USD", "Kaspi")
+	if err != nil || len(latest) != 1 || latest[0].BuyChangePrev != 1 {
+		t.Fatalf("backfilled rate must precede the new one in one series: %+v, %v", latest, err)
+	}
+
+	ref := &entity.ExchangeRate{CurrencyCode: "USD", QuoteCurrency: "RUB", Buy: "96.9", Sell: "96.9", Source: "CBR"}
+	if err = repo.AddExchangeRate(ctx, ref); err != nil {
+		t.Fatalf("add reference: %v", err)
+	}
+	got, err = repo.GetLatestExchangeRate(ctx, ref.Key())
+	if err != nil || got.QuoteCurrency != "RUB" {
+		t.Fatalf("quote currency not stored: %+v, %v", got, err)
+	}
+
+	// повторная миграция не должна падать
+	if _, err = NewSQLiteExchangeRateRepository(db); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// exportBatch is how many rows EachExchangeRate reads per query.
+const exportBatch = 1000
+
+// EachExchangeRate calls fn for every stored rate in range, oldest first. Empty currencyCode, source or
+// channel means any. Rows are read in batches, so neither memory nor the read lock grows with the range
+// and a slow fn does not hold up writers; an error from fn stops the iteration and is returned.
+func (r *SQLiteExchangeRateRepository) EachExchangeRate(
+	ctx context.Context,
+	currencyCode, source, channel string,
+	startDate, endDate time.Time,
+	fn func(rate *entity.ExchangeRate) error,
+) error {
+	// Курсор по (created_at, id): created_at сравниваем как сохранённую строку, без разбора во время
+	q := `SELECT id, CAST(created_at AS TEXT), ` + rateColumns + `
+		FROM exchange_rates
+		WHERE (? = '' OR currency_code = ?) AND (? = '' OR source = ?) AND (? = '' OR channel = ?)
+			AND created_at BETWEEN ? AND ?
+			AND (created_at, id) > (?, ?)
+		ORDER BY created_at, id
+		LIMIT ?`
+	start, end := normalizeStart(startDate), normalizeEnd(endDate)
+	var (
+		cursorAt string
+		cursorID int64
+	)
+	for {
+		batch, err := r.exportBatch(ctx, q,
+			currencyCode, currencyCode, source, source, channel, channel, start, end, cursorAt, cursorID, exportBatch,
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range batch {
+			if err = fn(row.rate); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatch {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		cursorAt, cursorID = last.createdAt, last.id
+	}
+}
+
+type exportRow struct {
+	id        int64
+	createdAt string
+	rate      *entity.ExchangeRate
+}
+
+func (r *SQLiteExchangeRateRepository) exportBatch(ctx context.Context, query string, args ...any) ([]exportRow, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]exportRow, 0, exportBatch)
+	for rows.Next() {
+		row := exportRow{rate: &entity.ExchangeRate{}}
+		if err = rows.Scan(
+			&row.id,
+			&row.createdAt,
+			&row.rate.CurrencyCode,
+			&row.rate.QuoteCurrency,
+			&row.rate.Buy,
+			&row.rate.Sell,
+			&row.rate.Source,
+			&row.rate.Channel,
+			&row.rate.Branch,
+			&row.rate.BranchName,
+			&row.rate.City,
+			&row.rate.Address,
+			&row.rate.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+func TestSQLiteExchangeRateRepository_EachExchangeRate(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	base := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	// Больше одной пачки; пары курсов с одинаковым временем проверяют курсор по id
+	total := exportBatch + exportBatch/2
+	for i := 0; i < total; i++ {
+		channel := entity.ChannelCash
+		if i%2 == 1 {
+			channel = entity.ChannelCard
+		}
+		err = repo.AddExchangeRate(ctx, &entity.ExchangeRate{
+			Source: "Halyk", Channel: channel, CurrencyCode: "USD", Buy: fmt.Sprint(400 + i), Sell: "600",
+			CreatedAt: base.Add(time.Duration(i/2) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	if err = repo.AddExchangeRate(ctx, &entity.ExchangeRate{
+		Source: "BCC", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "540", Sell: "550", CreatedAt: base,
+	}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	var got []*entity.ExchangeRate
+	collect := func(r *entity.ExchangeRate) error {
+		got = append(got, r)
+		return nil
+	}
+	if err = repo.EachExchangeRate(ctx, "USD", "", "", time.Time{}, time.Time{}, collect); err != nil {
+		t.Fatalf("EachExchangeRate() error = %v", err)
+	}
+	if len(got) != total {
+		t.Fatalf("got %d rates, want %d", len(got), total)
+	}
+	for i, r := range got {
+		if r.Buy != fmt.Sprint(400+i) {
+			t.Fatalf("rate[%d].Buy = %s, want %d: order or cursor broken", i, r.Buy, 400+i)
+		}
+	}
+
+	got = nil
+	if err = repo.EachExchangeRate(ctx, "USD", "Halyk", entity.ChannelCard,
+		base.Add(10*time.Minute), base.Add(19*time.Minute), collect); err != nil {
+		t.Fatalf("filtered: %v", err)
+	}
+	if len(got) != 10 || got[0].Channel != entity.ChannelCard || !got[0].CreatedAt.Equal(base.Add(10*time.Minute)) {
+		t.Errorf("filtered: %d rates, first %+v", len(got), got[0])
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.EachExchangeRate(ctx, "", "", "", time.Time{}, time.Time{}, func(*entity.ExchangeRate) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("stop: err %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// quarantineSchema keeps rates that failed validation; the same values violating the same rule
+// are stored once and counted in hits.
+const quarantineSchema = `CREATE TABLE IF NOT EXISTS quarantined_rates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	currency_code TEXT NOT NULL,
+	quote_currency TEXT NOT NULL DEFAULT 'KZT',
+	buy TEXT NOT NULL,
+	sell TEXT NOT NULL,
+	source TEXT NOT NULL,
+	channel TEXT NOT NULL DEFAULT '',
+	branch TEXT NOT NULL DEFAULT '',
+	branch_name TEXT NOT NULL DEFAULT '',
+	city TEXT NOT NULL DEFAULT '',
+	address TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	rule TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	first_seen TIMESTAMP NOT NULL,
+	last_seen TIMESTAMP NOT NULL,
+	hits INTEGER NOT NULL DEFAULT 1,
+	UNIQUE(currency_code, source, channel, branch, buy, sell, rule)
+);
+CREATE INDEX IF NOT EXISTS idx_quarantined_rates_last_seen ON quarantined_rates(last_seen);`
+
+// QuarantineExchangeRate stores a rate that failed validation, counting repeats.
+func (r *SQLiteExchangeRateRepository) QuarantineExchangeRate(
+	ctx context.Context,
+	q *entity.QuarantinedRate,
+) error {
+	rate := q.Rate
+	if rate.CreatedAt.IsZero() {
+		rate.CreatedAt = time.Now().UTC()
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO quarantined_rates(`+rateColumns+`, rule, reason, first_seen, last_seen, hits)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,1)
+		ON CONFLICT(currency_code, source, channel, branch, buy, sell, rule) DO UPDATE SET
+			reason = excluded.reason,
+			last_seen = excluded.last_seen,
+			hits = hits + 1`,
+		rate.CurrencyCode, rate.Quote(), rate.Buy, rate.Sell, rate.Source, rate.Channel, rate.Branch, rate.BranchName,
+		rate.City, rate.Address, rate.CreatedAt, q.Rule, q.Reason, q.FirstSeen, q.LastSeen,
+	)
+	return err
+}
+
+// GetQuarantinedRates returns quarantined rates last seen in range, newest first.
+func (r *SQLiteExchangeRateRepository) GetQuarantinedRates(
+	ctx context.Context,
+	startDate, endDate time.Time,
+) ([]*entity.QuarantinedRate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, `+rateColumns+`, rule, reason, first_seen, last_seen, hits
+		FROM quarantined_rates
+		WHERE last_seen BETWEEN ? AND ?
+		ORDER BY last_seen DESC, id DESC`,
+		normalizeStart(startDate), normalizeEnd(endDate),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.QuarantinedRate
+	for rows.Next() {
+		q, err := scanQuarantined(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// GetQuarantinedRate returns the quarantined rate by id.
+func (r *SQLiteExchangeRateRepository) GetQuarantinedRate(ctx context.Context, id int64) (*entity.QuarantinedRate, error) {
+	q, err := scanQuarantined(r.db.QueryRowContext(ctx,
+		`SELECT id, `+rateColumns+`, rule, reason, first_seen, last_seen, hits
+		FROM quarantined_rates WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internalErrors.ErrNotFound
+	}
+	return q, err
+}
+
+// DeleteQuarantinedRate removes a reviewed rate from quarantine.
+func (r *SQLiteExchangeRateRepository) DeleteQuarantinedRate(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM quarantined_rates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return internalErrors.ErrNotFound
+	}
+	return nil
+}
+
+func scanQuarantined(row interface{ Scan(dest ...any) error }) (*entity.QuarantinedRate, error) {
+	q := &entity.QuarantinedRate{Rate: &entity.ExchangeRate{}}
+	err := row.Scan(
+		&q.ID,
+		&q.Rate.CurrencyCode,
+		&q.Rate.QuoteCurrency,
+		&q.Rate.Buy,
+		&q.Rate.Sell,
+		&q.Rate.Source,
+		&q.Rate.Channel,
+		&q.Rate.Branch,
+		&q.Rate.BranchName,
+		&q.Rate.City,
+		&q.Rate.Address,
+		&q.Rate.CreatedAt,
+		&q.Rule,
+		&q.Reason,
+		&q.FirstSeen,
+		&q.LastSeen,
+		&q.Hits,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_Quarantine(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err = repo.GetQuarantinedRates(ctx, time.Time{}, time.Time{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Fatalf("empty quarantine: err = %v, want ErrNotFound", err)
+	}
+
+	seen := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	inverted := func(at time.Time) *entity.QuarantinedRate {
+		return &entity.QuarantinedRate{
+			Rate: &entity.ExchangeRate{
+				CurrencyCode: "USD", Buy: "545", Sell: "540", Source: "Halyk", Channel: "cash", CreatedAt: seen,
+			},
+			Rule:      entity.RuleBuyAboveSell,
+			Reason:    "buy 545 above sell 540",
+			FirstSeen: at,
+			LastSeen:  at,
+			Hits:      1,
+		}
+	}
+	// Тот же курс на следующем опросе не плодит строки, а увеличивает счётчик
+	for _, at := range []time.Time{seen, seen.Add(30 * time.Minute)} {
+		if err = repo.QuarantineExchangeRate(ctx, inverted(at)); err != nil {
+			t.Fatalf("quarantine: %v", err)
+		}
+	}
+
+	got, err := repo.GetQuarantinedRates(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("get quarantined: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d quarantined rates, want 1", len(got))
+	}
+	q := got[0]
+	if q.Hits != 2 || !q.FirstSeen.Equal(seen) || !q.LastSeen.Equal(seen.Add(30*time.Minute)) {
+		t.Errorf("hits = %d, first %v, last %v", q.Hits, q.FirstSeen, q.LastSeen)
+	}
+	if q.Rule != entity.RuleBuyAboveSell || q.Rate.Buy != "545" || q.Rate.Channel != "cash" {
+		t.Errorf("unexpected quarantined rate %+v / %+v", q, q.Rate)
+	}
+
+	// Карантин не виден в обычных курсах
+	if _, err = repo.GetLatestExchangeRate(ctx, q.Rate.Key()); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Fatalf("quarantined rate leaked into exchange_rates: %v", err)
+	}
+
+	byID, err := repo.GetQuarantinedRate(ctx, q.ID)
+	if err != nil || byID.Rate.Buy != "545" || byID.Hits != 2 {
+		t.Fatalf("get by id: %+v, %v", byID, err)
+	}
+	if err = repo.DeleteQuarantinedRate(ctx, q.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = repo.GetQuarantinedRate(ctx, q.ID); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("get deleted: err = %v, want ErrNotFound", err)
+	}
+	if err = repo.DeleteQuarantinedRate(ctx, q.ID); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("second delete: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// spreadPct is the spread of a row in percent of its mid rate.
+const spreadPct = `(CAST(sell AS REAL) - CAST(buy AS REAL)) / ((CAST(sell AS REAL) + CAST(buy AS REAL)) / 2) * 100`
+
+// GetSpreadDays aggregates spreads of the currency's KZT rates per source, channel and day of loc,
+// sorted by source, channel and day. Empty source means every source; official rates, offices of
+// aggregators and rates missing a side are left out.
+func (r *SQLiteExchangeRateRepository) GetSpreadDays(
+	ctx context.Context,
+	currencyCode, source string,
+	loc *time.Location,
+	startDate, endDate time.Time,
+) ([]*entity.SpreadDay, error) {
+	start, end := normalizeStart(startDate), normalizeEnd(endDate)
+	// Смещение пояса на начало диапазона: в Казахстане нет перехода на летнее время
+	_, offset := start.In(loc).Zone()
+	q := `SELECT source, channel, date(created_at, ?) AS day,
+			AVG(` + spreadPct + `), MIN(` + spreadPct + `), MAX(` + spreadPct + `), COUNT(*)
+		FROM exchange_rates
+		WHERE currency_code = ? AND (? = '' OR source = ?) AND branch = '' AND channel <> ?
+			AND quote_currency = ? AND CAST(buy AS REAL) > 0 AND CAST(sell AS REAL) > 0
+			AND created_at BETWEEN ? AND ?
+		GROUP BY source, channel, day
+		ORDER BY source, channel, day`
+	rows, err := r.db.QueryContext(ctx, q,
+		fmt.Sprintf("%+d seconds", offset), currencyCode, source, source, entity.ChannelOfficial, entity.QuoteKZT,
+		start, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.SpreadDay
+	for rows.Next() {
+		d := &entity.SpreadDay{CurrencyCode: currencyCode}
+		var day string
+		if err = rows.Scan(&d.Source, &d.Channel, &day, &d.AvgPct, &d.MinPct, &d.MaxPct, &d.Changes); err != nil {
+			return nil, err
+		}
+		if d.Day, err = time.ParseInLocation(time.DateOnly, day, loc); err != nil {
+			return nil, fmt.Errorf("spread day %q: %w", day, err)
+		}
+		out = append(out, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_GetSpreadDays(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, almaty)
+
+	for _, r := range []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: "cash", Buy: "495", Sell: "505", CreatedAt: day.Add(2 * time.Hour)}, // 2%
+		{Source: "Halyk", Channel: "cash", Buy: "498", Sell: "502", CreatedAt: day.Add(9 * time.Hour)}, // 0.8%
+		{Source: "Halyk", Channel: "cash", Buy: "490", Sell: "510", CreatedAt: day.Add(26 * time.Hour)},
+		{Source: "BCC", Channel: "cash", Buy: "499", Sell: "501", CreatedAt: day.Add(3 * time.Hour)},
+		{Source: "BCC", Channel: "cash", Buy: "499", Sell: "", CreatedAt: day.Add(4 * time.Hour)},
+		{Source: "NBRK", Channel: "official", Buy: "500", Sell: "500", CreatedAt: day.Add(time.Hour)},
+		{Source: "Halyk", Branch: "Обменник", Channel: "cash", Buy: "400", Sell: "600", CreatedAt: day.Add(time.Hour)},
+	} {
+		r.CurrencyCode = "USD"
+		r.CreatedAt = r.CreatedAt.UTC()
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	got, err := repo.GetSpreadDays(ctx, "USD", "", almaty, day.UTC(), day.Add(48*time.Hour).UTC())
+	if err != nil {
+		t.Fatalf("GetSpreadDays() error = %v", err)
+	}
+	want := []entity.SpreadDay{
+		{Source: "BCC", Day: day, AvgPct: 0.4, MinPct: 0.4, MaxPct: 0.4, Changes: 1},
+		{Source: "Halyk", Day: day, AvgPct: 1.4, MinPct: 0.8, MaxPct: 2, Changes: 2},
+		{Source: "Halyk", Day: day.AddDate(0, 0, 1), AvgPct: 4, MinPct: 4, MaxPct: 4, Changes: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d days, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Source != w.Source || !g.Day.Equal(w.Day) || g.Changes != w.Changes || g.Channel != "cash" ||
+			math.Abs(g.AvgPct-w.AvgPct) > 1e-9 || math.Abs(g.MinPct-w.MinPct) > 1e-9 || math.Abs(g.MaxPct-w.MaxPct) > 1e-9 {
+			t.Errorf("day[%d] = %+v, want %+v", i, *g, w)
+		}
+	}
+
+	if got, err = repo.GetSpreadDays(ctx, "USD", "BCC", almaty, time.Time{}, time.Time{}); err != nil || len(got) != 1 {
+		t.Errorf("BCC only: %d days, err %v", len(got), err)
+	}
+	if _, err = repo.GetSpreadDays(ctx, "EUR", "", almaty, time.Time{}, time.Time{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("no rates: err = %v, want ErrNotFound", err)
+	}
+}
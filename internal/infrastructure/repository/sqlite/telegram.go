@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// telegramSchema links alert rules created from the Telegram bot to the chats that own them.
+const telegramSchema = `CREATE TABLE IF NOT EXISTS telegram_alerts (
+	rule_id INTEGER PRIMARY KEY,
+	chat_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_telegram_alerts_chat ON telegram_alerts(chat_id);`
+
+// AddTelegramAlert records that the chat owns the alert rule.
+func (r *SQLiteExchangeRateRepository) AddTelegramAlert(ctx context.Context, ruleID, chatID int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO telegram_alerts(rule_id, chat_id) VALUES(?,?)`, ruleID, chatID)
+	return err
+}
+
+// GetTelegramAlertChat returns the chat that owns the alert rule.
+func (r *SQLiteExchangeRateRepository) GetTelegramAlertChat(ctx context.Context, ruleID int64) (int64, error) {
+	var chatID int64
+	err := r.db.QueryRowContext(ctx, `SELECT chat_id FROM telegram_alerts WHERE rule_id = ?`, ruleID).Scan(&chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, internalErrors.ErrNotFound
+	}
+	return chatID, err
+}
+
+// GetTelegramAlertRules returns ids of the alert rules owned by the chat.
+func (r *SQLiteExchangeRateRepository) GetTelegramAlertRules(ctx context.Context, chatID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT rule_id FROM telegram_alerts WHERE chat_id = ? ORDER BY rule_id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// DeleteTelegramAlert forgets the owner of the alert rule.
+func (r *SQLiteExchangeRateRepository) DeleteTelegramAlert(ctx context.Context, ruleID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM telegram_alerts WHERE rule_id = ?`, ruleID)
+	return err
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_TelegramAlerts(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	for _, link := range [][2]int64{{1, 100}, {2, 200}, {3, 100}} {
+		if err = repo.AddTelegramAlert(ctx, link[0], link[1]); err != nil {
+			t.Fatalf("add telegram alert: %v", err)
+		}
+	}
+	ids, err := repo.GetTelegramAlertRules(ctx, 100)
+	if err != nil || len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("rules of chat 100 = %v, %v; want [1 3]", ids, err)
+	}
+	if chat, err := repo.GetTelegramAlertChat(ctx, 2); err != nil || chat != 200 {
+		t.Errorf("chat of rule 2 = %d, %v; want 200", chat, err)
+	}
+
+	if err = repo.DeleteTelegramAlert(ctx, 2); err != nil {
+		t.Fatalf("delete telegram alert: %v", err)
+	}
+	if _, err = repo.GetTelegramAlertChat(ctx, 2); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("deleted link: err = %v, want ErrNotFound", err)
+	}
+	if _, err = repo.GetTelegramAlertRules(ctx, 200); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("chat without rules: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// webhookSchema keeps webhook subscriptions and their delivery queue; a delivery row is both
+// a queue entry and the log of its attempts.
+const webhookSchema = `CREATE TABLE IF NOT EXISTS webhooks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	currency_code TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL DEFAULT '',
+	channel TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_id INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	payload BLOB NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_status INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);`
+
+const deliveryColumns = `id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status, last_error,
+	created_at, delivered_at`
+
+// AddWebhook stores a new webhook subscription and sets its ID.
+func (r *SQLiteExchangeRateRepository) AddWebhook(ctx context.Context, w *entity.Webhook) error {
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhooks(url, secret, currency_code, source, channel, created_at) VALUES(?,?,?,?,?,?)`,
+		w.URL, w.Secret, w.CurrencyCode, w.Source, w.Channel, w.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	w.ID, err = res.LastInsertId()
+	return err
+}
+
+// GetWebhooks returns all webhook subscriptions in creation order.
+func (r *SQLiteExchangeRateRepository) GetWebhooks(ctx context.Context) ([]*entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, url, secret, currency_code, source, channel, created_at FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.Webhook
+	for rows.Next() {
+		w := &entity.Webhook{}
+		if err = rows.Scan(&w.ID, &w.URL, &w.Secret, &w.CurrencyCode, &w.Source, &w.Channel, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// DeleteWebhook removes the subscription together with its deliveries.
+func (r *SQLiteExchangeRateRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return internalErrors.ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// AddWebhookDelivery queues a delivery and sets its ID.
+func (r *SQLiteExchangeRateRepository) AddWebhookDelivery(ctx context.Context, d *entity.WebhookDelivery) error {
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = d.CreatedAt
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries(webhook_id, event, payload, status, attempts, next_attempt_at, created_at)
+		VALUES(?,?,?,?,?,?,?)`,
+		d.WebhookID, d.Event, d.Payload, d.Status, d.Attempts, d.NextAttemptAt, d.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	d.ID, err = res.LastInsertId()
+	return err
+}
+
+// GetDueWebhookDeliveries returns up to limit pending deliveries whose next attempt is due, oldest first.
+func (r *SQLiteExchangeRateRepository) GetDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	return r.queryDeliveries(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id LIMIT ?`,
+		entity.DeliveryPending, now, limit,
+	)
+}
+
+// GetWebhookDeliveries returns up to limit latest deliveries of the webhook, newest first.
+func (r *SQLiteExchangeRateRepository) GetWebhookDeliveries(
+	ctx context.Context,
+	webhookID int64,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	return r.queryDeliveries(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`,
+		webhookID, limit,
+	)
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery attempt.
+func (r *SQLiteExchangeRateRepository) UpdateWebhookDelivery(ctx context.Context, d *entity.WebhookDelivery) error {
+	delivered := sql.NullTime{Time: d.DeliveredAt, Valid: !d.DeliveredAt.IsZero()}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastStatus, d.LastError, delivered, d.ID,
+	)
+	return err
+}
+
+func (r *SQLiteExchangeRateRepository) queryDeliveries(
+	ctx context.Context,
+	query string,
+	args ...any,
+) ([]*entity.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*entity.WebhookDelivery
+	for rows.Next() {
+		d := &entity.WebhookDelivery{}
+		var delivered sql.NullTime
+		if err = rows.Scan(
+			&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastStatus,
+			&d.LastError, &d.CreatedAt, &delivered,
+		); err != nil {
+			return nil, err
+		}
+		d.DeliveredAt = delivered.Time
+		out = append(out, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
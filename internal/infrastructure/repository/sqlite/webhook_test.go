@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestSQLiteExchangeRateRepository_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewSQLiteExchangeRateRepository(setupTestDB(t))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	hook := &entity.Webhook{URL: "https://pricing.local/hook", Secret: "s3cret", CurrencyCode: "USD"}
+	if err = repo.AddWebhook(ctx, hook); err != nil || hook.ID == 0 {
+		t.Fatalf("add webhook: id %d, err %v", hook.ID, err)
+	}
+	hooks, err := repo.GetWebhooks(ctx)
+	if err != nil || len(hooks) != 1 || hooks[0].Secret != "s3cret" || hooks[0].CurrencyCode != "USD" {
+		t.Fatalf("get webhooks: %+v, %v", hooks, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	due := &entity.WebhookDelivery{
+		WebhookID: hook.ID, Event: "rates.changed", Payload: []byte(`{"rates":[]}`),
+		Status: entity.DeliveryPending, CreatedAt: now.Add(-time.Minute),
+	}
+	later := &entity.WebhookDelivery{
+		WebhookID: hook.ID, Event: "rates.changed", Payload: []byte(`{}`),
+		Status: entity.DeliveryPending, CreatedAt: now, NextAttemptAt: now.Add(time.Hour),
+	}
+	for _, d := range []*entity.WebhookDelivery{due, later} {
+		if err = repo.AddWebhookDelivery(ctx, d); err != nil {
+			t.Fatalf("add delivery: %v", err)
+		}
+	}
+
+	got, err := repo.GetDueWebhookDeliveries(ctx, now, 10)
+	if err != nil || len(got) != 1 || got[0].ID != due.ID || string(got[0].Payload) != `{"rates":[]}` {
+		t.Fatalf("due deliveries: %+v, %v", got, err)
+	}
+
+	due.Status, due.Attempts, due.LastStatus, due.DeliveredAt = entity.DeliveryDelivered, 1, 200, now
+	if err = repo.UpdateWebhookDelivery(ctx, due); err != nil {
+		t.Fatalf("update delivery: %v", err)
+	}
+	if _, err = repo.GetDueWebhookDeliveries(ctx, now, 10); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("delivered is still due: err = %v", err)
+	}
+
+	log, err := repo.GetWebhookDeliveries(ctx, hook.ID, 10)
+	if err != nil || len(log) != 2 || log[0].ID != later.ID {
+		t.Fatalf("delivery log: %+v, %v", log, err)
+	}
+	if d := log[1]; d.Status != entity.DeliveryDelivered || d.Attempts != 1 || d.LastStatus != 200 || !d.DeliveredAt.Equal(now) {
+		t.Errorf("unexpected delivery %+v", d)
+	}
+
+	if err = repo.DeleteWebhook(ctx, hook.ID); err != nil {
+		t.Fatalf("delete webhook: %v", err)
+	}
+	if _, err = repo.GetWebhookDeliveries(ctx, hook.ID, 10); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("deliveries of deleted webhook kept: err = %v", err)
+	}
+	if err = repo.DeleteWebhook(ctx, hook.ID); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("delete missing webhook: err = %v", err)
+	}
+}
@@ -0,0 +1,384 @@
+// Package digest builds the morning email report: yesterday's close and today's open of every
+// source, the best places to buy and sell and notable moves, per currency of each recipient.
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// Config configures the digest schedule, its content and delivery.
+type Config struct {
+	Time          string      `json:"time"`           // когда отправлять, ЧЧ:ММ по местному времени (TZ)
+	MoveThreshold float64     `json:"move_threshold"` // изменение за сутки в процентах, о котором стоит написать
+	BestLimit     int         `json:"best_limit"`     // сколько лучших предложений на сторону
+	SMTP          SMTPConfig  `json:"smtp"`
+	Recipients    []Recipient `json:"recipients"`
+}
+
+// Recipient is an address and the currencies it wants in its digest.
+type Recipient struct {
+	Email      string   `json:"email"`
+	Currencies []string `json:"currencies"` // пусто — DefaultCurrencies
+}
+
+// DefaultCurrencies are reported to recipients without preferences.
+var DefaultCurrencies = []string{"USD", "EUR", "RUB"}
+
+// DefaultConfig sends at 09:00, after banks set their morning rates.
+func DefaultConfig() Config {
+	return Config{Time: "09:00", MoveThreshold: 0.5, BestLimit: 3}
+}
+
+// LoadConfig reads digest settings from JSON; omitted fields keep their defaults.
+func LoadConfig(r io.Reader) (Config, error) {
+	cfg := DefaultConfig()
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("decode digest config: %w", err)
+	}
+	invalid := func(what string, v any) error {
+		return fmt.Errorf("digest %s %v: %w", what, v, internalErrors.ErrInvalidArgument)
+	}
+	if _, err := time.Parse("15:04", cfg.Time); err != nil {
+		return Config{}, invalid("time", cfg.Time)
+	}
+	if cfg.MoveThreshold < 0 {
+		return Config{}, invalid("move_threshold", cfg.MoveThreshold)
+	}
+	if cfg.SMTP.Addr == "" || cfg.SMTP.From == "" {
+		return Config{}, invalid("smtp", "without addr or from")
+	}
+	if len(cfg.Recipients) == 0 {
+		return Config{}, invalid("recipients", "[]")
+	}
+	for i, r := range cfg.Recipients {
+		if _, err := mail.ParseAddress(r.Email); err != nil {
+			return Config{}, invalid("recipient", r.Email)
+		}
+		for j, c := range r.Currencies {
+			cfg.Recipients[i].Currencies[j] = strings.ToUpper(c)
+		}
+	}
+	return cfg, nil
+}
+
+// Service is the part of the exchange rate usecase the digest is built from.
+type Service interface {
+	Sources() []entity.SourceInfo
+	Candles(ctx context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error)
+	BestRates(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.BestRate, error)
+}
+
+// Mailer sends an email.
+type Mailer interface {
+	Send(ctx context.Context, email *Email) error
+}
+
+// Digest is the report for one day.
+type Digest struct {
+	Date     time.Time // начало сегодняшнего дня
+	Sections []*Section
+}
+
+// Section is the part of the digest about one currency.
+type Section struct {
+	Currency string
+	Rows     []Row  // курсы источников, официальный курс первым
+	Best     []Best // по каналам
+	Moves    []Move // по убыванию модуля изменения
+}
+
+// Row is a source's rate at yesterday's close and today at the time of the digest; zero if the side is not quoted.
+type Row struct {
+	Name      string
+	Channel   string
+	CloseBuy  float64
+	CloseSell float64
+	OpenBuy   float64
+	OpenSell  float64
+}
+
+// BuyChange is the change of the buy rate since yesterday's close, in percent.
+func (r Row) BuyChange() float64 { return pct(r.CloseBuy, r.OpenBuy) }
+
+// SellChange is the change of the sell rate since yesterday's close, in percent.
+func (r Row) SellChange() float64 { return pct(r.CloseSell, r.OpenSell) }
+
+// Best lists where to buy and sell the currency in one channel.
+type Best struct {
+	Channel string
+	Buy     []Offer // где купить валюту
+	Sell    []Offer // где продать валюту
+}
+
+// Offer is a named best offer.
+type Offer struct {
+	Name string
+	Rate float64
+}
+
+// Move is a change of one side of a source's rate over the last day beyond the threshold.
+type Move struct {
+	Name    string
+	Channel string
+	Side    string
+	From    float64 // открытие вчерашнего дня
+	To      float64 // сейчас
+	Pct     float64
+}
+
+// Generator builds digests and mails them on schedule.
+type Generator struct {
+	svc    Service
+	mailer Mailer
+	cfg    Config
+	l      logger.Logger
+	now    func() time.Time
+}
+
+// NewGenerator creates a digest generator.
+func NewGenerator(svc Service, mailer Mailer, cfg Config, l logger.Logger) *Generator {
+	return &Generator{svc: svc, mailer: mailer, cfg: cfg, l: l, now: time.Now}
+}
+
+// Run sends the digest every day at the configured time until ctx is done. A digest missed
+// while the process was down is not sent on start.
+func (g *Generator) Run(ctx context.Context) {
+	for {
+		next := nextRun(g.now(), g.cfg.Time)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := g.SendAll(ctx); err != nil {
+			g.l.Error("digest: send failed", "err", err)
+		}
+	}
+}
+
+// nextRun returns the next moment at hh:mm local time after now.
+func nextRun(now time.Time, hhmm string) time.Time {
+	at, _ := time.Parse("15:04", hhmm)
+	now = now.In(time.Local)
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, at.Hour(), at.Minute(), 0, 0, time.Local)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// sendTimeout limits mailing one recipient: Run's context never ends, so the SMTP session needs its own deadline.
+const sendTimeout = 2 * time.Minute
+
+// SendAll builds today's digest and mails every recipient its currencies. A failed recipient
+// does not stop the others.
+func (g *Generator) SendAll(ctx context.Context) error {
+	now := g.now()
+	sections := map[string]*Section{}
+	failed := map[string]error{}
+	var errs []error
+	for _, r := range g.cfg.Recipients {
+		if err := g.send(ctx, r, now, sections, failed); err != nil {
+			errs = append(errs, fmt.Errorf("send to %s: %w", r.Email, err))
+			continue
+		}
+		g.l.Info("digest sent", "to", r.Email)
+	}
+	return errors.Join(errs...)
+}
+
+// send mails the recipient its digest; sections and failed cache the currencies already built for others.
+func (g *Generator) send(
+	ctx context.Context,
+	r Recipient,
+	now time.Time,
+	sections map[string]*Section,
+	failed map[string]error,
+) error {
+	d := &Digest{Date: dayStart(now)}
+	for _, c := range currenciesOf(r) {
+		if err, ok := failed[c]; ok {
+			return err
+		}
+		s, ok := sections[c]
+		if !ok {
+			var err error
+			if s, err = g.section(ctx, c, now); err != nil {
+				failed[c] = fmt.Errorf("build %s: %w", c, err)
+				return failed[c]
+			}
+			sections[c] = s
+		}
+		d.Sections = append(d.Sections, s)
+	}
+	email, err := Render(d)
+	if err != nil {
+		return err
+	}
+	email.To = r.Email
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return g.mailer.Send(ctx, email)
+}
+
+// Build returns the digest of the currencies as of now.
+func (g *Generator) Build(ctx context.Context, currencies []string, now time.Time) (*Digest, error) {
+	d := &Digest{Date: dayStart(now)}
+	for _, c := range currencies {
+		s, err := g.section(ctx, strings.ToUpper(c), now)
+		if err != nil {
+			return nil, fmt.Errorf("build %s: %w", c, err)
+		}
+		d.Sections = append(d.Sections, s)
+	}
+	return d, nil
+}
+
+func (g *Generator) section(ctx context.Context, currency string, now time.Time) (*Section, error) {
+	today := dayStart(now)
+	yesterday := today.AddDate(0, 0, -1)
+	s := &Section{Currency: currency}
+
+	for _, info := range g.svc.Sources() {
+		// Иностранные ЦБ котируют не в тенге
+		if info.Kind == entity.SourceKindCentralBank && info.ID != exrate.OfficialSource {
+			continue
+		}
+		candles, err := g.svc.Candles(ctx, &exrate.CandleFilter{
+			CurrencyCode: currency,
+			Source:       info.ID,
+			Interval:     entity.CandleDay,
+			StartDate:    yesterday,
+			EndDate:      now,
+		})
+		if errors.Is(err, internalErrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("candles of %s: %w", info.ID, err)
+		}
+		rows, moves := summarize(info.DisplayName(), candles, yesterday, today, g.cfg.MoveThreshold)
+		s.Rows = append(s.Rows, rows...)
+		s.Moves = append(s.Moves, moves...)
+	}
+	sort.SliceStable(s.Rows, func(i, j int) bool {
+		oi, oj := s.Rows[i].Channel == entity.ChannelOfficial, s.Rows[j].Channel == entity.ChannelOfficial
+		if oi != oj {
+			return oi
+		}
+		return s.Rows[i].Name < s.Rows[j].Name
+	})
+	sort.SliceStable(s.Moves, func(i, j int) bool { return math.Abs(s.Moves[i].Pct) > math.Abs(s.Moves[j].Pct) })
+
+	best, err := g.svc.BestRates(ctx, &exrate.ExchangeRateFilter{CurrencyCode: currency}, g.cfg.BestLimit)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, fmt.Errorf("best rates: %w", err)
+	}
+	names := map[string]string{}
+	for _, info := range g.svc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	for _, br := range best {
+		s.Best = append(s.Best, Best{Channel: br.Channel, Buy: offers(br.Buy, names), Sell: offers(br.Sell, names)})
+	}
+	return s, nil
+}
+
+// summarize turns day candles of one source into rows per channel and moves beyond threshold.
+func summarize(name string, candles []*entity.Candle, yesterday, today time.Time, threshold float64) ([]Row, []Move) {
+	rows := map[string]*Row{}
+	var order []string
+	var moves []Move
+	opened := map[string]float64{} // открытие вчерашнего дня по каналу и стороне
+	for _, c := range candles {
+		r, ok := rows[c.Channel]
+		if !ok {
+			r = &Row{Name: name, Channel: c.Channel}
+			rows[c.Channel] = r
+			order = append(order, c.Channel)
+		}
+		key := c.Channel + "/" + c.Side
+		switch {
+		case c.Start.Equal(yesterday):
+			opened[key] = c.Open
+			if c.Side == entity.SideBuy {
+				r.CloseBuy = c.Close
+			} else {
+				r.CloseSell = c.Close
+			}
+		case c.Start.Equal(today):
+			if c.Side == entity.SideBuy {
+				r.OpenBuy = c.Close
+			} else {
+				r.OpenSell = c.Close
+			}
+			from, ok := opened[key]
+			if !ok {
+				// Серия началась сегодня, сравнивать не с чем
+				continue
+			}
+			if p := pct(from, c.Close); threshold > 0 && math.Abs(p) >= threshold {
+				moves = append(moves, Move{Name: name, Channel: c.Channel, Side: c.Side, From: from, To: c.Close, Pct: p})
+			}
+		}
+	}
+	out := make([]Row, 0, len(order))
+	for _, ch := range order {
+		out = append(out, *rows[ch])
+	}
+	return out, moves
+}
+
+func offers(in []entity.Offer, names map[string]string) []Offer {
+	out := make([]Offer, 0, len(in))
+	for _, o := range in {
+		name := entity.BranchLabel(o.Branch, o.BranchName)
+		if name == "" {
+			name = names[o.Source]
+		}
+		if name == "" {
+			name = o.Source
+		}
+		if o.City != "" {
+			name += " (" + o.City + ")"
+		}
+		out = append(out, Offer{Name: name, Rate: o.Rate})
+	}
+	return out
+}
+
+func currenciesOf(r Recipient) []string {
+	if len(r.Currencies) == 0 {
+		return DefaultCurrencies
+	}
+	return r.Currencies
+}
+
+func dayStart(t time.Time) time.Time {
+	return entity.CandleDay.Start(t, time.Local)
+}
+
+func pct(from, to float64) float64 {
+	if from == 0 || to == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
@@ -0,0 +1,409 @@
+package digest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any)        {}
+func (nopLogger) Info(string, ...any)         {}
+func (nopLogger) Warn(string, ...any)         {}
+func (nopLogger) Error(string, ...any)        {}
+func (l nopLogger) With(...any) logger.Logger { return l }
+
+// mockService отдаёт дневные свечи по источнику и валюте
+type mockService struct {
+	candles map[string][]*entity.Candle // источник/валюта -> свечи
+	best    map[string][]*entity.BestRate
+	broken  string // валюта, свечи которой не читаются
+	calls   int
+}
+
+func (m *mockService) Sources() []entity.SourceInfo {
+	return []entity.SourceInfo{
+		{ID: "halyk", Name: "Halyk Bank", Kind: entity.SourceKindBank},
+		{ID: exrate.OfficialSource, Name: "НБРК", Kind: entity.SourceKindCentralBank},
+		{ID: "cbr", Name: "ЦБ РФ", Kind: entity.SourceKindCentralBank},
+		{ID: "bcc", Name: "BCC", Kind: entity.SourceKindBank},
+	}
+}
+
+func (m *mockService) Candles(_ context.Context, f *exrate.CandleFilter) ([]*entity.Candle, error) {
+	m.calls++
+	if f.Source == "cbr" {
+		panic("foreign central bank must be skipped")
+	}
+	if f.CurrencyCode == m.broken {
+		return nil, errors.New("database is locked")
+	}
+	c, ok := m.candles[f.Source+"/"+f.CurrencyCode]
+	if !ok {
+		return nil, internalErrors.ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *mockService) BestRates(_ context.Context, f *exrate.ExchangeRateFilter, _ int) ([]*entity.BestRate, error) {
+	b, ok := m.best[f.CurrencyCode]
+	if !ok {
+		return nil, internalErrors.ErrNotFound
+	}
+	return b, nil
+}
+
+var (
+	testNow       = time.Date(2025, 3, 4, 9, 0, 0, 0, time.Local)
+	testToday     = time.Date(2025, 3, 4, 0, 0, 0, 0, time.Local)
+	testYesterday = time.Date(2025, 3, 3, 0, 0, 0, 0, time.Local)
+)
+
+func day(start time.Time, channel, side string, open, close float64) *entity.Candle {
+	return &entity.Candle{Channel: channel, Side: side, Interval: entity.CandleDay, Start: start, Open: open, Close: close}
+}
+
+func newMockService() *mockService {
+	return &mockService{
+		candles: map[string][]*entity.Candle{
+			"halyk/USD": {
+				day(testYesterday, entity.ChannelCash, entity.SideBuy, 500, 502),
+				day(testToday, entity.ChannelCash, entity.SideBuy, 502, 506),
+				day(testYesterday, entity.ChannelCash, entity.SideSell, 505, 507),
+				day(testToday, entity.ChannelCash, entity.SideSell, 507, 507),
+			},
+			"bcc/USD": {
+				// серия появилась сегодня: вчерашнего закрытия нет
+				day(testToday, entity.ChannelCard, entity.SideSell, 509, 509),
+			},
+			exrate.OfficialSource + "/USD": {
+				day(testYesterday, entity.ChannelOfficial, entity.SideBuy, 503, 503),
+				day(testToday, entity.ChannelOfficial, entity.SideBuy, 503, 504),
+				day(testYesterday, entity.ChannelOfficial, entity.SideSell, 503, 503),
+				day(testToday, entity.ChannelOfficial, entity.SideSell, 503, 504),
+			},
+			"halyk/EUR": {
+				day(testYesterday, entity.ChannelCash, entity.SideBuy, 540, 540),
+				day(testToday, entity.ChannelCash, entity.SideBuy, 540, 541),
+			},
+		},
+		best: map[string][]*entity.BestRate{
+			"USD": {{
+				CurrencyCode: "USD",
+				Channel:      entity.ChannelCash,
+				Buy:          []entity.Offer{{Source: "halyk", Rate: 507}},
+				Sell:         []entity.Offer{{Source: "kurs", Branch: "Обменник", City: "Алматы", Rate: 506.5}},
+			}},
+		},
+	}
+}
+
+func TestGenerator_Build(t *testing.T) {
+	svc := newMockService()
+	g := NewGenerator(svc, nil, DefaultConfig(), nopLogger{})
+
+	d, err := g.Build(context.Background(), []string{"usd"}, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Date.Equal(testToday) || len(d.Sections) != 1 {
+		t.Fatalf("unexpected digest %+v", d)
+	}
+	s := d.Sections[0]
+	if s.Currency != "USD" || len(s.Rows) != 3 {
+		t.Fatalf("unexpected section %+v", s)
+	}
+	// Официальный курс первым, дальше по имени
+	want := []Row{
+		{Name: "НБРК", Channel: entity.ChannelOfficial, CloseBuy: 503, CloseSell: 503, OpenBuy: 504, OpenSell: 504},
+		{Name: "BCC", Channel: entity.ChannelCard, OpenSell: 509},
+		{Name: "Halyk Bank", Channel: entity.ChannelCash, CloseBuy: 502, CloseSell: 507, OpenBuy: 506, OpenSell: 507},
+	}
+	for i, w := range want {
+		if s.Rows[i] != w {
+			t.Errorf("row %d = %+v, want %+v", i, s.Rows[i], w)
+		}
+	}
+	// 500 -> 506 это +1.2%, 503 -> 504 и 505 -> 507 ниже порога 0.5%
+	if len(s.Moves) != 1 || s.Moves[0].Name != "Halyk Bank" || s.Moves[0].Side != entity.SideBuy ||
+		s.Moves[0].From != 500 || s.Moves[0].To != 506 {
+		t.Errorf("unexpected moves %+v", s.Moves)
+	}
+	if len(s.Best) != 1 || s.Best[0].Buy[0].Name != "Halyk Bank" || s.Best[0].Sell[0].Name != "Обменник (Алматы)" {
+		t.Errorf("unexpected best %+v", s.Best)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := NewGenerator(newMockService(), nil, DefaultConfig(), nopLogger{})
+	d, err := g.Build(context.Background(), []string{"USD", "EUR", "GBP"}, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, err := Render(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Subject != "Курсы валют на 04.03.2025: USD, EUR, GBP" {
+		t.Errorf("subject = %q", email.Subject)
+	}
+	for _, want := range []string{
+		"== USD/KZT ==",
+		"Halyk Bank, наличные: покупка 502.00 -> 506.00 ↗ +0.80%, продажа 507.00 -> 507.00",
+		"BCC, по карте: покупка — -> —, продажа — -> 509.00",
+		"наличные: купить — Halyk Bank 507.00; продать — Обменник (Алматы) 506.50",
+		"Halyk Bank, наличные: покупка 500.00 -> 506.00 (+1.20%)",
+		"== GBP/KZT ==\n\nНет курсов.",
+	} {
+		if !strings.Contains(email.Text, want) {
+			t.Errorf("text digest misses %q:\n%s", want, email.Text)
+		}
+	}
+	for _, want := range []string{
+		"<h2 style=\"font-size: 17px; margin-top: 24px;\">USD/KZT</h2>",
+		"<td>Halyk Bank</td><td>наличные</td>",
+		"506.00 <span style=\"color: #166534;\">↗ &#43;0.80%</span>",
+		"Обменник (Алматы) 506.50",
+	} {
+		if !strings.Contains(email.HTML, want) {
+			t.Errorf("html digest misses %q:\n%s", want, email.HTML)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(strings.NewReader(`{"time":"08:30","smtp":{"addr":"localhost:25","from":"exr@bank.kz"},
+		"recipients":[{"email":"cfo@bank.kz","currencies":["usd"]},{"email":"Treasury <t@bank.kz>"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Time != "08:30" || cfg.MoveThreshold != 0.5 || cfg.Recipients[0].Currencies[0] != "USD" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if got := currenciesOf(cfg.Recipients[1]); strings.Join(got, ",") != "USD,EUR,RUB" {
+		t.Errorf("default currencies = %v", got)
+	}
+
+	for _, bad := range []string{
+		`{"time":"25:00","smtp":{"addr":"localhost:25","from":"exr@bank.kz"},"recipients":[{"email":"a@b.kz"}]}`,
+		`{"smtp":{"addr":"localhost:25"},"recipients":[{"email":"a@b.kz"}]}`,
+		`{"smtp":{"addr":"localhost:25","from":"exr@bank.kz"},"recipients":[]}`,
+		`{"smtp":{"addr":"localhost:25","from":"exr@bank.kz"},"recipients":[{"email":"not an address"}]}`,
+	} {
+		if _, err := LoadConfig(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadConfig(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 4, h, m, 0, 0, time.Local) }
+	if got := nextRun(at(8, 59), "09:00"); !got.Equal(at(9, 0)) {
+		t.Errorf("before: %v", got)
+	}
+	if got := nextRun(at(9, 0), "09:00"); !got.Equal(at(9, 0).AddDate(0, 0, 1)) {
+		t.Errorf("at: %v", got)
+	}
+}
+
+// smtpStub is a minimal SMTP server that accepts every message.
+type smtpStub struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []stubMessage
+	reject   string // RCPT на этот адрес отклоняется
+}
+
+type stubMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStub{ln: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 stub ESMTP")
+	var msg stubMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch upper := strings.ToUpper(cmd); {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 stub")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			msg = stubMessage{from: strings.Trim(cmd[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			rcpt := strings.Trim(cmd[len("RCPT TO:"):], "<> ")
+			if rcpt == s.reject {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, rcpt)
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestGenerator_SendAll_SMTP(t *testing.T) {
+	stub := newSMTPStub(t)
+	stub.reject = "gone@bank.kz"
+	cfg := DefaultConfig()
+	cfg.SMTP = SMTPConfig{Addr: stub.ln.Addr().String(), From: "exr <exr@bank.kz>"}
+	cfg.Recipients = []Recipient{
+		{Email: "cfo@bank.kz", Currencies: []string{"USD"}},
+		{Email: "gone@bank.kz"},
+		{Email: "Treasury <treasury@bank.kz>", Currencies: []string{"EUR", "USD"}},
+	}
+	svc := newMockService()
+	g := NewGenerator(svc, NewSMTPMailer(cfg.SMTP), cfg, nopLogger{})
+	g.now = func() time.Time { return testNow }
+
+	err := g.SendAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "gone@bank.kz") {
+		t.Fatalf("expected the rejected recipient in error, got %v", err)
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if len(stub.messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(stub.messages))
+	}
+	// Свечи валюты строим один раз на всех получателей: 3 источника × USD, EUR, RUB
+	if svc.calls != 9 {
+		t.Errorf("Candles called %d times, want 9", svc.calls)
+	}
+
+	m := stub.messages[1]
+	if m.from != "exr@bank.kz" || len(m.to) != 1 || m.to[0] != "treasury@bank.kz" {
+		t.Errorf("unexpected envelope %+v", m)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(m.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Курсы валют на 04.03.2025: EUR, USD" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type %q: %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// multipart.Reader сам декодирует quoted-printable
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, p.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("unexpected parts %v", types)
+	}
+	if !strings.Contains(bodies[0], "== EUR/KZT ==") || !strings.Contains(bodies[1], "<td>Halyk Bank</td>") {
+		t.Errorf("unexpected bodies:\n%s\n%s", bodies[0], bodies[1])
+	}
+}
+
+func TestGenerator_SendAll_FailedSection(t *testing.T) {
+	stub := newSMTPStub(t)
+	cfg := DefaultConfig()
+	cfg.SMTP = SMTPConfig{Addr: stub.ln.Addr().String(), From: "exr <exr@bank.kz>"}
+	cfg.Recipients = []Recipient{
+		{Email: "rub@bank.kz", Currencies: []string{"RUB"}},
+		{Email: "cfo@bank.kz", Currencies: []string{"USD"}},
+		{Email: "all@bank.kz", Currencies: []string{"USD", "RUB"}},
+	}
+	svc := newMockService()
+	svc.broken = "RUB"
+	g := NewGenerator(svc, NewSMTPMailer(cfg.SMTP), cfg, nopLogger{})
+	g.now = func() time.Time { return testNow }
+
+	// Не собранная валюта лишает письма только тех, кто её ждёт
+	err := g.SendAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "rub@bank.kz") || !strings.Contains(err.Error(), "all@bank.kz") {
+		t.Fatalf("expected both RUB recipients in error, got %v", err)
+	}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if len(stub.messages) != 1 || stub.messages[0].to[0] != "cfo@bank.kz" {
+		t.Fatalf("messages = %+v, want only cfo@bank.kz", stub.messages)
+	}
+	// Упавшую валюту второй раз не строим: первый источник RUB и 3 источника USD
+	if svc.calls != 4 {
+		t.Errorf("Candles called %d times, want 4", svc.calls)
+	}
+}
@@ -0,0 +1,101 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(templateFuncs).ParseFS(templates, "templates/digest.html"))
+	textTemplate = texttemplate.Must(texttemplate.New("digest.txt").Funcs(templateFuncs).ParseFS(templates, "templates/digest.txt"))
+)
+
+var templateFuncs = map[string]any{
+	"date":    func(t time.Time) string { return t.Format("02.01.2006") },
+	"rate":    rateText,
+	"change":  changeText,
+	"signed":  func(v float64) string { return fmt.Sprintf("%+.2f", v) },
+	"channel": channelLabel,
+	"side":    sideLabel,
+	"offers":  offersText,
+}
+
+// Render renders the digest as an email with plain-text and HTML bodies; the caller sets To.
+func Render(d *Digest) (*Email, error) {
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, d); err != nil {
+		return nil, fmt.Errorf("render text digest: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, d); err != nil {
+		return nil, fmt.Errorf("render html digest: %w", err)
+	}
+	currencies := make([]string, 0, len(d.Sections))
+	for _, s := range d.Sections {
+		currencies = append(currencies, s.Currency)
+	}
+	return &Email{
+		Subject: fmt.Sprintf("Курсы валют на %s: %s", d.Date.Format("02.01.2006"), strings.Join(currencies, ", ")),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// rateText formats a rate like the web page: two decimals, "—" when the side is not quoted.
+func rateText(v float64) string {
+	if v <= 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// changeText formats a change for plain text like RateWithIndicator: " ↗ +0.30%", empty without change.
+func changeText(v float64) string {
+	switch {
+	case v > 0:
+		return fmt.Sprintf(" ↗ +%.2f%%", v)
+	case v < 0:
+		return fmt.Sprintf(" ↘ %.2f%%", v)
+	}
+	return ""
+}
+
+func offersText(offers []Offer) string {
+	if len(offers) == 0 {
+		return "—"
+	}
+	parts := make([]string, 0, len(offers))
+	for _, o := range offers {
+		parts = append(parts, fmt.Sprintf("%s %.2f", o.Name, o.Rate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// channelLabel names a rate channel like the web page.
+func channelLabel(channel string) string {
+	switch channel {
+	case "cash":
+		return "наличные"
+	case "non_cash":
+		return "безналичные"
+	case "card":
+		return "по карте"
+	case "official":
+		return "официальный"
+	}
+	return channel
+}
+
+func sideLabel(side string) string {
+	if side == "sell" {
+		return "продажа"
+	}
+	return "покупка"
+}
@@ -0,0 +1,238 @@
+// Package export writes stored rates as CSV, JSON Lines or XLSX for spreadsheets and analysis
+// tools, streaming them from the repository row by row.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// Format is an export file format.
+type Format string
+
+// Export formats.
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+	XLSX  Format = "xlsx"
+)
+
+// Decimals is the least number of decimal places of an exported rate, so that columns line up;
+// a rate stored with more places keeps all of them.
+const Decimals = 4
+
+// Columns are the exported fields, in file order. Times are RFC 3339 with the offset of the export
+// time zone; rates use a dot and at least Decimals places, empty when a source does not quote the side.
+var Columns = []string{
+	"created_at", "source", "branch", "branch_name", "channel", "currency", "quote", "buy", "sell", "city", "address",
+}
+
+// ParseFormat accepts a format name, case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case CSV, JSONL, XLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("export format %q: %w", s, internalErrors.ErrInvalidArgument)
+}
+
+// ContentType is the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case JSONL:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Service streams stored rates.
+type Service interface {
+	ExportRates(ctx context.Context, filter *exrate.ExchangeRateFilter, fn func(rate *entity.ExchangeRate) error) error
+}
+
+// Writer encodes rates one at a time; Close completes the file.
+type Writer interface {
+	Write(rate *entity.ExchangeRate) error
+	Close() error
+}
+
+// NewWriter starts a file of the format on w, with times in loc.
+func NewWriter(w io.Writer, f Format, loc *time.Location) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w, loc)
+	case JSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w), loc: loc}, nil
+	case XLSX:
+		return newXLSXWriter(w, loc)
+	}
+	return nil, fmt.Errorf("export format %q: %w", f, internalErrors.ErrInvalidArgument)
+}
+
+// Export writes every rate matching the filter to w and returns how many were written.
+// The output is complete only when the error is nil.
+func Export(
+	ctx context.Context,
+	svc Service,
+	filter *exrate.ExchangeRateFilter,
+	w io.Writer,
+	f Format,
+	loc *time.Location,
+) (int, error) {
+	out, err := NewWriter(w, f, loc)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = svc.ExportRates(ctx, filter, func(r *entity.ExchangeRate) error {
+		n++
+		return out.Write(r)
+	})
+	if err != nil {
+		return n, fmt.Errorf("export rates: %w", err)
+	}
+	return n, out.Close()
+}
+
+// ParseTime parses RFC 3339 or a plain date in loc; a plain date used as upper bound covers the whole day.
+func ParseTime(v string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time %q: %w", v, internalErrors.ErrInvalidArgument)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t.UTC(), nil
+}
+
+// decimal formats a stored rate with a dot and at least Decimals places, never dropping stored
+// digits; false if the source did not quote it.
+func decimal(v string) (string, bool) {
+	v = strings.ReplaceAll(strings.TrimSpace(v), ",", ".")
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return "", false
+	}
+	places := Decimals
+	if i := strings.IndexByte(v, '.'); i >= 0 && !strings.ContainsAny(v, "eE") {
+		places = max(places, len(v)-i-1)
+	}
+	return strconv.FormatFloat(f, 'f', places, 64), true
+}
+
+func timestamp(r *entity.ExchangeRate, loc *time.Location) string {
+	return r.CreatedAt.In(loc).Format(time.RFC3339)
+}
+
+// record is a rate as strings in Columns order.
+func record(r *entity.ExchangeRate, loc *time.Location) []string {
+	buy, _ := decimal(r.Buy)
+	sell, _ := decimal(r.Sell)
+	return []string{
+		timestamp(r, loc), r.Source, r.Branch, r.BranchName, r.Channel, r.CurrencyCode, r.Quote(), buy, sell, r.City,
+		r.Address,
+	}
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	loc *time.Location
+}
+
+func newCSVWriter(w io.Writer, loc *time.Location) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), loc: loc}
+	if err := c.w.Write(Columns); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) Write(r *entity.ExchangeRate) error {
+	rec := record(r, c.loc)
+	for i, v := range rec {
+		if Columns[i] != "buy" && Columns[i] != "sell" {
+			rec[i] = csvText(v)
+		}
+	}
+	return c.w.Write(rec)
+}
+
+// csvText prefixes text that a spreadsheet would run as a formula (e.g. a branch named "=HYPERLINK(...)")
+// with an apostrophe, so the cell opens as the text it is.
+func csvText(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlRecord is one line of JSON Lines; every key is always present, missing sides are null.
+type jsonlRecord struct {
+	CreatedAt  string  `json:"created_at"`
+	Source     string  `json:"source"`
+	Branch     string  `json:"branch"`
+	BranchName string  `json:"branch_name"`
+	Channel    string  `json:"channel"`
+	Currency   string  `json:"currency"`
+	Quote      string  `json:"quote"`
+	Buy        *string `json:"buy"`
+	Sell       *string `json:"sell"`
+	City       string  `json:"city"`
+	Address    string  `json:"address"`
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+	loc *time.Location
+}
+
+func (j *jsonlWriter) Write(r *entity.ExchangeRate) error {
+	// Курсы строками: число в JSON потеряло бы нули после точки
+	side := func(v string) *string {
+		if d, ok := decimal(v); ok {
+			return &d
+		}
+		return nil
+	}
+	return j.enc.Encode(jsonlRecord{
+		CreatedAt:  timestamp(r, j.loc),
+		Source:     r.Source,
+		Branch:     r.Branch,
+		BranchName: r.BranchName,
+		Channel:    r.Channel,
+		Currency:   r.CurrencyCode,
+		Quote:      r.Quote(),
+		Buy:        side(r.Buy),
+		Sell:       side(r.Sell),
+		City:       r.City,
+		Address:    r.Address,
+	})
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
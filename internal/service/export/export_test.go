@@ -0,0 +1,228 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+var almaty = time.FixedZone("Asia/Almaty", 5*60*60)
+
+// sliceService отдаёт курсы из среза, как репозиторий по одному
+type sliceService struct {
+	rates  []*entity.ExchangeRate
+	filter *exrate.ExchangeRateFilter
+	err    error
+}
+
+func (s *sliceService) ExportRates(
+	ctx context.Context,
+	filter *exrate.ExchangeRateFilter,
+	fn func(rate *entity.ExchangeRate) error,
+) error {
+	s.filter = filter
+	for _, r := range s.rates {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func testRates() []*entity.ExchangeRate {
+	at := time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC)
+	return []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "497.5", Sell: "506", CreatedAt: at},
+		{
+			Source: "KursKZ", Branch: "1042", BranchName: `Обменник "Центр"`, Channel: "cash", City: "Алматы", Address: "Абая, 1",
+			CurrencyCode: "USD", Buy: "498", Sell: "", CreatedAt: at.Add(time.Hour),
+		},
+		{Source: "CBR", Channel: "official", CurrencyCode: "KZT", QuoteCurrency: "RUB", Buy: "0.19381", Sell: "0.19381", CreatedAt: at},
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	svc := &sliceService{rates: testRates()}
+	filter := &exrate.ExchangeRateFilter{CurrencyCode: "USD"}
+	n, err := Export(context.Background(), svc, filter, &buf, CSV, almaty)
+	if err != nil || n != 3 {
+		t.Fatalf("Export() = %d, %v", n, err)
+	}
+	if svc.filter != filter {
+		t.Error("filter was not passed to the service")
+	}
+	want := `created_at,source,branch,branch_name,channel,currency,quote,buy,sell,city,address
+2024-11-04T14:30:00+05:00,Halyk,,,cash,USD,KZT,497.5000,506.0000,,
+2024-11-04T15:30:00+05:00,KursKZ,1042,"Обменник ""Центр""",cash,USD,KZT,498.0000,,Алматы,"Абая, 1"
+2024-11-04T14:30:00+05:00,CBR,,,official,KZT,RUB,0.19381,0.19381,,
+`
+	if buf.String() != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestExport_CSVFormulaText(t *testing.T) {
+	var buf bytes.Buffer
+	rates := []*entity.ExchangeRate{{
+		Source: "KursKZ", Branch: "7", BranchName: `=HYPERLINK("http://evil","x")`, Channel: "cash", City: "+Алматы",
+		Address: "@Абая 1", CurrencyCode: "USD", Buy: "-1", Sell: "506", CreatedAt: time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC),
+	}}
+	if _, err := Export(context.Background(), &sliceService{rates: rates}, &exrate.ExchangeRateFilter{}, &buf, CSV, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	// Текст, похожий на формулу, открывается как текст; курсы остаются числами
+	want := `2024-11-04T09:30:00Z,KursKZ,7,"'=HYPERLINK(""http://evil"",""x"")",cash,USD,KZT,-1.0000,506.0000,'+Алматы,'@Абая 1` + "\n"
+	if got := strings.SplitN(buf.String(), "\n", 2)[1]; got != want {
+		t.Errorf("csv row:\n%s\nwant:\n%s", got, want)
+	}
+	if csvText("-5") != "'-5" || csvText("Абая") != "Абая" || csvText("") != "" {
+		t.Error("csvText guards only leading formula characters")
+	}
+}
+
+func TestExport_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Export(context.Background(), &sliceService{rates: testRates()}, &exrate.ExchangeRateFilter{}, &buf, JSONL, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	want := `{"created_at":"2024-11-04T10:30:00Z","source":"KursKZ","branch":"1042","branch_name":"Обменник \"Центр\"",` +
+		`"channel":"cash",` +
+		`"currency":"USD","quote":"KZT","buy":"498.0000","sell":null,"city":"Алматы","address":"Абая, 1"}`
+	if lines[1] != want {
+		t.Errorf("line 2:\n%s\nwant:\n%s", lines[1], want)
+	}
+	for _, l := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(l), &m); err != nil || len(m) != len(Columns) {
+			t.Errorf("line %s: %d keys, err %v", l, len(m), err)
+		}
+	}
+}
+
+func TestExport_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Export(context.Background(), &sliceService{rates: testRates()}, &exrate.ExchangeRateFilter{}, &buf, XLSX, almaty); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	var sheet []byte
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		// Каждая часть книги должна быть корректным XML
+		if err = xml.Unmarshal(data, new(struct{})); err != nil {
+			t.Errorf("%s: %v", f.Name, err)
+		}
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = data
+		}
+	}
+
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Style  string `xml:"s,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err = xml.Unmarshal(sheet, &ws); err != nil {
+		t.Fatal(err)
+	}
+	if len(ws.Rows) != 4 {
+		t.Fatalf("got %d rows, want header and 3 rates", len(ws.Rows))
+	}
+	cell := func(row, col int) string {
+		c := ws.Rows[row].Cells[col]
+		if c.Type == "inlineStr" {
+			return c.Inline
+		}
+		return c.Value
+	}
+	if cell(0, 7) != "buy" || ws.Rows[0].Cells[7].Style != xlsxStyleHeader {
+		t.Errorf("header: %+v", ws.Rows[0])
+	}
+	if cell(2, 0) != "2024-11-04T15:30:00+05:00" || cell(2, 3) != `Обменник "Центр"` || cell(2, 10) != "Абая, 1" {
+		t.Errorf("row 2 text: %+v", ws.Rows[2])
+	}
+	if c := ws.Rows[2].Cells[7]; c.Value != "498.0000" || c.Type != "" || c.Style != xlsxStyleDecimal {
+		t.Errorf("buy is not a decimal number: %+v", c)
+	}
+	if c := ws.Rows[2].Cells[8]; c.Value != "" || c.Inline != "" {
+		t.Errorf("missing sell: %+v", c)
+	}
+}
+
+func TestXLSXWriter_TooManyRows(t *testing.T) {
+	x, err := newXLSXWriter(io.Discard, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.rows = xlsxMaxRows - 1
+	if err = x.Write(testRates()[0]); err != nil {
+		t.Fatalf("last row: %v", err)
+	}
+	if err = x.Write(testRates()[0]); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("err = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestExport_Errors(t *testing.T) {
+	boom := errors.New("boom")
+	n, err := Export(context.Background(), &sliceService{rates: testRates(), err: boom}, &exrate.ExchangeRateFilter{}, io.Discard, CSV, time.UTC)
+	if !errors.Is(err, boom) || n != 3 {
+		t.Errorf("service error: %d, %v", n, err)
+	}
+	if _, err = ParseFormat("XLS"); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("ParseFormat(XLS) err = %v", err)
+	}
+	if f, err := ParseFormat("JSONL"); err != nil || f != JSONL || f.ContentType() != "application/x-ndjson" {
+		t.Errorf("ParseFormat(JSONL) = %s, %v", f, err)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"", false, time.Time{}},
+		{"2024-11-04", false, time.Date(2024, 11, 3, 19, 0, 0, 0, time.UTC)},
+		{"2024-11-04", true, time.Date(2024, 11, 4, 18, 59, 59, 999999999, time.UTC)},
+		{"2024-11-04T09:00:00+03:00", true, time.Date(2024, 11, 4, 6, 0, 0, 0, time.UTC)},
+	} {
+		got, err := ParseTime(tc.in, tc.endOfDay, almaty)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseTime(%q, %v) = %v, %v; want %v", tc.in, tc.endOfDay, got, err, tc.want)
+		}
+	}
+	if _, err := ParseTime("04.11.2024", false, almaty); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("bad date: err = %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// xlsxMaxRows is the row limit of an Excel sheet, the header included.
+const xlsxMaxRows = 1 << 20
+
+// ErrTooManyRows is returned by an XLSX writer when the rates do not fit into one sheet.
+var ErrTooManyRows = errors.New("export: more rates than an XLSX sheet holds")
+
+// Cell styles of styles.xml.
+const (
+	xlsxStyleDecimal = "1"
+	xlsxStyleHeader  = "2"
+)
+
+// Минимальный SpreadsheetML: одна страница, строки пишутся сразу в zip, без общей таблицы строк
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="rates" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0000##########"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+const (
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter streams a one-sheet workbook: rates become rows as they come, rates are numbers
+// shown with at least Decimals places and every stored digit, and times are text with their offset,
+// as Excel dates have no zone.
+// Write errors stick in the bufio.Writer and surface from the next Write or Close.
+type xlsxWriter struct {
+	zw   *zip.Writer
+	w    *bufio.Writer
+	loc  *time.Location
+	rows int
+}
+
+func newXLSXWriter(w io.Writer, loc *time.Location) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, p := range xlsxParts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, w: bufio.NewWriter(f), loc: loc}
+	x.w.WriteString(xlsxSheetStart)
+	x.row()
+	for _, c := range Columns {
+		x.text(c, xlsxStyleHeader)
+	}
+	x.w.WriteString("</row>")
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(r *entity.ExchangeRate) error {
+	if x.rows >= xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	x.row()
+	for i, v := range record(r, x.loc) {
+		// Курсы покупки и продажи — числа, остальное — текст: строка ячейки не вычисляется как формула,
+		// поэтому апостроф, как в CSV, здесь не нужен
+		if Columns[i] == "buy" || Columns[i] == "sell" {
+			x.number(v)
+			continue
+		}
+		x.text(v, "")
+	}
+	_, err := x.w.WriteString("</row>")
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.w.WriteString(xlsxSheetEnd)
+	if err := x.w.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+func (x *xlsxWriter) row() {
+	x.rows++
+	x.w.WriteString("<row>")
+}
+
+func (x *xlsxWriter) text(v, style string) {
+	if v == "" && style == "" {
+		x.w.WriteString("<c/>")
+		return
+	}
+	x.w.WriteString("<c")
+	if style != "" {
+		x.w.WriteString(` s="` + style + `"`)
+	}
+	x.w.WriteString(` t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(x.w, []byte(v))
+	x.w.WriteString("</t></is></c>")
+}
+
+func (x *xlsxWriter) number(v string) {
+	if v == "" {
+		x.w.WriteString("<c/>")
+		return
+	}
+	x.w.WriteString(`<c s="` + xlsxStyleDecimal + `"><v>` + v + "</v></c>")
+}
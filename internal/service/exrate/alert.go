@@ -0,0 +1,258 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// CreateAlertRule validates and stores an alert rule; the new rule is not firing.
+// A source-scoped rule must name a configured source by its exact id.
+func (u *ExchangeRateUsecase) CreateAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	rule.CurrencyCode = strings.ToUpper(rule.CurrencyCode)
+	if err := validateAlertRule(rule); err != nil {
+		return err
+	}
+	if _, ok := u.sources[rule.Source]; rule.Source != "" && !ok {
+		return fmt.Errorf("alert rule source %q: %w", rule.Source, internalErrors.ErrInvalidArgument)
+	}
+	rule.Firing, rule.LastFiredAt, rule.CreatedAt = false, time.Time{}, u.now().UTC()
+	return u.repo.AddAlertRule(ctx, rule)
+}
+
+// AlertRules returns all alert rules in creation order.
+func (u *ExchangeRateUsecase) AlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	return u.repo.GetAlertRules(ctx)
+}
+
+// DeleteAlertRule removes the rule together with its events.
+func (u *ExchangeRateUsecase) DeleteAlertRule(ctx context.Context, id int64) error {
+	return u.repo.DeleteAlertRule(ctx, id)
+}
+
+// AlertEvents returns alert events fired in range, newest first.
+func (u *ExchangeRateUsecase) AlertEvents(ctx context.Context, startDate, endDate time.Time) ([]*entity.AlertEvent, error) {
+	return u.repo.GetAlertEvents(ctx, startDate, endDate)
+}
+
+func validateAlertRule(rule *entity.AlertRule) error {
+	invalid := func(what string, v any) error {
+		return fmt.Errorf("alert rule %s %v: %w", what, v, internalErrors.ErrInvalidArgument)
+	}
+	switch {
+	case rule.CurrencyCode == "":
+		return invalid("currency", `""`)
+	case rule.Kind != entity.AlertPrice && rule.Kind != entity.AlertChange && rule.Kind != entity.AlertSpread:
+		return invalid("kind", rule.Kind)
+	case rule.Side != "" && rule.Side != entity.SideBuy && rule.Side != entity.SideSell:
+		return invalid("side", rule.Side)
+	case rule.Kind == entity.AlertPrice && rule.Side == "":
+		return invalid("side", `"" for price rule`)
+	case rule.Kind != entity.AlertChange && rule.Op != entity.AlertBelow && rule.Op != entity.AlertAbove:
+		return invalid("op", rule.Op)
+	case rule.Threshold <= 0 || math.IsInf(rule.Threshold, 0) || math.IsNaN(rule.Threshold):
+		return invalid("threshold", rule.Threshold)
+	case rule.Cooldown < 0:
+		return invalid("cooldown", rule.Cooldown)
+	}
+	if rule.Kind == entity.AlertChange {
+		rule.Op = ""
+	}
+	return nil
+}
+
+// evaluateAlerts checks every rule after a fetch. Price and spread rules look at the latest fresh
+// rates: such a rule fires when its condition starts to hold and stays firing, without new events,
+// until it clears. Change rules look at the rates stored by this fetch and fire on every change
+// beyond the threshold. Either kind fires at most once per cooldown.
+func (u *ExchangeRateUsecase) evaluateAlerts(ctx context.Context, stored []*entity.ExchangeRate) error {
+	rules, err := u.repo.GetAlertRules(ctx)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := u.now().UTC()
+	for _, rule := range rules {
+		var matches []*entity.AlertEvent
+		if rule.Kind == entity.AlertChange {
+			matches = changeMatches(rule, stored)
+		} else if matches, err = u.levelMatches(ctx, rule); err != nil {
+			return fmt.Errorf("alert rule %d: %w", rule.ID, err)
+		}
+
+		cooling := !rule.LastFiredAt.IsZero() && now.Sub(rule.LastFiredAt) < rule.Cooldown
+		attempt := len(matches) > 0 && !cooling && (!rule.Firing || rule.Kind == entity.AlertChange)
+		fired := false
+		if attempt {
+			for _, e := range matches {
+				e.RuleID, e.FiredAt = rule.ID, now
+				// Событие по уже отработанному курсу не повторяется
+				added, err := u.repo.AddAlertEvent(ctx, e)
+				if err != nil {
+					return fmt.Errorf("alert rule %d: %w", rule.ID, err)
+				}
+				if added {
+					fired = true
+					for _, l := range u.alertListeners {
+						l.AlertFired(ctx, rule, e)
+					}
+				}
+			}
+		}
+
+		// Изменение — разовое событие, а уровень держится, пока условие выполняется
+		firing := fired
+		if rule.Kind != entity.AlertChange {
+			firing = len(matches) > 0 && (rule.Firing || attempt)
+		}
+		lastFired := rule.LastFiredAt
+		if fired {
+			lastFired = now
+		}
+		if firing == rule.Firing && !fired {
+			continue
+		}
+		if err = u.repo.UpdateAlertRuleState(ctx, rule.ID, firing, lastFired); err != nil {
+			return fmt.Errorf("alert rule %d: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// levelMatches returns the latest fresh rates meeting a price or spread rule.
+func (u *ExchangeRateUsecase) levelMatches(ctx context.Context, rule *entity.AlertRule) ([]*entity.AlertEvent, error) {
+	// По источнику GetRates отдал бы всю историю серии, а нужен только последний курс
+	rates, err := u.LatestRates(ctx, &ExchangeRateFilter{
+		CurrencyCode: rule.CurrencyCode,
+		Source:       rule.Source,
+		Channel:      rule.Channel,
+	})
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checked := u.checkedAt()
+	var out []*entity.AlertEvent
+	for _, r := range rates {
+		official := r.Channel == entity.ChannelOfficial || r.Source == OfficialSource
+		// Официальный курс учитываем, только если правило задано именно на него
+		if r.Quote() != entity.QuoteKZT || (official && rule.Source == "") || u.stale(r, checked) {
+			continue
+		}
+
+		var value float64
+		var what string
+		if rule.Kind == entity.AlertSpread {
+			s, ok := entity.NewSpread(r)
+			if !ok || official {
+				continue
+			}
+			value, what = s.Pct, "спред"
+		} else {
+			v, ok := sideValue(r, rule.Side)
+			if !ok {
+				continue
+			}
+			value, what = v, alertSideLabel(rule.Side)
+		}
+		if (rule.Op == entity.AlertBelow && value >= rule.Threshold) ||
+			(rule.Op == entity.AlertAbove && value <= rule.Threshold) {
+			continue
+		}
+
+		unit := ""
+		if rule.Kind == entity.AlertSpread {
+			unit = "%"
+		}
+		cmp := "ниже"
+		if rule.Op == entity.AlertAbove {
+			cmp = "выше"
+		}
+		out = append(out, newAlertEvent(r, value,
+			fmt.Sprintf("%s %s: %s %.2f%s %s %g%s", alertSeriesLabel(r), r.CurrencyCode, what, value, unit, cmp,
+				rule.Threshold, unit)))
+	}
+	return out, nil
+}
+
+// changeMatches returns the stored rates that moved beyond a change rule's threshold.
+func changeMatches(rule *entity.AlertRule, stored []*entity.ExchangeRate) []*entity.AlertEvent {
+	var out []*entity.AlertEvent
+	for _, r := range stored {
+		if !strings.EqualFold(r.CurrencyCode, rule.CurrencyCode) ||
+			(rule.Source != "" && r.Source != rule.Source) ||
+			(rule.Channel != "" && !strings.EqualFold(r.Channel, rule.Channel)) {
+			continue
+		}
+
+		// Срабатываем по стороне с наибольшим изменением
+		var best *entity.AlertEvent
+		for _, side := range []string{entity.SideBuy, entity.SideSell} {
+			if rule.Side != "" && side != rule.Side {
+				continue
+			}
+			cur, ok := sideValue(r, side)
+			delta := r.BuyChangePrev
+			if side == entity.SideSell {
+				delta = r.SellChangePrev
+			}
+			prev := cur - delta
+			if !ok || delta == 0 || prev <= 0 {
+				continue
+			}
+			pct := delta / prev * 100
+			if math.Abs(pct) < rule.Threshold || (best != nil && math.Abs(pct) <= math.Abs(best.Value)) {
+				continue
+			}
+			best = newAlertEvent(r, pct, fmt.Sprintf("%s %s: %s %.2f → %.2f (%+.2f%%)",
+				alertSeriesLabel(r), r.CurrencyCode, alertSideLabel(side), prev, cur, pct))
+		}
+		if best != nil {
+			out = append(out, best)
+		}
+	}
+	return out
+}
+
+func newAlertEvent(r *entity.ExchangeRate, value float64, message string) *entity.AlertEvent {
+	return &entity.AlertEvent{
+		Source:       r.Source,
+		Channel:      r.Channel,
+		Branch:       r.Branch,
+		CurrencyCode: r.CurrencyCode,
+		Value:        value,
+		Message:      message,
+		RateAt:       r.CreatedAt,
+	}
+}
+
+// alertSeriesLabel names the series of a rate in alert messages.
+func alertSeriesLabel(r *entity.ExchangeRate) string {
+	label := r.Source
+	if r.Branch != "" {
+		label += " (" + r.BranchLabel() + ")"
+	}
+	if r.Channel != "" && r.Channel != entity.ChannelOfficial {
+		label += ", " + r.Channel
+	}
+	return label
+}
+
+func alertSideLabel(side string) string {
+	if side == entity.SideSell {
+		return "продажа"
+	}
+	return "покупка"
+}
@@ -0,0 +1,71 @@
+package exrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	"github.com/Mi7teR/exr/internal/infrastructure/repository/sqlite"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type quoteDriver struct{ sell string }
+
+func (d *quoteDriver) FetchRates(context.Context) ([]*entity.ExchangeRate, error) {
+	return []*entity.ExchangeRate{{CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "465", Sell: d.sell}}, nil
+}
+
+// Правило по источнику смотрит только на последний курс серии, а не на всю её историю
+func TestExchangeRateUsecase_SourceAlertIgnoresHistory(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo, err := sqlite.NewSQLiteExchangeRateRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, r := range []*entity.ExchangeRate{
+		{Sell: "470", CreatedAt: now.Add(-72 * time.Hour)},
+		{Sell: "495", CreatedAt: now.Add(-time.Hour)},
+	} {
+		r.Source, r.Channel, r.CurrencyCode, r.Buy = "Halyk", entity.ChannelCash, "USD", "465"
+		if err = repo.AddExchangeRate(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	driver := &quoteDriver{sell: "495"}
+	uc := exrate.NewExchangeRateUsecase(repo, map[string]exrate.Driver{"Halyk": driver})
+	uc.SetMaxRateAge(12 * time.Hour)
+	rule := &entity.AlertRule{
+		Kind: entity.AlertPrice, CurrencyCode: "USD", Source: "Halyk", Side: entity.SideSell,
+		Op: entity.AlertBelow, Threshold: 480,
+	}
+	if err = uc.CreateAlertRule(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = uc.AddRates(ctx); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if events, _ := uc.AlertEvents(ctx, time.Time{}, time.Time{}); len(events) != 0 {
+		t.Fatalf("rule fired on history: %s", events[0].Message)
+	}
+
+	driver.sell = "475"
+	if err = uc.AddRates(ctx); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	events, _ := uc.AlertEvents(ctx, time.Time{}, time.Time{})
+	if len(events) != 1 || events[0].Value != 475 {
+		t.Fatalf("got %d events, want one for 475: %+v", len(events), events)
+	}
+}
@@ -0,0 +1,220 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// alertBench is a usecase over an in-memory store of the latest rates with a controllable clock.
+type alertBench struct {
+	uc    *ExchangeRateUsecase
+	repo  *mockRepository
+	now   time.Time
+	quote map[string][2]string // источник -> покупка, продажа
+}
+
+func newAlertBench() *alertBench {
+	b := &alertBench{now: time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC), quote: map[string][2]string{}}
+	// Драйверы в AddRates работают параллельно
+	var mu sync.Mutex
+	latest := map[entity.SeriesKey]*entity.ExchangeRate{}
+	find := func(code, source string) ([]*entity.ExchangeRate, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var out []*entity.ExchangeRate
+		for _, r := range latest {
+			if r.CurrencyCode == code && (source == "" || r.Source == source) {
+				out = append(out, r)
+			}
+		}
+		if len(out) == 0 {
+			return nil, internalErrors.ErrNotFound
+		}
+		return out, nil
+	}
+	b.repo = &mockRepository{
+		getLatestExchangeRateFunc: func(_ context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r, ok := latest[key]; ok {
+				return r, nil
+			}
+			return nil, internalErrors.ErrNotFound
+		},
+		addExchangeRateFunc: func(_ context.Context, r *entity.ExchangeRate) error {
+			mu.Lock()
+			defer mu.Unlock()
+			latest[r.Key()] = r
+			return nil
+		},
+		getRatesByCurrencyCodeFunc: func(_ context.Context, code string, _, _ time.Time) ([]*entity.ExchangeRate, error) {
+			return find(code, "")
+		},
+		getLatestRatesFunc: func(_ context.Context, code, source string) ([]*entity.ExchangeRate, error) {
+			return find(code, source)
+		},
+	}
+	drivers := map[string]Driver{}
+	for _, id := range []string{"Halyk", "Kaspi"} {
+		id := id
+		drivers[id] = &mockDriver{fetchRatesFunc: func(context.Context) ([]*entity.ExchangeRate, error) {
+			q, ok := b.quote[id]
+			if !ok {
+				return nil, nil
+			}
+			return []*entity.ExchangeRate{{CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: q[0], Sell: q[1], CreatedAt: b.now}}, nil
+		}}
+	}
+	b.uc = NewExchangeRateUsecase(b.repo, drivers)
+	b.uc.SetValidationRules(ValidationRules{RejectNonPositive: true})
+	b.uc.now = func() time.Time { return b.now }
+	return b
+}
+
+// fetch runs AddRates after the clock moves on and returns the events it fired.
+func (b *alertBench) fetch(t *testing.T, after time.Duration) []*entity.AlertEvent {
+	t.Helper()
+	b.now = b.now.Add(after)
+	before := len(b.repo.alertEvents)
+	if err := b.uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	return b.repo.alertEvents[before:]
+}
+
+// alertRecorder запоминает события, переданные подписчику
+type alertRecorder struct {
+	rules  []int64
+	events []*entity.AlertEvent
+}
+
+func (r *alertRecorder) AlertFired(_ context.Context, rule *entity.AlertRule, event *entity.AlertEvent) {
+	r.rules = append(r.rules, rule.ID)
+	r.events = append(r.events, event)
+}
+
+func TestExchangeRateUsecase_PriceAlert(t *testing.T) {
+	b := newAlertBench()
+	rule := &entity.AlertRule{
+		Kind: entity.AlertPrice, CurrencyCode: "usd", Side: entity.SideSell, Op: entity.AlertBelow,
+		Threshold: 480, Cooldown: time.Hour,
+	}
+	if err := b.uc.CreateAlertRule(context.Background(), rule); err != nil {
+		t.Fatalf("CreateAlertRule() error = %v", err)
+	}
+	listener := &alertRecorder{}
+	b.uc.AddAlertListener(listener)
+
+	steps := []struct {
+		name       string
+		after      time.Duration
+		halyk      [2]string
+		wantEvents int
+		wantFiring bool
+	}{
+		{name: "above threshold", halyk: [2]string{"475", "485"}},
+		{name: "drops below", after: 5 * time.Minute, halyk: [2]string{"470", "479.5"}, wantEvents: 1, wantFiring: true},
+		{name: "still below: no repeat", after: 5 * time.Minute, halyk: [2]string{"470", "479"}, wantFiring: true},
+		{name: "recovers", after: 5 * time.Minute, halyk: [2]string{"475", "481"}},
+		{name: "below again within cooldown", after: 5 * time.Minute, halyk: [2]string{"470", "478"}},
+		{name: "cooldown over", after: time.Hour, halyk: [2]string{"470", "478"}, wantEvents: 1, wantFiring: true},
+	}
+	for _, st := range steps {
+		b.quote["Halyk"] = st.halyk
+		events := b.fetch(t, st.after)
+		if len(events) != st.wantEvents {
+			t.Fatalf("%s: %d events, want %d", st.name, len(events), st.wantEvents)
+		}
+		if got := b.repo.alertRules[0].Firing; got != st.wantFiring {
+			t.Fatalf("%s: firing = %v, want %v", st.name, got, st.wantFiring)
+		}
+	}
+
+	e := b.repo.alertEvents[0]
+	if e.RuleID != rule.ID || e.Source != "Halyk" || e.Value != 479.5 || !strings.Contains(e.Message, "продажа 479.50 ниже 480") {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if len(listener.events) != 2 || listener.events[0] != e || listener.rules[0] != rule.ID {
+		t.Errorf("listener got %d events, want both firings", len(listener.events))
+	}
+	if !b.repo.alertRules[0].LastFiredAt.Equal(b.now) {
+		t.Errorf("last fired at %v, want %v", b.repo.alertRules[0].LastFiredAt, b.now)
+	}
+}
+
+func TestExchangeRateUsecase_ChangeAndSpreadAlerts(t *testing.T) {
+	b := newAlertBench()
+	ctx := context.Background()
+	change := &entity.AlertRule{Kind: entity.AlertChange, CurrencyCode: "USD", Source: "Halyk", Threshold: 1}
+	spread := &entity.AlertRule{Kind: entity.AlertSpread, CurrencyCode: "USD", Source: "Kaspi", Op: entity.AlertAbove, Threshold: 2}
+	for _, r := range []*entity.AlertRule{change, spread} {
+		if err := b.uc.CreateAlertRule(ctx, r); err != nil {
+			t.Fatalf("CreateAlertRule() error = %v", err)
+		}
+	}
+
+	b.quote["Halyk"] = [2]string{"500", "505"}
+	b.quote["Kaspi"] = [2]string{"500", "505"}
+	if events := b.fetch(t, 0); len(events) != 0 {
+		t.Fatalf("first fetch fired %+v", events)
+	}
+
+	// Halyk: покупка +0.6%, продажа +1.19%; Kaspi: спред 3.9%
+	b.quote["Halyk"] = [2]string{"503", "511"}
+	b.quote["Kaspi"] = [2]string{"495", "515"}
+	events := b.fetch(t, 5*time.Minute)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want change and spread: %+v", len(events), events)
+	}
+	byRule := map[int64]*entity.AlertEvent{}
+	for _, e := range events {
+		byRule[e.RuleID] = e
+	}
+	if e := byRule[change.ID]; e == nil || math.Abs(e.Value-600.0/505) > 1e-9 || !strings.Contains(e.Message, "продажа 505.00 → 511.00") {
+		t.Errorf("unexpected change event %+v", e)
+	}
+	if e := byRule[spread.ID]; e == nil || math.Abs(e.Value-20.0/505*100) > 1e-9 {
+		t.Errorf("unexpected spread event %+v", e)
+	}
+
+	// Изменение срабатывает на каждый скачок, спред — пока не сузится, молчит
+	b.quote["Halyk"] = [2]string{"498", "505"}
+	events = b.fetch(t, 5*time.Minute)
+	if len(events) != 1 || events[0].RuleID != change.ID || events[0].Value >= 0 {
+		t.Fatalf("got %+v, want one drop of Halyk", events)
+	}
+
+	// Небольшое изменение правило сбрасывает
+	b.quote["Halyk"] = [2]string{"499", "506"}
+	if events = b.fetch(t, 5*time.Minute); len(events) != 0 {
+		t.Fatalf("small change fired %+v", events)
+	}
+	if b.repo.alertRules[0].Firing || !b.repo.alertRules[1].Firing {
+		t.Errorf("firing = %v, %v; want change cleared, spread firing", b.repo.alertRules[0].Firing, b.repo.alertRules[1].Firing)
+	}
+}
+
+func TestExchangeRateUsecase_CreateAlertRule_Invalid(t *testing.T) {
+	uc := NewExchangeRateUsecase(&mockRepository{}, map[string]Driver{})
+	for _, rule := range []*entity.AlertRule{
+		{Kind: entity.AlertPrice, Side: entity.SideSell, Op: entity.AlertBelow, Threshold: 480},
+		{Kind: "volume", CurrencyCode: "USD", Threshold: 1},
+		{Kind: entity.AlertPrice, CurrencyCode: "USD", Op: entity.AlertBelow, Threshold: 480},
+		{Kind: entity.AlertSpread, CurrencyCode: "USD", Op: "equal", Threshold: 2},
+		{Kind: entity.AlertChange, CurrencyCode: "USD", Threshold: 0},
+		{Kind: entity.AlertChange, CurrencyCode: "USD", Threshold: 1, Cooldown: -time.Minute},
+		{Kind: entity.AlertChange, CurrencyCode: "USD", Threshold: 1, Source: "Halyk"},
+	} {
+		if err := uc.CreateAlertRule(context.Background(), rule); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+			t.Errorf("CreateAlertRule(%+v) error = %v, want ErrInvalidArgument", rule, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package exrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// Anomaly detection methods.
+const (
+	MethodMAD    = "mad"    // робастный z-score по медиане и медианному абсолютному отклонению
+	MethodZScore = "zscore" // классический z-score по среднему и стандартному отклонению
+)
+
+// madScale makes MAD comparable to a standard deviation for normal data; meanADScale does the
+// same for the mean absolute deviation used when more than half of the window is identical.
+const (
+	madScale    = 0.6745
+	meanADScale = 1.253314
+)
+
+// AnomalyConfig configures the detector run after each ingestion.
+type AnomalyConfig struct {
+	Method       string  `json:"method"`        // mad или zscore
+	Window       int     `json:"window"`        // сколько последних изменений серии сравниваем
+	MinHistory   int     `json:"min_history"`   // меньше изменений — серия слишком молода для выводов
+	LookbackDays int     `json:"lookback_days"` // изменения старше не учитываем
+	Threshold    float64 `json:"threshold"`     // порог |score|
+}
+
+// DefaultAnomalyConfig uses the robust detector: a single glitch does not hide the next one.
+func DefaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{Method: MethodMAD, Window: 50, MinHistory: 10, LookbackDays: 30, Threshold: 5}
+}
+
+// LoadAnomalyConfig reads detector settings from JSON; omitted fields keep their defaults.
+func LoadAnomalyConfig(r io.Reader) (AnomalyConfig, error) {
+	cfg := DefaultAnomalyConfig()
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return AnomalyConfig{}, fmt.Errorf("decode anomaly config: %w", err)
+	}
+	if cfg.Method != MethodMAD && cfg.Method != MethodZScore {
+		return AnomalyConfig{}, fmt.Errorf("anomaly method %q: %w", cfg.Method, internalErrors.ErrInvalidArgument)
+	}
+	return cfg, nil
+}
+
+// SetAnomalyConfig replaces the default detector settings.
+func (u *ExchangeRateUsecase) SetAnomalyConfig(cfg AnomalyConfig) {
+	u.anomaly = cfg
+}
+
+// GetAnomalies returns anomaly events detected in range, newest first.
+func (u *ExchangeRateUsecase) GetAnomalies(ctx context.Context, startDate, endDate time.Time) ([]*entity.Anomaly, error) {
+	return u.repo.GetAnomalies(ctx, startDate, endDate)
+}
+
+// detectAnomalies compares freshly stored rates with the history of their series.
+func (u *ExchangeRateUsecase) detectAnomalies(ctx context.Context, stored []*entity.ExchangeRate) error {
+	for _, rate := range stored {
+		// История — последние изменения той же серии до нового курса, от свежих к старым
+		window, err := u.repo.GetSeriesRates(
+			ctx, rate.Key(), rate.CreatedAt.AddDate(0, 0, -u.anomaly.LookbackDays), rate.CreatedAt, u.anomaly.Window,
+		)
+		if errors.Is(err, internalErrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(window) < u.anomaly.MinHistory {
+			continue
+		}
+
+		for _, side := range []string{entity.SideBuy, entity.SideSell} {
+			a, ok := u.scoreSide(rate, window, side)
+			if !ok {
+				continue
+			}
+			if err = u.repo.AddAnomaly(ctx, a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scoreSide returns an anomaly if the side of the rate moved beyond the threshold.
+func (u *ExchangeRateUsecase) scoreSide(rate *entity.ExchangeRate, window []*entity.ExchangeRate, side string) (*entity.Anomaly, bool) {
+	x, ok := sideValue(rate, side)
+	if !ok {
+		return nil, false
+	}
+	values := make([]float64, 0, len(window))
+	for _, h := range window {
+		if v, ok := sideValue(h, side); ok {
+			values = append(values, v)
+		}
+	}
+	if len(values) < u.anomaly.MinHistory {
+		return nil, false
+	}
+
+	var baseline, score float64
+	if u.anomaly.Method == MethodZScore {
+		baseline, score, ok = zScore(values, x)
+	} else {
+		baseline, score, ok = robustScore(values, x)
+	}
+	if !ok || math.Abs(score) <= u.anomaly.Threshold {
+		return nil, false
+	}
+	return &entity.Anomaly{
+		Source:       rate.Source,
+		Channel:      rate.Channel,
+		Branch:       rate.Branch,
+		BranchName:   rate.BranchName,
+		CurrencyCode: rate.CurrencyCode,
+		Side:         side,
+		Value:        x,
+		Baseline:     baseline,
+		Score:        score,
+		Method:       u.anomaly.Method,
+		RateAt:       rate.CreatedAt,
+		DetectedAt:   u.now(),
+	}, true
+}
+
+func sideValue(r *entity.ExchangeRate, side string) (float64, bool) {
+	s := r.Buy
+	if side == entity.SideSell {
+		s = r.Sell
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil && v > 0
+}
+
+// robustScore is the modified z-score: 0.6745 * (x - median) / MAD. When MAD is zero (курс
+// стоял на месте больше половины окна) the mean absolute deviation stands in for it.
+func robustScore(values []float64, x float64) (baseline, score float64, ok bool) {
+	med := median(values)
+	dev := make([]float64, len(values))
+	var sum float64
+	for i, v := range values {
+		dev[i] = math.Abs(v - med)
+		sum += dev[i]
+	}
+	if mad := median(dev); mad > 0 {
+		return med, madScale * (x - med) / mad, true
+	}
+	if meanAD := sum / float64(len(values)); meanAD > 0 {
+		return med, (x - med) / (meanADScale * meanAD), true
+	}
+	return med, 0, false // все значения одинаковые — отклонение не с чем сравнить
+}
+
+func zScore(values []float64, x float64) (baseline, score float64, ok bool) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(sq / float64(len(values)))
+	if std == 0 {
+		return mean, 0, false
+	}
+	return mean, (x - mean) / std, true
+}
+
+func median(values []float64) float64 {
+	s := append([]float64(nil), values...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
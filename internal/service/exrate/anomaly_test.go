@@ -0,0 +1,137 @@
+package exrate
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// history builds a series of changes, newest first, an hour apart before at.
+func history(at time.Time, buys ...float64) []*entity.ExchangeRate {
+	out := make([]*entity.ExchangeRate, 0, len(buys))
+	for i, b := range buys {
+		out = append(out, &entity.ExchangeRate{
+			CurrencyCode: "USD",
+			Source:       "Halyk",
+			Channel:      entity.ChannelCash,
+			Buy:          strconv.FormatFloat(b, 'f', -1, 64),
+			Sell:         strconv.FormatFloat(b+5, 'f', -1, 64),
+			CreatedAt:    at.Add(-time.Duration(i+1) * time.Hour),
+		})
+	}
+	return out
+}
+
+func TestExchangeRateUsecase_AddRates_Anomalies(t *testing.T) {
+	at := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	calm := []float64{536, 537, 536.5, 537.5, 538, 537, 536, 537, 538, 536.5, 537, 537.5}
+
+	tests := []struct {
+		name     string
+		method   string
+		history  []*entity.ExchangeRate
+		buy      string
+		sell     string
+		wantSide []string
+	}{
+		{name: "calm", history: history(at, calm...), buy: "537.5", sell: "542.5"},
+		{name: "glitch on both sides", history: history(at, calm...), buy: "580", sell: "585", wantSide: []string{"buy", "sell"}},
+		{name: "zscore", method: MethodZScore, history: history(at, calm...), buy: "580", sell: "542", wantSide: []string{"buy"}},
+		{name: "short history", history: history(at, 536, 537, 538), buy: "580", sell: "585"},
+		{
+			name:    "flat window",
+			history: history(at, 537, 537, 537, 537, 537, 537, 537, 537, 537, 537, 537),
+			buy:     "540", sell: "545",
+		},
+		{
+			// больше половины окна одинаковые: MAD = 0, выручает среднее абсолютное отклонение
+			name:    "mostly flat window",
+			history: history(at, 537, 537, 537, 537, 537, 537, 537, 537, 537, 538, 536),
+			buy:     "560", sell: "542",
+			wantSide: []string{"buy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{
+				getSeriesRatesFunc: func(_ context.Context, key entity.SeriesKey, from, to time.Time, limit int) ([]*entity.ExchangeRate, error) {
+					want := entity.SeriesKey{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD"}
+					if key != want || !to.Equal(at) || !from.Equal(at.AddDate(0, 0, -30)) || limit != 50 {
+						t.Errorf("unexpected history query %+v %v..%v limit %d", key, from, to, limit)
+					}
+					if len(tt.history) == 0 {
+						return nil, internalErrors.ErrNotFound
+					}
+					return tt.history, nil
+				},
+				getLatestExchangeRateFunc: func(context.Context, entity.SeriesKey) (*entity.ExchangeRate, error) {
+					return nil, internalErrors.ErrNotFound
+				},
+			}
+			driver := &mockDriver{fetchRatesFunc: func(context.Context) ([]*entity.ExchangeRate, error) {
+				return []*entity.ExchangeRate{{CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: tt.buy, Sell: tt.sell, CreatedAt: at}}, nil
+			}}
+			uc := NewExchangeRateUsecase(repo, map[string]Driver{"Halyk": driver})
+			// Детектор проверяет поведение банка, пороги валидации тут не нужны
+			uc.SetValidationRules(ValidationRules{RejectNonPositive: true})
+			if tt.method != "" {
+				cfg := DefaultAnomalyConfig()
+				cfg.Method = tt.method
+				cfg.Threshold = 3
+				uc.SetAnomalyConfig(cfg)
+			}
+
+			if err := uc.AddRates(context.Background()); err != nil {
+				t.Fatalf("AddRates() error = %v", err)
+			}
+
+			var sides []string
+			for _, a := range repo.anomalies {
+				sides = append(sides, a.Side)
+				if a.Source != "Halyk" || a.CurrencyCode != "USD" || !a.RateAt.Equal(at) || a.Score <= 0 {
+					t.Errorf("unexpected anomaly %+v", a)
+				}
+			}
+			if strings.Join(sides, ",") != strings.Join(tt.wantSide, ",") {
+				t.Errorf("anomaly sides = %v, want %v", sides, tt.wantSide)
+			}
+		})
+	}
+}
+
+func TestRobustScore(t *testing.T) {
+	baseline, score, ok := robustScore([]float64{1, 2, 3, 4, 100}, 3)
+	if !ok || baseline != 3 || score != 0 {
+		t.Errorf("robustScore() = %v, %v, %v; want median 3, score 0", baseline, score, ok)
+	}
+	// Выброс в окне почти не сдвигает базу — в отличие от среднего
+	_, score, _ = robustScore([]float64{1, 2, 3, 4, 100}, 10)
+	if want := madScale * 7; math.Abs(score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", score, want)
+	}
+	if _, _, ok = robustScore([]float64{5, 5, 5}, 6); ok {
+		t.Error("identical window must not be scored")
+	}
+}
+
+func TestLoadAnomalyConfig(t *testing.T) {
+	cfg, err := LoadAnomalyConfig(strings.NewReader(`{"method":"zscore","threshold":3}`))
+	if err != nil {
+		t.Fatalf("LoadAnomalyConfig() error = %v", err)
+	}
+	want := DefaultAnomalyConfig()
+	want.Method, want.Threshold = MethodZScore, 3
+	if cfg != want {
+		t.Errorf("cfg = %+v, want %+v", cfg, want)
+	}
+	if _, err = LoadAnomalyConfig(strings.NewReader(`{"method":"prophet"}`)); err == nil {
+		t.Error("expected error for unknown method")
+	}
+}
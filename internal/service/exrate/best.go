@@ -0,0 +1,137 @@
+package exrate
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+const (
+	// DefaultMaxRateAge is how long a rate is trusted when its source has not confirmed it since.
+	DefaultMaxRateAge = 24 * time.Hour
+	// DefaultBestLimit is the number of offers per side: the best one and its runner-ups.
+	DefaultBestLimit = 3
+)
+
+// SetMaxRateAge sets how old a rate may be to be offered. Rates are stored only when they change,
+// so a rate is fresh if it changed or a poll returned it unchanged within the max age; an office
+// that dropped out of an aggregator's feed goes stale even though the source is still polled.
+func (u *ExchangeRateUsecase) SetMaxRateAge(d time.Duration) {
+	u.maxAge = d
+}
+
+// BestRates returns, per currency and channel, where to buy currency (lowest sell) and where to
+// sell it (highest buy), up to limit offers per side. Official rates are not offers and stale ones
+// are skipped (see SetMaxRateAge).
+func (u *ExchangeRateUsecase) BestRates(
+	ctx context.Context,
+	filter *ExchangeRateFilter,
+	limit int,
+) ([]*entity.BestRate, error) {
+	if limit <= 0 {
+		limit = DefaultBestLimit
+	}
+	rates, err := u.GetRates(ctx, &ExchangeRateFilter{
+		CurrencyCode: filter.CurrencyCode,
+		City:         filter.City,
+		Channel:      filter.Channel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checked := u.checkedAt()
+	type group struct{ currency, channel string }
+	groups := make(map[group]*entity.BestRate)
+	for _, r := range rates {
+		if r.Quote() != entity.QuoteKZT || r.Channel == entity.ChannelOfficial || r.Source == OfficialSource {
+			continue
+		}
+		if u.stale(r, checked) {
+			continue
+		}
+
+		offer := entity.Offer{
+			Source:     r.Source,
+			Branch:     r.Branch,
+			BranchName: r.BranchName,
+			City:       r.City,
+			Address:    r.Address,
+			ChangedAt:  r.CreatedAt,
+			CheckedAt:  checked[r.Key()],
+		}
+		k := group{r.CurrencyCode, r.Channel}
+		b := groups[k]
+		if b == nil {
+			b = &entity.BestRate{CurrencyCode: r.CurrencyCode, Channel: r.Channel}
+			groups[k] = b
+		}
+		// Покупаем валюту по курсу продажи банка, продаём по курсу покупки
+		if sell, err := strconv.ParseFloat(r.Sell, 64); err == nil && sell > 0 {
+			offer.Rate = sell
+			b.Buy = append(b.Buy, offer)
+		}
+		if buy, err := strconv.ParseFloat(r.Buy, 64); err == nil && buy > 0 {
+			offer.Rate = buy
+			b.Sell = append(b.Sell, offer)
+		}
+	}
+
+	out := make([]*entity.BestRate, 0, len(groups))
+	for _, b := range groups {
+		b.Buy = topOffers(b.Buy, limit, func(a, c float64) bool { return a < c })
+		b.Sell = topOffers(b.Sell, limit, func(a, c float64) bool { return a > c })
+		out = append(out, b)
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CurrencyCode != out[j].CurrencyCode {
+			return out[i].CurrencyCode < out[j].CurrencyCode
+		}
+		return out[i].Channel < out[j].Channel
+	})
+	return out, nil
+}
+
+// checkedAt returns a copy of the last poll that returned each series.
+func (u *ExchangeRateUsecase) checkedAt() map[entity.SeriesKey]time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	checked := make(map[entity.SeriesKey]time.Time, len(u.confirmed))
+	for key, at := range u.confirmed {
+		checked[key] = at
+	}
+	return checked
+}
+
+// stale reports whether the rate neither changed nor was confirmed by a poll within the max age.
+func (u *ExchangeRateUsecase) stale(r *entity.ExchangeRate, checked map[entity.SeriesKey]time.Time) bool {
+	if u.maxAge <= 0 {
+		return false
+	}
+	seen := r.CreatedAt
+	if at := checked[r.Key()]; at.After(seen) {
+		seen = at
+	}
+	return u.now().Sub(seen) > u.maxAge
+}
+
+// topOffers orders offers by rate, fresher first on a tie, and keeps the first limit.
+func topOffers(offers []entity.Offer, limit int, better func(a, b float64) bool) []entity.Offer {
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].Rate != offers[j].Rate {
+			return better(offers[i].Rate, offers[j].Rate)
+		}
+		return offers[i].ChangedAt.After(offers[j].ChangedAt)
+	})
+	if len(offers) > limit {
+		offers = offers[:limit]
+	}
+	return offers
+}
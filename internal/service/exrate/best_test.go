@@ -0,0 +1,78 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_BestRates(t *testing.T) {
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{
+		getRatesByCurrencyCodeFunc: func(_ context.Context, code string, _, _ time.Time) ([]*entity.ExchangeRate, error) {
+			if code != "USD" {
+				t.Errorf("currency = %q, want USD", code)
+			}
+			return []*entity.ExchangeRate{
+				{Source: "NBRK", CurrencyCode: "USD", Channel: entity.ChannelOfficial, Buy: "520", Sell: "520", CreatedAt: now},
+				{Source: "Halyk", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "515", Sell: "522", CreatedAt: now.Add(-time.Hour)},
+				{Source: "BCC", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "517", Sell: "521", CreatedAt: now.Add(-2 * time.Hour)},
+				{Source: "Kaspi", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "516", Sell: "521", CreatedAt: now.Add(-30 * time.Minute)},
+				// Курс трёхдневной давности, источник с тех пор не опрашивался
+				{Source: "Jusan", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "530", Sell: "510", CreatedAt: now.AddDate(0, 0, -3)},
+				// Курс не менялся неделю, но источник только что опрошен — он актуален
+				{Source: "Forte", CurrencyCode: "USD", Channel: entity.ChannelNonCash, Buy: "518", Sell: "519", CreatedAt: now.AddDate(0, 0, -7)},
+				{Source: "Halyk", CurrencyCode: "USD", Channel: entity.ChannelNonCash, Buy: "514", Sell: "", CreatedAt: now},
+				// Обменник пропал из выдачи агрегатора: источник опрошен, но курс офиса не подтверждён
+				{Source: "KursKZ", Branch: "1042", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "531", Sell: "509", CreatedAt: now.AddDate(0, 0, -2)},
+			}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+	uc.now = func() time.Time { return now }
+	uc.confirmed[entity.SeriesKey{Source: "Forte", Channel: entity.ChannelNonCash, CurrencyCode: "USD"}] = now.Add(-10 * time.Minute)
+	uc.lastFetched["KursKZ"] = now.Add(-10 * time.Minute)
+
+	got, err := uc.BestRates(context.Background(), &ExchangeRateFilter{CurrencyCode: "USD"}, 2)
+	if err != nil {
+		t.Fatalf("BestRates() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Channel != entity.ChannelCash || got[1].Channel != entity.ChannelNonCash {
+		t.Fatalf("BestRates() = %+v, want cash and non_cash groups", got)
+	}
+
+	cash := got[0]
+	// При равном курсе продажи выше тот, что обновился позже
+	if len(cash.Buy) != 2 || cash.Buy[0].Source != "Kaspi" || cash.Buy[1].Source != "BCC" || cash.Buy[0].Rate != 521 {
+		t.Errorf("cash buy = %+v, want Kaspi then BCC at 521", cash.Buy)
+	}
+	if len(cash.Sell) != 2 || cash.Sell[0].Source != "BCC" || cash.Sell[1].Source != "Kaspi" {
+		t.Errorf("cash sell = %+v, want BCC then Kaspi", cash.Sell)
+	}
+
+	nonCash := got[1]
+	if len(nonCash.Buy) != 1 || nonCash.Buy[0].Source != "Forte" || !nonCash.Buy[0].CheckedAt.Equal(now.Add(-10*time.Minute)) {
+		t.Errorf("non_cash buy = %+v, want Forte confirmed by the last poll", nonCash.Buy)
+	}
+	if len(nonCash.Sell) != 2 || nonCash.Sell[0].Source != "Forte" || nonCash.Sell[1].Source != "Halyk" {
+		t.Errorf("non_cash sell = %+v", nonCash.Sell)
+	}
+
+	// Без ограничения возраста учитываются и давние курсы
+	uc.SetMaxRateAge(0)
+	got, _ = uc.BestRates(context.Background(), &ExchangeRateFilter{CurrencyCode: "USD"}, 0)
+	if got[0].Buy[0].Source != "KursKZ" || got[0].Buy[1].Source != "Jusan" || len(got[0].Buy) != DefaultBestLimit {
+		t.Errorf("cash buy without max age = %+v", got[0].Buy)
+	}
+
+	repo.getRatesByCurrencyCodeFunc = func(context.Context, string, time.Time, time.Time) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{{Source: "NBRK", CurrencyCode: "USD", Channel: entity.ChannelOfficial, Buy: "520", Sell: "520"}}, nil
+	}
+	if _, err = uc.BestRates(context.Background(), &ExchangeRateFilter{CurrencyCode: "USD"}, 0); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("BestRates() error = %v, want ErrNotFound", err)
+	}
+}
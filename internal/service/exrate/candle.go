@@ -0,0 +1,108 @@
+package exrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// maxCandles caps periods per series so an hourly year does not get built by accident.
+const maxCandles = 5000
+
+// CandleFilter selects the candles to build.
+type CandleFilter struct {
+	CurrencyCode string
+	Source       string
+	Channel      string // пусто — все каналы
+	Side         string // buy, sell; пусто — обе стороны
+	Interval     entity.CandleInterval
+	StartDate    time.Time
+	EndDate      time.Time // ноль — сейчас
+}
+
+// Candles returns open/high/low/close candles of the source's currency, sorted by channel, side and
+// start. Periods are cut in local time (TZ). Periods without changes carry the previous close
+// forward, so every period from the first known value to the end of range has a candle.
+func (u *ExchangeRateUsecase) Candles(ctx context.Context, filter *CandleFilter) ([]*entity.Candle, error) {
+	if filter.CurrencyCode == "" || filter.Source == "" || !filter.Interval.Valid() {
+		return nil, fmt.Errorf("candles %+v: %w", *filter, internalErrors.ErrInvalidArgument)
+	}
+	if filter.Side != "" && filter.Side != entity.SideBuy && filter.Side != entity.SideSell {
+		return nil, fmt.Errorf("candle side %q: %w", filter.Side, internalErrors.ErrInvalidArgument)
+	}
+	end := filter.EndDate
+	if end.IsZero() {
+		end = u.now()
+	}
+	loc := time.Local
+
+	candles, err := u.repo.GetCandles(
+		ctx, strings.ToUpper(filter.CurrencyCode), filter.Source, filter.Interval, loc, filter.StartDate, end,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*entity.Candle
+	for i := 0; i < len(candles); {
+		// Серия — подряд идущие свечи одного канала и стороны
+		j := i + 1
+		for j < len(candles) && candles[j].Channel == candles[i].Channel && candles[j].Side == candles[i].Side {
+			j++
+		}
+		series := candles[i:j]
+		i = j
+		if filter.Channel != "" && !strings.EqualFold(series[0].Channel, filter.Channel) {
+			continue
+		}
+		if filter.Side != "" && series[0].Side != filter.Side {
+			continue
+		}
+		filled, err := carryForward(series, filter.Interval, filter.Interval.Start(end, loc))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, filled...)
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// carryForward fills periods without changes up to last with flat candles at the previous close.
+func carryForward(series []*entity.Candle, interval entity.CandleInterval, last time.Time) ([]*entity.Candle, error) {
+	out := make([]*entity.Candle, 0, len(series))
+	prev := series[0]
+	out = append(out, prev)
+	next := 1
+	for at := interval.Next(prev.Start); !at.After(last); at = interval.Next(at) {
+		if len(out) >= maxCandles {
+			return nil, fmt.Errorf("more than %d %s candles: %w", maxCandles, interval, internalErrors.ErrInvalidArgument)
+		}
+		if next < len(series) && !series[next].Start.After(at) {
+			prev = series[next]
+			next++
+			out = append(out, prev)
+			continue
+		}
+		out = append(out, &entity.Candle{
+			Source:       prev.Source,
+			Channel:      prev.Channel,
+			CurrencyCode: prev.CurrencyCode,
+			Side:         prev.Side,
+			Interval:     interval,
+			Start:        at,
+			Open:         prev.Close,
+			High:         prev.Close,
+			Low:          prev.Close,
+			Close:        prev.Close,
+		})
+		prev = out[len(out)-1]
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_Candles(t *testing.T) {
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, time.Local)
+	candle := func(side string, start time.Time, open, high, low, close float64) *entity.Candle {
+		return &entity.Candle{
+			Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Side: side, Interval: entity.CandleDay,
+			Start: start, Open: open, High: high, Low: low, Close: close, Changes: 1,
+		}
+	}
+	repo := &mockRepository{candles: []*entity.Candle{
+		candle("buy", day, 490, 492, 488, 491),
+		candle("buy", day.AddDate(0, 0, 2), 491, 495, 491, 495),
+		candle("sell", day, 495, 499, 495, 496),
+	}}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+	uc.now = func() time.Time { return day.AddDate(0, 0, 3).Add(5 * time.Hour) }
+
+	got, err := uc.Candles(context.Background(), &CandleFilter{
+		CurrencyCode: "usd", Source: "Halyk", Side: "buy", Interval: entity.CandleDay, StartDate: day,
+	})
+	if err != nil {
+		t.Fatalf("Candles() error = %v", err)
+	}
+	// вторник и четверг без изменений — переносим закрытие предыдущего дня
+	wantClose := []float64{491, 491, 495, 495}
+	if len(got) != len(wantClose) {
+		t.Fatalf("got %d candles, want %d", len(got), len(wantClose))
+	}
+	for i, c := range got {
+		if !c.Start.Equal(day.AddDate(0, 0, i)) || c.Close != wantClose[i] || c.Side != "buy" {
+			t.Errorf("candle[%d] = %+v", i, *c)
+		}
+	}
+	if c := got[1]; c.Changes != 0 || c.Open != 491 || c.High != 491 || c.Low != 491 {
+		t.Errorf("carried candle = %+v", *c)
+	}
+
+	got, _ = uc.Candles(context.Background(), &CandleFilter{CurrencyCode: "USD", Source: "Halyk", Interval: entity.CandleDay})
+	if len(got) != 8 || got[4].Side != "sell" || got[7].Close != 496 {
+		t.Errorf("both sides: got %d candles", len(got))
+	}
+
+	for _, f := range []CandleFilter{
+		{CurrencyCode: "USD", Source: "Halyk", Interval: "minute"},
+		{CurrencyCode: "USD", Interval: entity.CandleDay},
+		{CurrencyCode: "USD", Source: "Halyk", Interval: entity.CandleDay, Side: "mid"},
+	} {
+		if _, err = uc.Candles(context.Background(), &f); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+			t.Errorf("Candles(%+v) error = %v, want ErrInvalidArgument", f, err)
+		}
+	}
+
+	repo.candles = []*entity.Candle{{Side: "buy", Interval: entity.CandleHour, Start: day.AddDate(-1, 0, 0), Close: 1}}
+	if _, err = uc.Candles(context.Background(), &CandleFilter{CurrencyCode: "USD", Source: "Halyk", Interval: entity.CandleHour}); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("hourly year: err = %v, want ErrInvalidArgument", err)
+	}
+}
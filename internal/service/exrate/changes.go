@@ -0,0 +1,31 @@
+package exrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Limits of RecentChanges.
+const (
+	DefaultChangesLimit = 50
+	MaxChangesLimit     = 500
+)
+
+// RecentChanges returns the latest stored rates, newest first, each with its change against the previous
+// rate of its series (BuyChangePrev, SellChangePrev). Only CurrencyCode, Source and StartDate of the filter
+// are used; limit below 1 means DefaultChangesLimit, above MaxChangesLimit is cut to it.
+func (u *ExchangeRateUsecase) RecentChanges(
+	ctx context.Context,
+	filter *ExchangeRateFilter,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultChangesLimit
+	case limit > MaxChangesLimit:
+		limit = MaxChangesLimit
+	}
+	return u.repo.GetRateChanges(ctx, strings.ToUpper(filter.CurrencyCode), filter.Source, filter.StartDate, limit)
+}
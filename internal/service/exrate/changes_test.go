@@ -0,0 +1,38 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_RecentChanges(t *testing.T) {
+	base := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	repo := &mockRepository{}
+	for i := 0; i < MaxChangesLimit+DefaultChangesLimit; i++ {
+		repo.changes = append(repo.changes, &entity.ExchangeRate{
+			Source: "Halyk", CurrencyCode: "USD", Buy: fmt.Sprint(500 - i), CreatedAt: base.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	u := NewExchangeRateUsecase(repo, nil)
+	ctx := context.Background()
+
+	got, err := u.RecentChanges(ctx, &ExchangeRateFilter{CurrencyCode: "usd"}, 0)
+	if err != nil || len(got) != DefaultChangesLimit {
+		t.Fatalf("default limit: %d changes, err %v", len(got), err)
+	}
+	if got, _ = u.RecentChanges(ctx, &ExchangeRateFilter{Source: "Halyk"}, MaxChangesLimit+1); len(got) != MaxChangesLimit {
+		t.Errorf("limit over max: %d changes", len(got))
+	}
+	if got, _ = u.RecentChanges(ctx, &ExchangeRateFilter{CurrencyCode: "USD", StartDate: base.Add(-2 * time.Hour)}, 10); len(got) != 3 {
+		t.Errorf("since: %d changes, want 3", len(got))
+	}
+	if _, err = u.RecentChanges(ctx, &ExchangeRateFilter{Source: "BCC"}, 10); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("unknown source: err = %v", err)
+	}
+}
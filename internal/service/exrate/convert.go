@@ -0,0 +1,135 @@
+package exrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// Convert exchanges amount of from into to at every source's latest rates, and at the official
+// NBRK rate. Banks quote against tenge, so a pair of foreign currencies is exchanged in two legs
+// through KZT at the same source and channel: sell from at the bank's buy rate, buy to at its sell
+// rate. Filter narrows sources by city and channel; stale rates are skipped (see SetMaxRateAge).
+func (u *ExchangeRateUsecase) Convert(
+	ctx context.Context,
+	amount float64,
+	from, to string,
+	filter *ExchangeRateFilter,
+) (*entity.Conversion, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if amount <= 0 || from == "" || to == "" || from == to {
+		return nil, fmt.Errorf("convert %v %s to %s: %w", amount, from, to, internalErrors.ErrInvalidArgument)
+	}
+	rates, err := u.GetRates(ctx, &ExchangeRateFilter{City: filter.City, Channel: filter.Channel})
+	if err != nil {
+		return nil, err
+	}
+
+	checked := u.checkedAt()
+	// Курсы одного источника в одном канале — обе ноги двухшагового обмена идут по ним
+	type desk struct{ source, branch, channel string }
+	desks := make(map[desk]map[string]*entity.ExchangeRate)
+	branchNames := make(map[desk]string)
+	var order []desk
+	for _, r := range rates {
+		if r.Quote() != entity.QuoteKZT || u.stale(r, checked) {
+			continue
+		}
+		d := desk{r.Source, r.Branch, r.Channel}
+		if desks[d] == nil {
+			desks[d] = make(map[string]*entity.ExchangeRate)
+			order = append(order, d)
+		}
+		desks[d][r.CurrencyCode] = r
+		branchNames[d] = r.BranchName
+	}
+
+	out := &entity.Conversion{From: from, To: to, Amount: amount}
+	for _, d := range order {
+		official := d.source == OfficialSource || d.channel == entity.ChannelOfficial
+		opt, ok := convertAt(desks[d], amount, from, to, official)
+		if !ok {
+			continue
+		}
+		opt.Source, opt.Branch, opt.Channel = d.source, d.branch, d.channel
+		opt.BranchName = branchNames[d]
+		if official {
+			if d.source == OfficialSource {
+				out.Official = &opt
+			}
+			continue
+		}
+		out.Options = append(out.Options, opt)
+	}
+	if out.Official == nil && len(out.Options) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	sort.SliceStable(out.Options, func(i, j int) bool {
+		if out.Options[i].Result != out.Options[j].Result {
+			return out.Options[i].Result > out.Options[j].Result
+		}
+		return out.Options[i].ChangedAt.After(out.Options[j].ChangedAt)
+	})
+	return out, nil
+}
+
+// convertAt exchanges amount at one desk. The official rate has no spread, its mid is used both ways.
+func convertAt(rates map[string]*entity.ExchangeRate, amount float64, from, to string, official bool) (entity.ConversionOption, bool) {
+	var opt entity.ConversionOption
+	// Первая нога: продаём from за тенге по курсу покупки
+	kzt := amount
+	if from != entity.QuoteKZT {
+		r, ok := rates[from]
+		if !ok {
+			return opt, false
+		}
+		price := sidePrice(r, r.Buy, official)
+		if price <= 0 {
+			return opt, false
+		}
+		kzt = amount * price
+		opt.ChangedAt, opt.City = r.CreatedAt, r.City
+	}
+	// Вторая нога: покупаем to за тенге по курсу продажи
+	result := kzt
+	if to != entity.QuoteKZT {
+		r, ok := rates[to]
+		if !ok {
+			return opt, false
+		}
+		price := sidePrice(r, r.Sell, official)
+		if price <= 0 {
+			return opt, false
+		}
+		result = kzt / price
+		if opt.ChangedAt.IsZero() || r.CreatedAt.Before(opt.ChangedAt) {
+			opt.ChangedAt = r.CreatedAt
+		}
+		if opt.City == "" {
+			opt.City = r.City
+		}
+	}
+	if from != entity.QuoteKZT && to != entity.QuoteKZT {
+		opt.Via = entity.QuoteKZT
+	}
+	opt.Result = result
+	opt.Rate = result / amount
+	return opt, true
+}
+
+func sidePrice(r *entity.ExchangeRate, side string, official bool) float64 {
+	if official {
+		return mid(r)
+	}
+	v, err := strconv.ParseFloat(side, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_Convert(t *testing.T) {
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{
+		getRatesFunc: func(context.Context, time.Time, time.Time) ([]*entity.ExchangeRate, error) {
+			return []*entity.ExchangeRate{
+				{Source: "NBRK", Channel: entity.ChannelOfficial, CurrencyCode: "USD", Buy: "500", Sell: "500", CreatedAt: now},
+				{Source: "NBRK", Channel: entity.ChannelOfficial, CurrencyCode: "EUR", Buy: "540", Sell: "540", CreatedAt: now},
+				{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "495", Sell: "505", CreatedAt: now.Add(-time.Hour)},
+				{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "535", Sell: "548", CreatedAt: now.Add(-2 * time.Hour)},
+				{Source: "BCC", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "498", Sell: "502", CreatedAt: now},
+				{Source: "BCC", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "530", Sell: "545", CreatedAt: now},
+				// Евро по безналу у Halyk есть, доллара нет — двухшаговый обмен в этом канале невозможен
+				{Source: "Halyk", Channel: entity.ChannelNonCash, CurrencyCode: "EUR", Buy: "538", Sell: "544", CreatedAt: now},
+				// Давно не подтверждённый курс не предлагаем
+				{Source: "Jusan", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "560", Sell: "561", CreatedAt: now.AddDate(0, 0, -3)},
+				{Source: "Jusan", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "600", Sell: "601", CreatedAt: now.AddDate(0, 0, -3)},
+			}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+	uc.now = func() time.Time { return now }
+
+	got, err := uc.Convert(context.Background(), 100, "eur", "usd", &ExchangeRateFilter{})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if got.From != "EUR" || got.To != "USD" || got.Official == nil {
+		t.Fatalf("Convert() = %+v", got)
+	}
+	if math.Abs(got.Official.Result-108) > 1e-9 {
+		t.Errorf("official result = %v, want 108", got.Official.Result)
+	}
+
+	want := []struct {
+		source string
+		result float64
+	}{
+		{"Halyk", 100 * 535.0 / 505},
+		{"BCC", 100 * 530.0 / 502},
+	}
+	if len(got.Options) != len(want) {
+		t.Fatalf("got %d options, want %d: %+v", len(got.Options), len(want), got.Options)
+	}
+	for i, w := range want {
+		o := got.Options[i]
+		if o.Source != w.source || math.Abs(o.Result-w.result) > 1e-9 || o.Via != "KZT" {
+			t.Errorf("option[%d] = %s %v via %q, want %s %v via KZT", i, o.Source, o.Result, o.Via, w.source, w.result)
+		}
+	}
+	if !got.Options[0].ChangedAt.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("changed at = %v, want the older leg", got.Options[0].ChangedAt)
+	}
+
+	// Тенге в валюту — одна нога по курсу продажи
+	got, err = uc.Convert(context.Background(), 50500, "KZT", "USD", &ExchangeRateFilter{})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if o := got.Options[0]; o.Source != "BCC" || math.Abs(o.Result-50500.0/502) > 1e-9 || o.Via != "" {
+		t.Errorf("best KZT->USD = %+v", o)
+	}
+
+	if _, err = uc.Convert(context.Background(), 100, "USD", "usd", &ExchangeRateFilter{}); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("same currency: err = %v, want ErrInvalidArgument", err)
+	}
+	if _, err = uc.Convert(context.Background(), 100, "GBP", "USD", &ExchangeRateFilter{}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("unknown currency: err = %v, want ErrNotFound", err)
+	}
+}
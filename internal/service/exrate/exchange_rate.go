@@ -3,6 +3,12 @@ package exrate
 import (
 	"context"
 	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Mi7teR/exr/internal/entity"
@@ -12,10 +18,15 @@ import (
 
 // Driver is an interface that defines the methods that a driver must implement.
 type Driver interface {
-	// FetchRates returns a list of exchange rates.
+	// FetchRates returns a list of exchange rates. When the upstream payload drifted from the
+	// expected shape the error wraps errors.ErrSchemaChanged; rates the driver still extracted
+	// are returned alongside it.
 	FetchRates(ctx context.Context) ([]*entity.ExchangeRate, error)
 }
 
+// schemaDrift counts fetches whose payload drifted from the expected shape, per source.
+var schemaDrift = expvar.NewMap("exr_schema_drift")
+
 // ExchangeRateRepository is an interface that defines the methods that a repository must implement.
 type ExchangeRateRepository interface {
 	// GetExchangeRates returns a list of exchange rates.
@@ -34,25 +45,168 @@ type ExchangeRateRepository interface {
 	GetExchangeRatesBySource(
 		ctx context.Context, source string, startDate, endDate time.Time,
 	) ([]*entity.ExchangeRate, error)
+	// GetLatestExchangeRates returns the latest rate of every series of the currency and source with its
+	// change against the previous rate; empty currency code or source means any.
+	GetLatestExchangeRates(ctx context.Context, currencyCode, source string) ([]*entity.ExchangeRate, error)
 	// AddExchangeRate adds an exchange rate.
 	AddExchangeRate(ctx context.Context, exchangeRate *entity.ExchangeRate) error
-	// GetLatestExchangeRate returns the most recent exchange rate for currency+source.
-	GetLatestExchangeRate(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error)
+	// GetLatestExchangeRate returns the most recent exchange rate of the series.
+	GetLatestExchangeRate(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error)
+	// GetSeriesRates returns up to limit rates of the series stored in [startDate, endDate), newest first.
+	GetSeriesRates(
+		ctx context.Context,
+		key entity.SeriesKey,
+		startDate, endDate time.Time,
+		limit int,
+	) ([]*entity.ExchangeRate, error)
+	// BackfillChannel sets the channel of the source's rates stored without one.
+	BackfillChannel(ctx context.Context, source, channel string) error
+	// QuarantineExchangeRate keeps a rate that failed validation for review.
+	QuarantineExchangeRate(ctx context.Context, rate *entity.QuarantinedRate) error
+	// GetQuarantinedRates returns quarantined rates last seen in range, newest first.
+	GetQuarantinedRates(ctx context.Context, startDate, endDate time.Time) ([]*entity.QuarantinedRate, error)
+	// GetQuarantinedRate returns the quarantined rate by id.
+	GetQuarantinedRate(ctx context.Context, id int64) (*entity.QuarantinedRate, error)
+	// DeleteQuarantinedRate removes a reviewed rate from quarantine.
+	DeleteQuarantinedRate(ctx context.Context, id int64) error
+	// AddAnomaly stores an anomaly event.
+	AddAnomaly(ctx context.Context, anomaly *entity.Anomaly) error
+	// GetAnomalies returns anomaly events detected in range, newest first.
+	GetAnomalies(ctx context.Context, startDate, endDate time.Time) ([]*entity.Anomaly, error)
+	// GetCandles aggregates changes of the source's currency into candles per channel and side;
+	// the rate in force at startDate opens the first period.
+	GetCandles(
+		ctx context.Context,
+		currencyCode, source string,
+		interval entity.CandleInterval,
+		loc *time.Location,
+		startDate, endDate time.Time,
+	) ([]*entity.Candle, error)
+	// AddAlertRule stores a new alert rule and sets its ID.
+	AddAlertRule(ctx context.Context, rule *entity.AlertRule) error
+	// GetAlertRules returns all alert rules in creation order.
+	GetAlertRules(ctx context.Context) ([]*entity.AlertRule, error)
+	// DeleteAlertRule removes the rule together with its events.
+	DeleteAlertRule(ctx context.Context, id int64) error
+	// UpdateAlertRuleState saves whether the rule is firing and when it last fired.
+	UpdateAlertRuleState(ctx context.Context, id int64, firing bool, lastFiredAt time.Time) error
+	// AddAlertEvent stores an alert event; false if the rule already fired on this rate.
+	AddAlertEvent(ctx context.Context, event *entity.AlertEvent) (bool, error)
+	// GetAlertEvents returns alert events fired in range, newest first.
+	GetAlertEvents(ctx context.Context, startDate, endDate time.Time) ([]*entity.AlertEvent, error)
+	// GetSpreadDays aggregates spreads of the currency's KZT rates per source, channel and day;
+	// empty source means every source.
+	GetSpreadDays(
+		ctx context.Context,
+		currencyCode, source string,
+		loc *time.Location,
+		startDate, endDate time.Time,
+	) ([]*entity.SpreadDay, error)
+	// GetRateChanges returns up to limit rates stored since startDate, newest first, with their change
+	// against the previous rate of the series; empty currency code or source means any.
+	GetRateChanges(
+		ctx context.Context,
+		currencyCode, source string,
+		startDate time.Time,
+		limit int,
+	) ([]*entity.ExchangeRate, error)
+	// EachExchangeRate calls fn for every stored rate in range, oldest first, without loading them all;
+	// empty currency code, source or channel means any.
+	EachExchangeRate(
+		ctx context.Context,
+		currencyCode, source, channel string,
+		startDate, endDate time.Time,
+		fn func(rate *entity.ExchangeRate) error,
+	) error
+}
+
+// RatesListener receives the rates stored by AddRates, e.g. to push them to subscribers.
+// It is called synchronously and must not block for long.
+type RatesListener interface {
+	RatesStored(ctx context.Context, rates []*entity.ExchangeRate)
+}
+
+// AlertListener receives alert events as rules fire, e.g. to notify the rule's owner.
+// It is called synchronously and must not block for long.
+type AlertListener interface {
+	AlertFired(ctx context.Context, rule *entity.AlertRule, event *entity.AlertEvent)
 }
 
 // ExchangeRateUsecase represents the usecase for exchange rates.
 type ExchangeRateUsecase struct {
 	repo    ExchangeRateRepository
 	drivers map[string]Driver
+	sources map[string]entity.SourceInfo
+	rules   ValidationRules
+	anomaly AnomalyConfig
+	maxAge  time.Duration // курсы старше не предлагаем в BestRates и Convert
+
+	listeners      []RatesListener
+	alertListeners []AlertListener
+
+	mu          sync.Mutex
+	lastFetched map[string]time.Time           // последний успешный опрос драйвера, для Schedule
+	confirmed   map[entity.SeriesKey]time.Time // последний опрос, вернувший курс серии, для свежести
+	notices     map[string]entity.SchemaNotice
+	now         func() time.Time
 }
 
+// NewExchangeRateUsecase creates usecase; drivers are keyed by source id (see Registry.Drivers).
 func NewExchangeRateUsecase(repo ExchangeRateRepository, drivers map[string]Driver) *ExchangeRateUsecase {
+	sources := make(map[string]entity.SourceInfo, len(drivers))
+	for id, d := range drivers {
+		sources[id] = describe(id, d)
+	}
 	return &ExchangeRateUsecase{
-		repo:    repo,
-		drivers: drivers,
+		repo:        repo,
+		drivers:     drivers,
+		sources:     sources,
+		rules:       DefaultValidationRules(),
+		anomaly:     DefaultAnomalyConfig(),
+		maxAge:      DefaultMaxRateAge,
+		lastFetched: make(map[string]time.Time),
+		confirmed:   make(map[entity.SeriesKey]time.Time),
+		notices:     make(map[string]entity.SchemaNotice),
+		now:         time.Now,
 	}
 }
 
+// AddRatesListener registers a listener of rates stored by AddRates. Not safe to call concurrently with AddRates.
+func (u *ExchangeRateUsecase) AddRatesListener(l RatesListener) {
+	u.listeners = append(u.listeners, l)
+}
+
+// AddAlertListener registers a listener of alert events. Not safe to call concurrently with AddRates.
+func (u *ExchangeRateUsecase) AddAlertListener(l AlertListener) {
+	u.alertListeners = append(u.alertListeners, l)
+}
+
+// Sources returns metadata of all configured sources sorted by id.
+func (u *ExchangeRateUsecase) Sources() []entity.SourceInfo {
+	return sortedSources(u.sources)
+}
+
+// Source returns metadata of the source by id.
+func (u *ExchangeRateUsecase) Source(id string) (entity.SourceInfo, bool) {
+	info, ok := u.sources[id]
+	return info, ok
+}
+
+// BackfillChannels stamps rates stored before channels were recorded with the default channel of
+// their source, so that their series continue with the rates fetched now. Call it once at startup.
+func (u *ExchangeRateUsecase) BackfillChannels(ctx context.Context) error {
+	for _, info := range u.Sources() {
+		channel := info.DefaultChannel()
+		if channel == "" {
+			continue // канал старых курсов многоканального источника не угадать
+		}
+		if err := u.repo.BackfillChannel(ctx, info.ID, channel); err != nil {
+			return fmt.Errorf("backfill channel of %s: %w", info.ID, err)
+		}
+	}
+	return nil
+}
+
 // GetRates returns a list of exchange rates.
 func (u *ExchangeRateUsecase) GetRates(
 	ctx context.Context,
@@ -91,55 +245,292 @@ func (u *ExchangeRateUsecase) GetRates(
 			filter.EndDate,
 		)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return filterRates(rates, filter)
+}
+
+// LatestRates returns the latest rate of every series matching the filter with its change against the
+// previous rate. Unlike GetRates it does so with a source too; the dates of the filter are not used.
+func (u *ExchangeRateUsecase) LatestRates(
+	ctx context.Context,
+	filter *ExchangeRateFilter,
+) ([]*entity.ExchangeRate, error) {
+	rates, err := u.repo.GetLatestExchangeRates(ctx, filter.CurrencyCode, filter.Source)
+	if err != nil {
+		return nil, err
+	}
+	return filterRates(rates, filter)
+}
 
-	return rates, err
+// RatesAt returns the rate of the source's currency in force at the time, one per channel of the source:
+// the last one stored before it. Offices of aggregators are not included.
+func (u *ExchangeRateUsecase) RatesAt(
+	ctx context.Context,
+	currencyCode, source string,
+	at time.Time,
+) ([]*entity.ExchangeRate, error) {
+	channels := u.sources[source].Channels
+	if len(channels) == 0 {
+		channels = []string{""}
+	}
+	var out []*entity.ExchangeRate
+	for _, ch := range channels {
+		key := entity.SeriesKey{Source: source, Channel: ch, CurrencyCode: currencyCode}
+		rates, err := u.repo.GetSeriesRates(ctx, key, time.Time{}, at, 1)
+		if errors.Is(err, internalErrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rates...)
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+// filterRates applies the city and channel of the filter.
+func filterRates(rates []*entity.ExchangeRate, filter *ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+	if filter.City == "" && filter.Channel == "" {
+		return rates, nil
+	}
+
+	// Город фильтруем здесь: курсы без города (по всему банку) действуют в любом городе
+	filtered := make([]*entity.ExchangeRate, 0, len(rates))
+	for _, r := range rates {
+		if filter.City != "" && r.City != "" && !strings.EqualFold(r.City, filter.City) {
+			continue
+		}
+		if filter.Channel != "" && !strings.EqualFold(r.Channel, filter.Channel) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	if len(filtered) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return filtered, nil
 }
 
+// AddRates fetches rates from every driver that is due by its schedule, stores changed ones,
+// checks them for anomalies against the history of their series and evaluates alert rules.
 func (u *ExchangeRateUsecase) AddRates(ctx context.Context) error {
+	var (
+		storedMu sync.Mutex
+		stored   []*entity.ExchangeRate
+	)
 	g := new(errgroup.Group)
-	for _, driver := range u.drivers {
-		driver := driver // захватываем переменную для замыкания
+	for id, driver := range u.drivers {
+		id, driver := id, driver // захватываем переменные для замыкания
+		info := u.sources[id]
+		if !u.due(id, info.Schedule) {
+			continue
+		}
 		g.Go(func() error {
 			rates, err := driver.FetchRates(ctx)
-			if err != nil {
+			switch {
+			case errors.Is(err, internalErrors.ErrSchemaChanged):
+				u.noteSchemaDrift(id, err, len(rates) == 0)
+				if len(rates) == 0 {
+					return fmt.Errorf("%s: %w", id, err)
+				}
+				// формат изменился, но курсы разобраны: сохраняем, уведомление остаётся у админа
+			case err != nil:
 				return err
+			default:
+				u.clearSchemaDrift(id)
 			}
+			u.markFetched(id)
 
 			for _, rate := range rates {
-				// Проверяем последний курс для этой валюты и источника
-				lastRate, err := u.repo.GetLatestExchangeRate(ctx, rate.CurrencyCode, rate.Source)
-				if err != nil {
-					// Если курс не найден (первый запуск), сохраняем новый
-					if errors.Is(err, internalErrors.ErrNotFound) {
-						err = u.repo.AddExchangeRate(ctx, rate)
-						if err != nil {
-							return err
-						}
-						continue
-					}
+				// Источник курса — id из реестра, канал по умолчанию берём из метаданных
+				rate.Source = id
+				if rate.Channel == "" {
+					rate.Channel = info.DefaultChannel()
+				}
+
+				// Проверяем последний курс для этой валюты и источника (и офиса);
+				// если курс не найден (первый запуск), сохраняем новый
+				lastRate, err := u.repo.GetLatestExchangeRate(ctx, rate.Key())
+				switch {
+				case errors.Is(err, internalErrors.ErrNotFound):
+					lastRate = nil
+				case err != nil:
 					// Другие ошибки просто возвращаем
 					return err
+				case lastRate.Buy == rate.Buy && lastRate.Sell == rate.Sell:
+					// Курсы не изменились, пропускаем: опрос лишь подтвердил, что курс ещё действует
+					u.markConfirmed(rate.Key())
+					continue
 				}
 
-				// Сравниваем курсы - если одинаковые, пропускаем сохранение
-				if lastRate.Buy == rate.Buy && lastRate.Sell == rate.Sell {
-					// Курсы не изменились, пропускаем
+				// Подозрительный курс не попадает в exchange_rates, а ждёт разбора в карантине
+				rule, reason, err := u.validate(ctx, rate, lastRate)
+				if err != nil {
+					return err
+				}
+				if rule != "" {
+					now := u.now()
+					err = u.repo.QuarantineExchangeRate(ctx, &entity.QuarantinedRate{
+						Rate: rate, Rule: rule, Reason: reason, FirstSeen: now, LastSeen: now, Hits: 1,
+					})
+					if err != nil {
+						return err
+					}
 					continue
 				}
 
-				// Курсы изменились, сохраняем новый
-				err = u.repo.AddExchangeRate(ctx, rate)
-				if err != nil {
+				// Курс новый или изменился, сохраняем
+				if err = u.store(ctx, rate, lastRate); err != nil {
 					return err
 				}
+				storedMu.Lock()
+				stored = append(stored, rate)
+				storedMu.Unlock()
 			}
 			return nil
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	// Подписчиков уведомляем и аномалии ищем и по курсам драйверов, опрос которых прошёл,
+	// даже если другой драйвер упал
+	err := g.Wait()
+	return errors.Join(err, u.ratesStored(ctx, stored))
+}
+
+// store saves a new or changed rate with its change against the last rate of the series, nil if none.
+func (u *ExchangeRateUsecase) store(ctx context.Context, rate, lastRate *entity.ExchangeRate) error {
+	if lastRate != nil {
+		rate.BuyChangePrev = priceChange(rate.Buy, lastRate.Buy)
+		rate.SellChangePrev = priceChange(rate.Sell, lastRate.Sell)
+	}
+	if err := u.repo.AddExchangeRate(ctx, rate); err != nil {
 		return err
 	}
-
+	u.markConfirmed(rate.Key())
 	return nil
 }
+
+// ratesStored notifies listeners about stored rates, then scores them for anomalies and alert rules.
+func (u *ExchangeRateUsecase) ratesStored(ctx context.Context, stored []*entity.ExchangeRate) error {
+	if len(stored) > 0 {
+		for _, l := range u.listeners {
+			l.RatesStored(ctx, stored)
+		}
+	}
+	var err error
+	if detectErr := u.detectAnomalies(ctx, stored); detectErr != nil {
+		err = errors.Join(err, fmt.Errorf("detect anomalies: %w", detectErr))
+	}
+	if alertErr := u.evaluateAlerts(ctx, stored); alertErr != nil {
+		err = errors.Join(err, fmt.Errorf("evaluate alerts: %w", alertErr))
+	}
+	return err
+}
+
+// priceChange returns cur - prev, or 0 if either value is not a number.
+func priceChange(cur, prev string) float64 {
+	c, errCur := strconv.ParseFloat(cur, 64)
+	p, errPrev := strconv.ParseFloat(prev, 64)
+	if errCur != nil || errPrev != nil {
+		return 0
+	}
+	return c - p
+}
+
+// due reports whether the driver should be polled now.
+func (u *ExchangeRateUsecase) due(id string, schedule time.Duration) bool {
+	if schedule <= 0 {
+		return true
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	last, ok := u.lastFetched[id]
+	return !ok || u.now().Sub(last) >= schedule
+}
+
+func (u *ExchangeRateUsecase) markFetched(id string) {
+	u.mu.Lock()
+	u.lastFetched[id] = u.now()
+	u.mu.Unlock()
+}
+
+func (u *ExchangeRateUsecase) markConfirmed(key entity.SeriesKey) {
+	u.mu.Lock()
+	u.confirmed[key] = u.now()
+	u.mu.Unlock()
+}
+
+// SchemaNotices returns sources whose latest payload drifted from the expected shape, sorted by source.
+func (u *ExchangeRateUsecase) SchemaNotices() []entity.SchemaNotice {
+	u.mu.Lock()
+	out := make([]entity.SchemaNotice, 0, len(u.notices))
+	for _, n := range u.notices {
+		out = append(out, n)
+	}
+	u.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+func (u *ExchangeRateUsecase) noteSchemaDrift(id string, err error, breaking bool) {
+	schemaDrift.Add(id, 1)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	n, ok := u.notices[id]
+	if !ok {
+		n = entity.SchemaNotice{Source: id, Since: now}
+	}
+	n.Detail = err.Error()
+	n.Breaking = breaking
+	n.Count++
+	n.LastSeen = now
+	u.notices[id] = n
+}
+
+// clearSchemaDrift drops the notice once the source answers in the expected shape again.
+func (u *ExchangeRateUsecase) clearSchemaDrift(id string) {
+	u.mu.Lock()
+	delete(u.notices, id)
+	u.mu.Unlock()
+}
+
+// GetQuarantinedRates returns rates that failed validation, last seen in range.
+func (u *ExchangeRateUsecase) GetQuarantinedRates(
+	ctx context.Context,
+	startDate, endDate time.Time,
+) ([]*entity.QuarantinedRate, error) {
+	return u.repo.GetQuarantinedRates(ctx, startDate, endDate)
+}
+
+// ReleaseQuarantinedRate accepts a reviewed rate, e.g. a genuine jump of the official rate.
+// It is stored as of the release, like a freshly polled rate, so subscribers, anomaly
+// detection and alert rules see it and it does not land behind rates stored meanwhile.
+func (u *ExchangeRateUsecase) ReleaseQuarantinedRate(ctx context.Context, id int64) error {
+	q, err := u.repo.GetQuarantinedRate(ctx, id)
+	if err != nil {
+		return err
+	}
+	rate := q.Rate
+	rate.CreatedAt = u.now()
+	lastRate, err := u.repo.GetLatestExchangeRate(ctx, rate.Key())
+	switch {
+	case errors.Is(err, internalErrors.ErrNotFound):
+		lastRate = nil
+	case err != nil:
+		return err
+	}
+	if err = u.store(ctx, rate, lastRate); err != nil {
+		return err
+	}
+	// Удаляем после сохранения: при сбое курс остаётся в карантине, а не теряется
+	if err = u.repo.DeleteQuarantinedRate(ctx, id); err != nil {
+		return err
+	}
+	return u.ratesStored(ctx, []*entity.ExchangeRate{rate})
+}
@@ -6,6 +6,8 @@ import "time"
 type ExchangeRateFilter struct {
 	CurrencyCode string
 	Source       string
+	City         string // пусто — все города
+	Channel      string // пусто — все каналы
 	StartDate    time.Time
 	EndDate      time.Time
 }
@@ -3,6 +3,8 @@ package exrate
 import (
 	"context"
 	"errors"
+	"expvar"
+	"fmt"
 	"testing"
 	"time"
 
@@ -17,7 +19,25 @@ type mockRepository struct {
 	getRatesByCurrencyCodeAndSourceFunc func(ctx context.Context, currencyCode, source string, startDate, endDate time.Time) ([]*entity.ExchangeRate, error)
 	getRatesBySourceFunc                func(ctx context.Context, source string, startDate, endDate time.Time) ([]*entity.ExchangeRate, error)
 	addExchangeRateFunc                 func(ctx context.Context, exchangeRate *entity.ExchangeRate) error
-	getLatestExchangeRateFunc           func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error)
+	getLatestExchangeRateFunc           func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error)
+	getLatestRatesFunc                  func(ctx context.Context, currencyCode, source string) ([]*entity.ExchangeRate, error)
+	getSeriesRatesFunc                  func(ctx context.Context, key entity.SeriesKey, startDate, endDate time.Time, limit int) ([]*entity.ExchangeRate, error)
+	quarantined                         []*entity.QuarantinedRate
+	anomalies                           []*entity.Anomaly
+	candles                             []*entity.Candle
+	spreadDays                          []*entity.SpreadDay
+	changes                             []*entity.ExchangeRate
+	alertRules                          []*entity.AlertRule
+	alertEvents                         []*entity.AlertEvent
+	backfilled                          map[string]string
+}
+
+func (m *mockRepository) BackfillChannel(_ context.Context, source, channel string) error {
+	if m.backfilled == nil {
+		m.backfilled = map[string]string{}
+	}
+	m.backfilled[source] = channel
+	return nil
 }
 
 func (m *mockRepository) GetExchangeRates(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
@@ -48,6 +68,13 @@ func (m *mockRepository) GetExchangeRatesBySource(ctx context.Context, source st
 	return nil, nil
 }
 
+func (m *mockRepository) GetLatestExchangeRates(ctx context.Context, currencyCode, source string) ([]*entity.ExchangeRate, error) {
+	if m.getLatestRatesFunc != nil {
+		return m.getLatestRatesFunc(ctx, currencyCode, source)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
 func (m *mockRepository) AddExchangeRate(ctx context.Context, exchangeRate *entity.ExchangeRate) error {
 	if m.addExchangeRateFunc != nil {
 		return m.addExchangeRateFunc(ctx, exchangeRate)
@@ -55,13 +82,171 @@ func (m *mockRepository) AddExchangeRate(ctx context.Context, exchangeRate *enti
 	return nil
 }
 
-func (m *mockRepository) GetLatestExchangeRate(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+func (m *mockRepository) GetLatestExchangeRate(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 	if m.getLatestExchangeRateFunc != nil {
-		return m.getLatestExchangeRateFunc(ctx, currencyCode, source)
+		return m.getLatestExchangeRateFunc(ctx, key)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockRepository) GetSeriesRates(
+	ctx context.Context,
+	key entity.SeriesKey,
+	startDate, endDate time.Time,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	if m.getSeriesRatesFunc != nil {
+		return m.getSeriesRatesFunc(ctx, key, startDate, endDate, limit)
 	}
 	return nil, internalErrors.ErrNotFound
 }
 
+func (m *mockRepository) QuarantineExchangeRate(ctx context.Context, rate *entity.QuarantinedRate) error {
+	m.quarantined = append(m.quarantined, rate)
+	return nil
+}
+
+func (m *mockRepository) GetQuarantinedRates(ctx context.Context, startDate, endDate time.Time) ([]*entity.QuarantinedRate, error) {
+	return m.quarantined, nil
+}
+
+func (m *mockRepository) GetQuarantinedRate(ctx context.Context, id int64) (*entity.QuarantinedRate, error) {
+	for _, q := range m.quarantined {
+		if q.ID == id {
+			return q, nil
+		}
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockRepository) DeleteQuarantinedRate(ctx context.Context, id int64) error {
+	for i, q := range m.quarantined {
+		if q.ID == id {
+			m.quarantined = append(m.quarantined[:i], m.quarantined[i+1:]...)
+			return nil
+		}
+	}
+	return internalErrors.ErrNotFound
+}
+
+func (m *mockRepository) AddAnomaly(ctx context.Context, anomaly *entity.Anomaly) error {
+	m.anomalies = append(m.anomalies, anomaly)
+	return nil
+}
+
+func (m *mockRepository) GetAnomalies(ctx context.Context, startDate, endDate time.Time) ([]*entity.Anomaly, error) {
+	return m.anomalies, nil
+}
+
+func (m *mockRepository) AddAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	rule.ID = int64(len(m.alertRules) + 1)
+	m.alertRules = append(m.alertRules, rule)
+	return nil
+}
+
+func (m *mockRepository) GetAlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	if len(m.alertRules) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	// Копии, как из базы: состояние меняется только через UpdateAlertRuleState
+	out := make([]*entity.AlertRule, 0, len(m.alertRules))
+	for _, r := range m.alertRules {
+		c := *r
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (m *mockRepository) DeleteAlertRule(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (m *mockRepository) UpdateAlertRuleState(ctx context.Context, id int64, firing bool, lastFiredAt time.Time) error {
+	for _, r := range m.alertRules {
+		if r.ID == id {
+			r.Firing, r.LastFiredAt = firing, lastFiredAt
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) AddAlertEvent(ctx context.Context, event *entity.AlertEvent) (bool, error) {
+	for _, e := range m.alertEvents {
+		if e.RuleID == event.RuleID && e.Source == event.Source && e.Channel == event.Channel &&
+			e.Branch == event.Branch && e.CurrencyCode == event.CurrencyCode && e.RateAt.Equal(event.RateAt) {
+			return false, nil
+		}
+	}
+	m.alertEvents = append(m.alertEvents, event)
+	return true, nil
+}
+
+func (m *mockRepository) GetAlertEvents(ctx context.Context, startDate, endDate time.Time) ([]*entity.AlertEvent, error) {
+	return m.alertEvents, nil
+}
+
+func (m *mockRepository) GetSpreadDays(
+	ctx context.Context,
+	currencyCode, source string,
+	loc *time.Location,
+	startDate, endDate time.Time,
+) ([]*entity.SpreadDay, error) {
+	if len(m.spreadDays) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.spreadDays, nil
+}
+
+func (m *mockRepository) GetRateChanges(
+	ctx context.Context,
+	currencyCode, source string,
+	startDate time.Time,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	var out []*entity.ExchangeRate
+	for _, r := range m.changes {
+		if (currencyCode == "" || r.CurrencyCode == currencyCode) && (source == "" || r.Source == source) &&
+			!r.CreatedAt.Before(startDate) && len(out) < limit {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+func (m *mockRepository) EachExchangeRate(
+	ctx context.Context,
+	currencyCode, source, channel string,
+	startDate, endDate time.Time,
+	fn func(rate *entity.ExchangeRate) error,
+) error {
+	for _, r := range m.changes {
+		if (currencyCode == "" || r.CurrencyCode == currencyCode) && (source == "" || r.Source == source) &&
+			(channel == "" || r.Channel == channel) &&
+			(startDate.IsZero() || !r.CreatedAt.Before(startDate)) && (endDate.IsZero() || !r.CreatedAt.After(endDate)) {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) GetCandles(
+	ctx context.Context,
+	currencyCode, source string,
+	interval entity.CandleInterval,
+	loc *time.Location,
+	startDate, endDate time.Time,
+) ([]*entity.Candle, error) {
+	if len(m.candles) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.candles, nil
+}
+
 // mockDriver реализует интерфейс Driver для тестов
 type mockDriver struct {
 	fetchRatesFunc func(ctx context.Context) ([]*entity.ExchangeRate, error)
@@ -210,6 +395,89 @@ func TestExchangeRateUsecase_GetRates(t *testing.T) {
 	}
 }
 
+func TestExchangeRateUsecase_GetRates_City(t *testing.T) {
+	repo := &mockRepository{
+		getRatesFunc: func(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
+			return []*entity.ExchangeRate{
+				{CurrencyCode: "USD", Source: "Halyk"},
+				{CurrencyCode: "USD", Source: "KursKZ", Branch: "A", City: "Алматы"},
+				{CurrencyCode: "USD", Source: "KursKZ", Branch: "B", City: "Астана"},
+			}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+
+	got, err := uc.GetRates(context.Background(), &ExchangeRateFilter{City: "алматы"})
+	if err != nil {
+		t.Fatalf("GetRates() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetRates() got = %d rates, want 2 (bank-wide + Almaty office)", len(got))
+	}
+	for _, r := range got {
+		if r.City == "Астана" {
+			t.Errorf("rate from another city leaked: %+v", r)
+		}
+	}
+
+	repo.getRatesFunc = func(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{
+			{CurrencyCode: "USD", Source: "Kaspi", Channel: entity.ChannelNonCash},
+			{CurrencyCode: "USD", Source: "KursKZ", Channel: entity.ChannelCash, City: "Алматы"},
+			{CurrencyCode: "USD", Source: "KursKZ", Channel: entity.ChannelCash, City: "Астана"},
+		}, nil
+	}
+	got, err = uc.GetRates(context.Background(), &ExchangeRateFilter{City: "Алматы", Channel: "cash"})
+	if err != nil || len(got) != 1 || got[0].City != "Алматы" {
+		t.Errorf("GetRates() city+channel = %+v, %v; want Almaty cash office", got, err)
+	}
+
+	repo.getRatesFunc = func(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{{CurrencyCode: "USD", Source: "KursKZ", City: "Астана"}}, nil
+	}
+	if _, err = uc.GetRates(context.Background(), &ExchangeRateFilter{City: "Алматы"}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("GetRates() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExchangeRateUsecase_RatesAt(t *testing.T) {
+	at := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	var keys []entity.SeriesKey
+	repo := &mockRepository{
+		getSeriesRatesFunc: func(_ context.Context, key entity.SeriesKey, from, to time.Time, limit int) ([]*entity.ExchangeRate, error) {
+			keys = append(keys, key)
+			if !from.IsZero() || !to.Equal(at) || limit != 1 {
+				t.Errorf("unexpected series query %v..%v limit %d", from, to, limit)
+			}
+			// Карточный курс у банка появился позже начала периода
+			if key.Channel != entity.ChannelCash {
+				return nil, internalErrors.ErrNotFound
+			}
+			return []*entity.ExchangeRate{{Source: key.Source, Channel: key.Channel, CurrencyCode: key.CurrencyCode, Buy: "495"}}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{
+		"Kaspi": &describedDriver{info: entity.SourceInfo{ID: "Kaspi", Channels: []string{entity.ChannelCash, entity.ChannelCard}}},
+	})
+
+	got, err := uc.RatesAt(context.Background(), "USD", "Kaspi", at)
+	if err != nil || len(got) != 1 || got[0].Buy != "495" {
+		t.Fatalf("RatesAt() = %+v, %v; want the cash rate", got, err)
+	}
+	if len(keys) != 2 || keys[1] != (entity.SeriesKey{Source: "Kaspi", Channel: entity.ChannelCard, CurrencyCode: "USD"}) {
+		t.Errorf("queried series %+v, want cash and card", keys)
+	}
+
+	// Источник без каналов — одна серия с пустым каналом
+	keys = nil
+	if _, err = uc.RatesAt(context.Background(), "EUR", "Legacy", at); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("RatesAt() error = %v, want ErrNotFound", err)
+	}
+	if len(keys) != 1 || keys[0].Channel != "" {
+		t.Errorf("queried series %+v, want one without channel", keys)
+	}
+}
+
 func TestExchangeRateUsecase_AddRates(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -220,7 +488,7 @@ func TestExchangeRateUsecase_AddRates(t *testing.T) {
 		{
 			name: "Add new rates successfully (first time)",
 			setupRepo: func(repo *mockRepository) {
-				repo.getLatestExchangeRateFunc = func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+				repo.getLatestExchangeRateFunc = func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 					return nil, internalErrors.ErrNotFound
 				}
 				repo.addExchangeRateFunc = func(ctx context.Context, exchangeRate *entity.ExchangeRate) error {
@@ -245,7 +513,7 @@ func TestExchangeRateUsecase_AddRates(t *testing.T) {
 		{
 			name: "Skip unchanged rates",
 			setupRepo: func(repo *mockRepository) {
-				repo.getLatestExchangeRateFunc = func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+				repo.getLatestExchangeRateFunc = func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 					return &entity.ExchangeRate{
 						CurrencyCode: "USD",
 						Buy:          "490.50",
@@ -274,7 +542,7 @@ func TestExchangeRateUsecase_AddRates(t *testing.T) {
 		{
 			name: "Add changed rates",
 			setupRepo: func(repo *mockRepository) {
-				repo.getLatestExchangeRateFunc = func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+				repo.getLatestExchangeRateFunc = func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 					return &entity.ExchangeRate{
 						CurrencyCode: "USD",
 						Buy:          "490.50",
@@ -317,7 +585,7 @@ func TestExchangeRateUsecase_AddRates(t *testing.T) {
 		{
 			name: "Repository add error",
 			setupRepo: func(repo *mockRepository) {
-				repo.getLatestExchangeRateFunc = func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+				repo.getLatestExchangeRateFunc = func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 					return nil, internalErrors.ErrNotFound
 				}
 				repo.addExchangeRateFunc = func(ctx context.Context, exchangeRate *entity.ExchangeRate) error {
@@ -364,9 +632,122 @@ func TestExchangeRateUsecase_AddRates(t *testing.T) {
 	}
 }
 
+func TestExchangeRateUsecase_AddRates_SchemaDrift(t *testing.T) {
+	var stored int
+	repo := &mockRepository{
+		addExchangeRateFunc: func(ctx context.Context, exchangeRate *entity.ExchangeRate) error {
+			stored++
+			return nil
+		},
+	}
+	drift := fmt.Errorf("%w: unknown data.cash.*.updated", internalErrors.ErrSchemaChanged)
+	var rates []*entity.ExchangeRate
+	var fetchErr error
+	driver := &mockDriver{
+		fetchRatesFunc: func(ctx context.Context) ([]*entity.ExchangeRate, error) {
+			return rates, fetchErr
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{"Forte": driver})
+	before := schemaDriftCount(t, "Forte")
+
+	// Новое поле: курсы разобраны и сохранены, админ видит уведомление
+	rates = []*entity.ExchangeRate{{CurrencyCode: "USD", Buy: "537", Sell: "541"}}
+	fetchErr = drift
+	if err := uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if stored != 1 {
+		t.Errorf("stored = %d, want 1", stored)
+	}
+	notices := uc.SchemaNotices()
+	if len(notices) != 1 || notices[0].Source != "Forte" || notices[0].Breaking {
+		t.Fatalf("SchemaNotices() = %+v, want one tolerated Forte notice", notices)
+	}
+
+	// Ничего не разобрали: ошибка отдельного класса и ломающее уведомление
+	rates = nil
+	err := uc.AddRates(context.Background())
+	if !errors.Is(err, internalErrors.ErrSchemaChanged) {
+		t.Fatalf("AddRates() error = %v, want ErrSchemaChanged", err)
+	}
+	notices = uc.SchemaNotices()
+	if len(notices) != 1 || !notices[0].Breaking || notices[0].Count != 2 {
+		t.Fatalf("SchemaNotices() = %+v, want breaking notice seen twice", notices)
+	}
+	if got := schemaDriftCount(t, "Forte") - before; got != 2 {
+		t.Errorf("exr_schema_drift[Forte] grew by %d, want 2", got)
+	}
+
+	// Ответ снова в ожидаемом формате — уведомление снимается
+	rates = []*entity.ExchangeRate{{CurrencyCode: "USD", Buy: "538", Sell: "541"}}
+	fetchErr = nil
+	if err = uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if notices = uc.SchemaNotices(); len(notices) != 0 {
+		t.Errorf("SchemaNotices() = %+v, want none", notices)
+	}
+}
+
+// recordingListener запоминает курсы, переданные подписчику
+type recordingListener struct {
+	calls [][]*entity.ExchangeRate
+}
+
+func (l *recordingListener) RatesStored(_ context.Context, rates []*entity.ExchangeRate) {
+	l.calls = append(l.calls, rates)
+}
+
+func TestExchangeRateUsecase_AddRates_Listeners(t *testing.T) {
+	last := &entity.ExchangeRate{CurrencyCode: "USD", Source: "Halyk", Buy: "500", Sell: "505"}
+	repo := &mockRepository{
+		getLatestExchangeRateFunc: func(context.Context, entity.SeriesKey) (*entity.ExchangeRate, error) {
+			return last, nil
+		},
+	}
+	buy := "500"
+	driver := &mockDriver{fetchRatesFunc: func(context.Context) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{{CurrencyCode: "USD", Buy: buy, Sell: "505"}}, nil
+	}}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{"Halyk": driver})
+	polled := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return polled }
+	listener := &recordingListener{}
+	uc.AddRatesListener(listener)
+
+	// Курс не изменился — подписчик не вызывается, но опрос подтверждает, что курс действует
+	if err := uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if checked := uc.checkedAt(); len(checked) != 1 || !checked[last.Key()].Equal(polled) {
+		t.Errorf("checkedAt() = %v, want the series confirmed at %v", checked, polled)
+	}
+	buy = "502.5"
+	if err := uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if len(listener.calls) != 1 || len(listener.calls[0]) != 1 {
+		t.Fatalf("listener calls = %v, want one call with the changed rate", listener.calls)
+	}
+	if r := listener.calls[0][0]; r.Source != "Halyk" || r.BuyChangePrev != 2.5 || r.SellChangePrev != 0 {
+		t.Errorf("unexpected rate %+v", r)
+	}
+}
+
+// schemaDriftCount reads the schema drift metric of the source.
+func schemaDriftCount(t *testing.T, source string) int64 {
+	t.Helper()
+	v, ok := schemaDrift.Get(source).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
 func BenchmarkExchangeRateUsecase_AddRates(b *testing.B) {
 	repo := &mockRepository{
-		getLatestExchangeRateFunc: func(ctx context.Context, currencyCode, source string) (*entity.ExchangeRate, error) {
+		getLatestExchangeRateFunc: func(ctx context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
 			return nil, internalErrors.ErrNotFound
 		},
 		addExchangeRateFunc: func(ctx context.Context, exchangeRate *entity.ExchangeRate) error {
@@ -0,0 +1,33 @@
+package exrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// ExportRates streams every stored rate matching the filter to fn, oldest first, without loading the
+// range into memory. City keeps rates of that city and rates without a city, as in GetRates. An error
+// from fn stops the export and is returned.
+func (u *ExchangeRateUsecase) ExportRates(
+	ctx context.Context,
+	filter *ExchangeRateFilter,
+	fn func(rate *entity.ExchangeRate) error,
+) error {
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.EndDate.Before(filter.StartDate) {
+		return fmt.Errorf("export range ends before it starts: %w", internalErrors.ErrInvalidArgument)
+	}
+	return u.repo.EachExchangeRate(ctx,
+		strings.ToUpper(filter.CurrencyCode), filter.Source, strings.ToLower(filter.Channel),
+		filter.StartDate, filter.EndDate,
+		func(r *entity.ExchangeRate) error {
+			if filter.City != "" && r.City != "" && !strings.EqualFold(r.City, filter.City) {
+				return nil
+			}
+			return fn(r)
+		},
+	)
+}
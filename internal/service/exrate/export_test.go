@@ -0,0 +1,47 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_ExportRates(t *testing.T) {
+	base := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	repo := &mockRepository{changes: []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "495", CreatedAt: base},
+		{Source: "KursKZ", Branch: "Обменник", City: "Астана", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "496", CreatedAt: base.Add(time.Hour)},
+		{Source: "KursKZ", Branch: "Обменник", City: "Алматы", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "497", CreatedAt: base.Add(2 * time.Hour)},
+		{Source: "Halyk", Channel: entity.ChannelCard, CurrencyCode: "USD", Buy: "498", CreatedAt: base.Add(3 * time.Hour)},
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "540", CreatedAt: base.Add(4 * time.Hour)},
+	}}
+	u := NewExchangeRateUsecase(repo, nil)
+	ctx := context.Background()
+
+	var got []string
+	collect := func(r *entity.ExchangeRate) error {
+		got = append(got, r.Buy)
+		return nil
+	}
+	err := u.ExportRates(ctx, &ExchangeRateFilter{CurrencyCode: "usd", Channel: "CASH", City: "алматы"}, collect)
+	if err != nil {
+		t.Fatalf("ExportRates() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "495" || got[1] != "497" {
+		t.Errorf("got %v, want [495 497]", got)
+	}
+
+	got = nil
+	if err = u.ExportRates(ctx, &ExchangeRateFilter{StartDate: base.Add(3 * time.Hour)}, collect); err != nil || len(got) != 2 {
+		t.Errorf("from: %v, err %v", got, err)
+	}
+
+	err = u.ExportRates(ctx, &ExchangeRateFilter{StartDate: base, EndDate: base.Add(-time.Hour)}, collect)
+	if !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("reversed range: err = %v, want ErrInvalidArgument", err)
+	}
+}
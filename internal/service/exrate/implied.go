@@ -0,0 +1,96 @@
+package exrate
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// OfficialSource is the source whose KZT rates are treated as official.
+const OfficialSource = "NBRK"
+
+// ImpliedRates derives KZT cross rates from reference sources quoted in a foreign currency.
+// The reference's own KZT quote is preferred for the leg into tenge (CBR publishes KZT/RUB),
+// otherwise the official NBRK rate of the quote currency is used.
+func (u *ExchangeRateUsecase) ImpliedRates(ctx context.Context) ([]*entity.ImpliedRate, error) {
+	latest, err := u.repo.GetExchangeRates(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	official := make(map[string]float64)
+	bySource := make(map[string]map[string]*entity.ExchangeRate)
+	for _, r := range latest {
+		if r.Quote() == entity.QuoteKZT {
+			if r.Source == OfficialSource {
+				official[r.CurrencyCode] = mid(r)
+			}
+			continue
+		}
+		if bySource[r.Source] == nil {
+			bySource[r.Source] = make(map[string]*entity.ExchangeRate)
+		}
+		bySource[r.Source][r.CurrencyCode] = r
+	}
+
+	var out []*entity.ImpliedRate
+	for source, rates := range bySource {
+		for code, r := range rates {
+			if code == entity.QuoteKZT {
+				continue
+			}
+			via := r.Quote()
+			// Сколько тенге стоит единица валюты котировки
+			viaKZT := official[via]
+			if kzt, ok := rates[entity.QuoteKZT]; ok && mid(kzt) > 0 {
+				viaKZT = 1 / mid(kzt)
+			}
+			value := mid(r)
+			if viaKZT == 0 || value == 0 {
+				continue
+			}
+
+			ir := &entity.ImpliedRate{
+				Reference:    source,
+				Via:          via,
+				CurrencyCode: code,
+				Rate:         value * viaKZT,
+				Official:     official[code],
+			}
+			if ir.Official > 0 {
+				ir.DeviationPct = (ir.Rate - ir.Official) / ir.Official * 100
+			}
+			out = append(out, ir)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CurrencyCode != out[j].CurrencyCode {
+			return out[i].CurrencyCode < out[j].CurrencyCode
+		}
+		return out[i].Reference < out[j].Reference
+	})
+	return out, nil
+}
+
+// mid returns the middle of buy and sell; unparsable sides are ignored.
+func mid(r *entity.ExchangeRate) float64 {
+	buy, errBuy := strconv.ParseFloat(r.Buy, 64)
+	sell, errSell := strconv.ParseFloat(r.Sell, 64)
+	switch {
+	case errBuy == nil && errSell == nil:
+		return (buy + sell) / 2
+	case errBuy == nil:
+		return buy
+	case errSell == nil:
+		return sell
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_ImpliedRates(t *testing.T) {
+	repo := &mockRepository{
+		getRatesFunc: func(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
+			return []*entity.ExchangeRate{
+				{Source: "NBRK", CurrencyCode: "USD", Buy: "480", Sell: "480"},
+				{Source: "NBRK", CurrencyCode: "EUR", Buy: "520", Sell: "520"},
+				{Source: "Halyk", CurrencyCode: "USD", Buy: "478", Sell: "484"},
+				// CBR: USD в рублях и тенге в рублях — кросс без НБРК
+				{Source: "CBR", QuoteCurrency: "RUB", CurrencyCode: "USD", Buy: "96", Sell: "96"},
+				{Source: "CBR", QuoteCurrency: "RUB", CurrencyCode: "KZT", Buy: "0.2", Sell: "0.2"},
+				// ECB: USD в евро, евро в тенге берём у НБРК
+				{Source: "ECB", QuoteCurrency: "EUR", CurrencyCode: "USD", Buy: "0.92", Sell: "0.92"},
+				{Source: "ECB", QuoteCurrency: "EUR", CurrencyCode: "GBP", Buy: "1.2", Sell: "1.2"},
+			}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+
+	got, err := uc.ImpliedRates(context.Background())
+	if err != nil {
+		t.Fatalf("ImpliedRates() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("ImpliedRates() got %d rates, want 3", len(got))
+	}
+
+	want := []struct {
+		ref, code string
+		rate, dev float64
+	}{
+		{"ECB", "GBP", 624, 0},
+		{"CBR", "USD", 480, 0},
+		{"ECB", "USD", 478.4, (478.4 - 480) / 480 * 100},
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Reference != w.ref || g.CurrencyCode != w.code {
+			t.Fatalf("got[%d] = %s %s, want %s %s", i, g.Reference, g.CurrencyCode, w.ref, w.code)
+		}
+		if math.Abs(g.Rate-w.rate) > 1e-9 || math.Abs(g.DeviationPct-w.dev) > 1e-9 {
+			t.Errorf("got[%d] rate=%v dev=%v, want rate=%v dev=%v", i, g.Rate, g.DeviationPct, w.rate, w.dev)
+		}
+	}
+	if got[0].Official != 0 {
+		t.Errorf("GBP has no official rate, got %v", got[0].Official)
+	}
+
+	repo.getRatesFunc = func(ctx context.Context, startDate, endDate time.Time) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{{Source: "Halyk", CurrencyCode: "USD", Buy: "478", Sell: "484"}}, nil
+	}
+	if _, err = uc.ImpliedRates(context.Background()); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("ImpliedRates() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,76 @@
+package exrate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// DescribedDriver is a driver that declares metadata about its source.
+type DescribedDriver interface {
+	Driver
+	// Info returns the source metadata; Info().ID is used as the driver id and rate Source.
+	Info() entity.SourceInfo
+}
+
+// Registry keeps drivers together with their source metadata.
+type Registry struct {
+	drivers map[string]Driver
+	infos   map[string]entity.SourceInfo
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		drivers: make(map[string]Driver),
+		infos:   make(map[string]entity.SourceInfo),
+	}
+}
+
+// Register adds a driver under the id it declares.
+func (r *Registry) Register(d DescribedDriver) error {
+	info := d.Info()
+	if info.ID == "" {
+		return fmt.Errorf("driver %T: empty id", d)
+	}
+	if _, ok := r.drivers[info.ID]; ok {
+		return fmt.Errorf("duplicate driver id %q", info.ID)
+	}
+	r.drivers[info.ID] = d
+	r.infos[info.ID] = info
+	return nil
+}
+
+// Drivers returns registered drivers by id.
+func (r *Registry) Drivers() map[string]Driver {
+	out := make(map[string]Driver, len(r.drivers))
+	for id, d := range r.drivers {
+		out[id] = d
+	}
+	return out
+}
+
+// Sources returns metadata of registered sources sorted by id.
+func (r *Registry) Sources() []entity.SourceInfo {
+	return sortedSources(r.infos)
+}
+
+// describe returns metadata of the driver registered under id; drivers without metadata get a minimal one.
+func describe(id string, d Driver) entity.SourceInfo {
+	if dd, ok := d.(DescribedDriver); ok {
+		info := dd.Info()
+		info.ID = id
+		return info
+	}
+	return entity.SourceInfo{ID: id, Name: id, Kind: entity.SourceKindBank}
+}
+
+func sortedSources(infos map[string]entity.SourceInfo) []entity.SourceInfo {
+	out := make([]entity.SourceInfo, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,127 @@
+package exrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// describedDriver — mockDriver с метаданными источника
+type describedDriver struct {
+	mockDriver
+	info entity.SourceInfo
+}
+
+func (d *describedDriver) Info() entity.SourceInfo { return d.info }
+
+func TestRegistry_Register(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(&describedDriver{info: entity.SourceInfo{ID: "Halyk", Name: "Halyk Bank"}}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := reg.Register(&describedDriver{info: entity.SourceInfo{ID: "Halyk"}}); err == nil {
+		t.Error("Register() duplicate id must fail")
+	}
+	if err := reg.Register(&describedDriver{}); err == nil {
+		t.Error("Register() empty id must fail")
+	}
+	if err := reg.Register(&describedDriver{info: entity.SourceInfo{ID: "Forte"}}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	sources := reg.Sources()
+	if len(sources) != 2 || sources[0].ID != "Forte" || sources[1].ID != "Halyk" {
+		t.Errorf("Sources() = %+v, want Forte, Halyk", sources)
+	}
+	if len(reg.Drivers()) != 2 {
+		t.Errorf("Drivers() = %d, want 2", len(reg.Drivers()))
+	}
+}
+
+func TestExchangeRateUsecase_BackfillChannels(t *testing.T) {
+	repo := &mockRepository{}
+	drivers := map[string]Driver{
+		"HomeKZ": &describedDriver{info: entity.SourceInfo{ID: "HomeKZ", Channels: []string{entity.ChannelCash}}},
+		"Kaspi": &describedDriver{info: entity.SourceInfo{
+			ID: "Kaspi", Channels: []string{entity.ChannelCash, entity.ChannelCard},
+		}},
+		"Plain": &mockDriver{},
+	}
+	uc := NewExchangeRateUsecase(repo, drivers)
+	if err := uc.BackfillChannels(context.Background()); err != nil {
+		t.Fatalf("BackfillChannels() error = %v", err)
+	}
+	if len(repo.backfilled) != 1 || repo.backfilled["HomeKZ"] != entity.ChannelCash {
+		t.Errorf("backfilled %v, want only the single-channel source", repo.backfilled)
+	}
+}
+
+func TestExchangeRateUsecase_AddRates_UsesRegistry(t *testing.T) {
+	var stored []*entity.ExchangeRate
+	repo := &mockRepository{
+		addExchangeRateFunc: func(ctx context.Context, rate *entity.ExchangeRate) error {
+			stored = append(stored, rate)
+			return nil
+		},
+	}
+	fetches := 0
+	d := &describedDriver{info: entity.SourceInfo{
+		ID: "HomeKZ", Name: "Home Credit Bank", Channels: []string{entity.ChannelCash}, Schedule: time.Hour,
+	}}
+	d.fetchRatesFunc = func(ctx context.Context) ([]*entity.ExchangeRate, error) {
+		fetches++
+		return []*entity.ExchangeRate{
+			{CurrencyCode: "USD", Buy: "480", Sell: "485", Source: "Home"},
+			{CurrencyCode: "EUR", Buy: "520", Sell: "530", Source: "Home", Channel: entity.ChannelCard},
+		}, nil
+	}
+	plain := &mockDriver{fetchRatesFunc: func(ctx context.Context) ([]*entity.ExchangeRate, error) {
+		return nil, nil
+	}}
+
+	reg := NewRegistry()
+	if err := reg.Register(d); err != nil {
+		t.Fatal(err)
+	}
+	drivers := reg.Drivers()
+	drivers["Plain"] = plain
+	uc := NewExchangeRateUsecase(repo, drivers)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	if info, ok := uc.Source("HomeKZ"); !ok || info.DisplayName() != "Home Credit Bank" {
+		t.Errorf("Source(HomeKZ) = %+v, %v", info, ok)
+	}
+	if info, _ := uc.Source("Plain"); info.DisplayName() != "Plain" || info.Kind != entity.SourceKindBank {
+		t.Errorf("driver without metadata must get a minimal one, got %+v", info)
+	}
+
+	if err := uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("stored %d rates, want 2", len(stored))
+	}
+	for _, r := range stored {
+		if r.Source != "HomeKZ" {
+			t.Errorf("Source = %q, want registry id HomeKZ", r.Source)
+		}
+	}
+	if stored[0].Channel != entity.ChannelCash || stored[1].Channel != entity.ChannelCard {
+		t.Errorf("channels = %q, %q; want default cash and explicit card", stored[0].Channel, stored[1].Channel)
+	}
+
+	// До истечения Schedule драйвер не опрашивается повторно
+	now = now.Add(30 * time.Minute)
+	_ = uc.AddRates(context.Background())
+	if fetches != 1 {
+		t.Errorf("fetches = %d before schedule elapsed, want 1", fetches)
+	}
+	now = now.Add(30 * time.Minute)
+	_ = uc.AddRates(context.Background())
+	if fetches != 2 {
+		t.Errorf("fetches = %d after schedule elapsed, want 2", fetches)
+	}
+}
@@ -0,0 +1,155 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// DefaultVolatileMovePct is the daily range of the official rate, in percent, that makes a day volatile.
+const DefaultVolatileMovePct = 0.5
+
+// SpreadFilter selects the spread history to build.
+type SpreadFilter struct {
+	CurrencyCode string
+	Source       string // пусто — все источники
+	Channel      string // пусто — все каналы
+	StartDate    time.Time
+	EndDate      time.Time
+	// VolatileMovePct overrides DefaultVolatileMovePct when positive.
+	VolatileMovePct float64
+}
+
+// Spreads ranks the latest rates by spread, narrowest first. Official and stale rates are left out
+// (see SetMaxRateAge).
+func (u *ExchangeRateUsecase) Spreads(ctx context.Context, filter *ExchangeRateFilter) ([]entity.Spread, error) {
+	rates, err := u.GetRates(ctx, &ExchangeRateFilter{
+		CurrencyCode: filter.CurrencyCode,
+		City:         filter.City,
+		Channel:      filter.Channel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checked := u.checkedAt()
+	var out []entity.Spread
+	for _, r := range rates {
+		if r.Quote() != entity.QuoteKZT || r.Channel == entity.ChannelOfficial || r.Source == OfficialSource ||
+			u.stale(r, checked) {
+			continue
+		}
+		if s, ok := entity.NewSpread(r); ok {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].CurrencyCode != out[j].CurrencyCode {
+			return out[i].CurrencyCode < out[j].CurrencyCode
+		}
+		return out[i].Pct < out[j].Pct
+	})
+	return out, nil
+}
+
+// SpreadHistory returns daily spreads of banks over the range and the days the official rate moved
+// at least the volatile threshold, listing series that quoted wider than on calm days of the range.
+// Days are cut in local time (TZ).
+func (u *ExchangeRateUsecase) SpreadHistory(ctx context.Context, filter *SpreadFilter) (*entity.SpreadHistory, error) {
+	if filter.CurrencyCode == "" {
+		return nil, fmt.Errorf("spread history without currency: %w", internalErrors.ErrInvalidArgument)
+	}
+	currency := strings.ToUpper(filter.CurrencyCode)
+	end := filter.EndDate
+	if end.IsZero() {
+		end = u.now()
+	}
+	days, err := u.repo.GetSpreadDays(ctx, currency, filter.Source, time.Local, filter.StartDate, end)
+	if err != nil {
+		return nil, err
+	}
+	out := &entity.SpreadHistory{}
+	for _, d := range days {
+		if filter.Channel == "" || strings.EqualFold(d.Channel, filter.Channel) {
+			out.Days = append(out.Days, d)
+		}
+	}
+	if len(out.Days) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+
+	// Дневной размах официального курса — мерило волатильности рынка
+	official, err := u.Candles(ctx, &CandleFilter{
+		CurrencyCode: currency,
+		Source:       OfficialSource,
+		Side:         entity.SideBuy,
+		Interval:     entity.CandleDay,
+		StartDate:    filter.StartDate,
+		EndDate:      end,
+	})
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	threshold := filter.VolatileMovePct
+	if threshold <= 0 {
+		threshold = DefaultVolatileMovePct
+	}
+	volatile := make(map[time.Time]*entity.VolatileDay)
+	for _, c := range official {
+		if c.Open <= 0 {
+			continue
+		}
+		if move := (c.High - c.Low) / c.Open * 100; move >= threshold {
+			v := &entity.VolatileDay{Day: c.Start, OfficialMovePct: move}
+			volatile[c.Start] = v
+			out.VolatileDays = append(out.VolatileDays, v)
+		}
+	}
+	if len(volatile) == 0 {
+		return out, nil
+	}
+
+	type series struct{ source, channel string }
+	sum := make(map[series]float64)
+	n := make(map[series]int)
+	for _, d := range out.Days {
+		if _, ok := volatile[d.Day]; !ok {
+			k := series{d.Source, d.Channel}
+			sum[k] += d.AvgPct
+			n[k]++
+		}
+	}
+	for _, d := range out.Days {
+		v, ok := volatile[d.Day]
+		k := series{d.Source, d.Channel}
+		if !ok || n[k] == 0 {
+			continue
+		}
+		if baseline := sum[k] / float64(n[k]); d.AvgPct > baseline {
+			v.Widened = append(v.Widened, entity.SpreadWidening{
+				Source:      d.Source,
+				Channel:     d.Channel,
+				AvgPct:      d.AvgPct,
+				BaselinePct: baseline,
+			})
+		}
+	}
+	for _, v := range out.VolatileDays {
+		sort.Slice(v.Widened, func(i, j int) bool {
+			return v.Widened[i].AvgPct-v.Widened[i].BaselinePct > v.Widened[j].AvgPct-v.Widened[j].BaselinePct
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_Spreads(t *testing.T) {
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepository{
+		getRatesFunc: func(context.Context, time.Time, time.Time) ([]*entity.ExchangeRate, error) {
+			return []*entity.ExchangeRate{
+				{Source: "NBRK", Channel: entity.ChannelOfficial, CurrencyCode: "USD", Buy: "500", Sell: "500", CreatedAt: now},
+				{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "495", Sell: "505", CreatedAt: now},
+				{Source: "BCC", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "499", Sell: "501", CreatedAt: now},
+				{Source: "Kaspi", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "499", Sell: "", CreatedAt: now},
+				{Source: "Jusan", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "480", Sell: "520", CreatedAt: now.AddDate(0, 0, -3)},
+				{Source: "CBR", QuoteCurrency: "RUB", CurrencyCode: "USD", Buy: "96", Sell: "97", CreatedAt: now},
+			}, nil
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+	uc.now = func() time.Time { return now }
+
+	got, err := uc.Spreads(context.Background(), &ExchangeRateFilter{})
+	if err != nil {
+		t.Fatalf("Spreads() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Source != "BCC" || got[1].Source != "Halyk" {
+		t.Fatalf("Spreads() = %+v, want BCC then Halyk", got)
+	}
+	if got[1].Abs != 10 || math.Abs(got[1].Pct-2) > 1e-9 {
+		t.Errorf("Halyk spread = %v / %v%%, want 10 / 2%%", got[1].Abs, got[1].Pct)
+	}
+}
+
+func TestExchangeRateUsecase_SpreadHistory(t *testing.T) {
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, time.Local)
+	spread := func(source string, offset int, pct float64) *entity.SpreadDay {
+		return &entity.SpreadDay{Source: source, Channel: "cash", CurrencyCode: "USD", Day: day.AddDate(0, 0, offset), AvgPct: pct}
+	}
+	repo := &mockRepository{
+		spreadDays: []*entity.SpreadDay{
+			spread("BCC", 0, 0.4), spread("BCC", 1, 0.4), spread("BCC", 2, 0.5),
+			spread("Halyk", 0, 1), spread("Halyk", 1, 1.2), spread("Halyk", 2, 3),
+		},
+		// НБРК: спокойные понедельник и вторник, в среду курс сдвинулся на 2%
+		candles: []*entity.Candle{
+			{Side: "buy", Interval: entity.CandleDay, Start: day, Open: 500, High: 500, Low: 500, Close: 500},
+			{Side: "buy", Interval: entity.CandleDay, Start: day.AddDate(0, 0, 1), Open: 500, High: 501, Low: 500, Close: 501},
+			{Side: "buy", Interval: entity.CandleDay, Start: day.AddDate(0, 0, 2), Open: 501, High: 511.02, Low: 501, Close: 511.02},
+		},
+	}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{})
+	uc.now = func() time.Time { return day.AddDate(0, 0, 2).Add(12 * time.Hour) }
+
+	got, err := uc.SpreadHistory(context.Background(), &SpreadFilter{CurrencyCode: "usd", StartDate: day})
+	if err != nil {
+		t.Fatalf("SpreadHistory() error = %v", err)
+	}
+	if len(got.Days) != 6 {
+		t.Errorf("got %d spread days, want 6", len(got.Days))
+	}
+	if len(got.VolatileDays) != 1 || !got.VolatileDays[0].Day.Equal(day.AddDate(0, 0, 2)) {
+		t.Fatalf("volatile days = %+v, want Wednesday only", got.VolatileDays)
+	}
+	v := got.VolatileDays[0]
+	if math.Abs(v.OfficialMovePct-2) > 1e-9 {
+		t.Errorf("official move = %v, want 2%%", v.OfficialMovePct)
+	}
+	if len(v.Widened) != 2 || v.Widened[0].Source != "Halyk" || math.Abs(v.Widened[0].BaselinePct-1.1) > 1e-9 {
+		t.Errorf("widened = %+v, want Halyk first with baseline 1.1", v.Widened)
+	}
+
+	// Порог выше движения — волатильных дней нет
+	got, _ = uc.SpreadHistory(context.Background(), &SpreadFilter{CurrencyCode: "USD", VolatileMovePct: 5})
+	if len(got.VolatileDays) != 0 {
+		t.Errorf("volatile days with 5%% threshold = %+v", got.VolatileDays)
+	}
+
+	if _, err = uc.SpreadHistory(context.Background(), &SpreadFilter{}); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+		t.Errorf("no currency: err = %v, want ErrInvalidArgument", err)
+	}
+	if _, err = uc.SpreadHistory(context.Background(), &SpreadFilter{CurrencyCode: "USD", Channel: "card"}); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("no card spreads: err = %v, want ErrNotFound", err)
+	}
+}
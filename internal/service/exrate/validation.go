@@ -0,0 +1,119 @@
+package exrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// ValidationRules configure the sanity checks a fetched rate passes before it is stored.
+// Zero thresholds disable the corresponding check.
+type ValidationRules struct {
+	RejectNonPositive       bool    `json:"reject_non_positive"`
+	RejectBuyAboveSell      bool    `json:"reject_buy_above_sell"`
+	MaxSpreadPct            float64 `json:"max_spread_pct"`             // (sell - buy) / mid
+	MaxJumpPct              float64 `json:"max_jump_pct"`               // от последнего сохранённого курса серии
+	MaxOfficialDeviationPct float64 `json:"max_official_deviation_pct"` // от официального курса НБРК, только KZT
+}
+
+// DefaultValidationRules are loose enough for cash RUB spreads at exchange offices.
+func DefaultValidationRules() ValidationRules {
+	return ValidationRules{
+		RejectNonPositive:       true,
+		RejectBuyAboveSell:      true,
+		MaxSpreadPct:            15,
+		MaxJumpPct:              10,
+		MaxOfficialDeviationPct: 20,
+	}
+}
+
+// LoadValidationRules reads rules from JSON; omitted fields keep their defaults.
+func LoadValidationRules(r io.Reader) (ValidationRules, error) {
+	rules := DefaultValidationRules()
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return ValidationRules{}, fmt.Errorf("decode validation rules: %w", err)
+	}
+	return rules, nil
+}
+
+// SetValidationRules replaces the default validation rules.
+func (u *ExchangeRateUsecase) SetValidationRules(rules ValidationRules) {
+	u.rules = rules
+}
+
+// validate returns the violated rule and its explanation, empty rule if the rate is sane.
+// last is the latest stored rate of the series, nil on first fetch.
+func (u *ExchangeRateUsecase) validate(
+	ctx context.Context,
+	rate, last *entity.ExchangeRate,
+) (rule, reason string, err error) {
+	buy, errBuy := strconv.ParseFloat(rate.Buy, 64)
+	sell, errSell := strconv.ParseFloat(rate.Sell, 64)
+	if u.rules.RejectNonPositive &&
+		(errBuy != nil || errSell != nil || !(buy > 0) || !(sell > 0) || math.IsInf(buy+sell, 0)) {
+		return entity.RuleNonPositive, fmt.Sprintf("buy %q, sell %q", rate.Buy, rate.Sell), nil
+	}
+	if u.rules.RejectBuyAboveSell && buy > sell {
+		return entity.RuleBuyAboveSell, fmt.Sprintf("buy %s above sell %s", rate.Buy, rate.Sell), nil
+	}
+
+	m := (buy + sell) / 2
+	if u.rules.MaxSpreadPct > 0 && m > 0 {
+		if spread := (sell - buy) / m * 100; spread > u.rules.MaxSpreadPct {
+			return entity.RuleSpread, fmt.Sprintf("spread %.2f%% above %.2f%%", spread, u.rules.MaxSpreadPct), nil
+		}
+	}
+
+	official, err := u.officialMid(ctx, rate)
+	if err != nil {
+		return "", "", err
+	}
+	if u.rules.MaxOfficialDeviationPct > 0 && official > 0 {
+		if dev := deviationPct(m, official); dev > u.rules.MaxOfficialDeviationPct {
+			return entity.RuleOfficialDeviation, fmt.Sprintf("%.2f%% from official %g", dev, official), nil
+		}
+	}
+	if u.rules.MaxJumpPct > 0 && last != nil {
+		// Скачок, подтверждённый официальным курсом (девальвация), — не ошибка банка: новый курс рядом
+		// с официальным и ближе к нему, чем прежний, то есть официальный сдвинулся вместе с ним
+		prev := mid(last)
+		confirmed := official > 0 && u.rules.MaxOfficialDeviationPct > 0 &&
+			deviationPct(m, official) <= u.rules.MaxOfficialDeviationPct &&
+			deviationPct(m, official) < deviationPct(prev, official)
+		if prev > 0 && !confirmed {
+			if jump := deviationPct(m, prev); jump > u.rules.MaxJumpPct {
+				return entity.RuleJump, fmt.Sprintf("%.2f%% from last %s/%s", jump, last.Buy, last.Sell), nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// officialMid returns the latest NBRK rate of the currency, 0 if it is unknown or not comparable.
+func (u *ExchangeRateUsecase) officialMid(ctx context.Context, rate *entity.ExchangeRate) (float64, error) {
+	if rate.Quote() != entity.QuoteKZT || rate.Source == OfficialSource {
+		return 0, nil
+	}
+	official, err := u.repo.GetLatestExchangeRate(ctx, entity.SeriesKey{
+		Source: OfficialSource, Channel: entity.ChannelOfficial, CurrencyCode: rate.CurrencyCode,
+	})
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return 0, nil // официального курса ещё нет — сверять не с чем
+	}
+	if err != nil {
+		return 0, err
+	}
+	return mid(official), nil
+}
+
+// deviationPct returns |v - base| / base in percent.
+func deviationPct(v, base float64) float64 {
+	return math.Abs(v-base) / base * 100
+}
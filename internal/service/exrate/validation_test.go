@@ -0,0 +1,181 @@
+package exrate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+func TestExchangeRateUsecase_AddRates_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     entity.ExchangeRate
+		last     *entity.ExchangeRate // последний сохранённый курс серии
+		official *entity.ExchangeRate // курс НБРК
+		rules    *ValidationRules
+		wantRule string // пусто — курс сохраняется
+	}{
+		{name: "sane", rate: entity.ExchangeRate{Buy: "537", Sell: "542"}},
+		{name: "empty buy", rate: entity.ExchangeRate{Buy: "", Sell: "542"}, wantRule: entity.RuleNonPositive},
+		{name: "zero sell", rate: entity.ExchangeRate{Buy: "537", Sell: "0"}, wantRule: entity.RuleNonPositive},
+		{name: "buy above sell", rate: entity.ExchangeRate{Buy: "545", Sell: "540"}, wantRule: entity.RuleBuyAboveSell},
+		{name: "wide spread", rate: entity.ExchangeRate{Buy: "450", Sell: "600"}, wantRule: entity.RuleSpread},
+		{
+			name:     "jump from last",
+			rate:     entity.ExchangeRate{Buy: "5370", Sell: "5420"}, // лишний ноль
+			last:     &entity.ExchangeRate{Buy: "536", Sell: "541"},
+			wantRule: entity.RuleJump,
+		},
+		{
+			name:     "far from official",
+			rate:     entity.ExchangeRate{Buy: "337", Sell: "342"},
+			official: &entity.ExchangeRate{Buy: "539.5", Sell: "539.5"},
+			wantRule: entity.RuleOfficialDeviation,
+		},
+		{
+			name:     "jump confirmed by official",
+			rate:     entity.ExchangeRate{Buy: "637", Sell: "642"},
+			last:     &entity.ExchangeRate{Buy: "536", Sell: "541"},
+			official: &entity.ExchangeRate{Buy: "640", Sell: "640"},
+		},
+		{
+			name:     "jump not followed by official",
+			rate:     entity.ExchangeRate{Buy: "617", Sell: "622"}, // в пределах отклонения, но официальный стоит
+			last:     &entity.ExchangeRate{Buy: "536", Sell: "541"},
+			official: &entity.ExchangeRate{Buy: "539.5", Sell: "539.5"},
+			wantRule: entity.RuleJump,
+		},
+		{
+			name:  "disabled rules",
+			rate:  entity.ExchangeRate{Buy: "545", Sell: "540"},
+			rules: &ValidationRules{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored []*entity.ExchangeRate
+			repo := &mockRepository{
+				getLatestExchangeRateFunc: func(_ context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
+					switch {
+					case key.Source == OfficialSource && tt.official != nil:
+						return tt.official, nil
+					case key.Source == "Halyk" && tt.last != nil:
+						return tt.last, nil
+					}
+					return nil, internalErrors.ErrNotFound
+				},
+				addExchangeRateFunc: func(_ context.Context, r *entity.ExchangeRate) error {
+					stored = append(stored, r)
+					return nil
+				},
+			}
+			rate := tt.rate
+			rate.CurrencyCode = "USD"
+			driver := &mockDriver{fetchRatesFunc: func(context.Context) ([]*entity.ExchangeRate, error) {
+				return []*entity.ExchangeRate{&rate}, nil
+			}}
+			uc := NewExchangeRateUsecase(repo, map[string]Driver{"Halyk": driver})
+			if tt.rules != nil {
+				uc.SetValidationRules(*tt.rules)
+			}
+
+			if err := uc.AddRates(context.Background()); err != nil {
+				t.Fatalf("AddRates() error = %v", err)
+			}
+
+			if tt.wantRule == "" {
+				if len(stored) != 1 || len(repo.quarantined) != 0 {
+					t.Fatalf("stored %d, quarantined %+v; want stored", len(stored), repo.quarantined)
+				}
+				return
+			}
+			if len(stored) != 0 {
+				t.Fatalf("violating rate stored: %+v", stored[0])
+			}
+			if len(repo.quarantined) != 1 || repo.quarantined[0].Rule != tt.wantRule {
+				t.Fatalf("quarantined %+v, want rule %s", repo.quarantined, tt.wantRule)
+			}
+			if q := repo.quarantined[0]; q.Rate.Source != "Halyk" || q.Reason == "" || q.Hits != 1 {
+				t.Errorf("quarantined rate = %+v / %+v", q, q.Rate)
+			}
+		})
+	}
+}
+
+func TestLoadValidationRules(t *testing.T) {
+	rules, err := LoadValidationRules(strings.NewReader(`{"max_jump_pct": 25, "reject_buy_above_sell": false}`))
+	if err != nil {
+		t.Fatalf("LoadValidationRules() error = %v", err)
+	}
+	want := DefaultValidationRules()
+	want.MaxJumpPct = 25
+	want.RejectBuyAboveSell = false
+	if rules != want {
+		t.Errorf("rules = %+v, want %+v", rules, want)
+	}
+
+	if _, err = LoadValidationRules(strings.NewReader(`{"max_jump_pct": "a lot"}`)); err == nil {
+		t.Error("expected error for invalid rules")
+	}
+}
+
+// Отпущенный из карантина курс проходит тот же путь, что и свежий: со временем выпуска,
+// изменением к последнему курсу серии и уведомлением подписчиков
+func TestExchangeRateUsecase_ReleaseQuarantinedRate(t *testing.T) {
+	last := &entity.ExchangeRate{CurrencyCode: "USD", Source: "Halyk", Buy: "536", Sell: "541"}
+	var stored []*entity.ExchangeRate
+	repo := &mockRepository{
+		getLatestExchangeRateFunc: func(_ context.Context, key entity.SeriesKey) (*entity.ExchangeRate, error) {
+			if key.Source == "Halyk" {
+				return last, nil
+			}
+			return nil, internalErrors.ErrNotFound
+		},
+		addExchangeRateFunc: func(_ context.Context, r *entity.ExchangeRate) error {
+			stored = append(stored, r)
+			return nil
+		},
+	}
+	driver := &mockDriver{fetchRatesFunc: func(context.Context) ([]*entity.ExchangeRate, error) {
+		return []*entity.ExchangeRate{{CurrencyCode: "USD", Buy: "5370", Sell: "5420"}}, nil
+	}}
+	uc := NewExchangeRateUsecase(repo, map[string]Driver{"Halyk": driver})
+	listener := &recordingListener{}
+	uc.AddRatesListener(listener)
+	if err := uc.AddRates(context.Background()); err != nil {
+		t.Fatalf("AddRates() error = %v", err)
+	}
+	if len(repo.quarantined) != 1 || len(listener.calls) != 0 {
+		t.Fatalf("quarantined %d, listener calls %d; want the jump held back", len(repo.quarantined), len(listener.calls))
+	}
+	repo.quarantined[0].ID = 7
+	repo.quarantined[0].Rate.CreatedAt = time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	released := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return released }
+	if err := uc.ReleaseQuarantinedRate(context.Background(), 7); err != nil {
+		t.Fatalf("ReleaseQuarantinedRate() error = %v", err)
+	}
+	if len(stored) != 1 || len(repo.quarantined) != 0 {
+		t.Fatalf("stored %d, quarantined %d; want the rate moved", len(stored), len(repo.quarantined))
+	}
+	if r := stored[0]; !r.CreatedAt.Equal(released) || r.BuyChangePrev != 4834 || r.SellChangePrev != 4879 {
+		t.Errorf("released rate = %+v", r)
+	}
+	if len(listener.calls) != 1 || listener.calls[0][0] != stored[0] {
+		t.Errorf("listener calls = %v, want the released rate", listener.calls)
+	}
+	if checked := uc.checkedAt(); !checked[last.Key()].Equal(released) {
+		t.Errorf("checkedAt() = %v, want the series confirmed at release", checked)
+	}
+
+	if err := uc.ReleaseQuarantinedRate(context.Background(), 7); !errors.Is(err, internalErrors.ErrNotFound) {
+		t.Errorf("second release: err = %v, want ErrNotFound", err)
+	}
+}
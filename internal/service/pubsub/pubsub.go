@@ -0,0 +1,83 @@
+// Package pubsub fans out rates stored by ingestion to in-process subscribers, such as live
+// page updates, without letting a slow subscriber hold up ingestion.
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// Broker publishes batches of stored rates to every subscriber. It implements exrate.RatesListener.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBroker creates a broker without subscribers.
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives batches of stored rates. Rates are shared between subscribers and must not be modified.
+type Subscription struct {
+	b       *Broker
+	c       chan []*entity.ExchangeRate
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Subscribe registers a subscriber with room for buffer batches; Close it when done.
+func (b *Broker) Subscribe(buffer int) *Subscription {
+	s := &Subscription{b: b, c: make(chan []*entity.ExchangeRate, max(buffer, 1))}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Subscribers returns the number of open subscriptions.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// RatesStored publishes the batch without blocking: a subscriber whose buffer is full misses it
+// (see Subscription.Dropped).
+func (b *Broker) RatesStored(_ context.Context, rates []*entity.ExchangeRate) {
+	if len(rates) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		select {
+		case s.c <- rates:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// C delivers batches of rates; it is closed by Close.
+func (s *Subscription) C() <-chan []*entity.ExchangeRate {
+	return s.c
+}
+
+// Dropped returns how many batches were missed because the subscriber did not keep up.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes; it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		close(s.c)
+		s.b.mu.Unlock()
+	})
+}
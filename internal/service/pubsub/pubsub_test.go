@@ -0,0 +1,48 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	fast := b.Subscribe(4)
+	slow := b.Subscribe(1)
+	if b.Subscribers() != 2 {
+		t.Fatalf("subscribers = %d, want 2", b.Subscribers())
+	}
+
+	ctx := context.Background()
+	batch := func(code string) []*entity.ExchangeRate {
+		return []*entity.ExchangeRate{{CurrencyCode: code}}
+	}
+	b.RatesStored(ctx, batch("USD"))
+	b.RatesStored(ctx, nil)
+	// У медленного подписчика буфер на одну пачку: вторая теряется, публикация не блокируется
+	b.RatesStored(ctx, batch("EUR"))
+
+	for _, want := range []string{"USD", "EUR"} {
+		if got := <-fast.C(); got[0].CurrencyCode != want {
+			t.Errorf("fast got %s, want %s", got[0].CurrencyCode, want)
+		}
+	}
+	if fast.Dropped() != 0 {
+		t.Errorf("fast dropped %d", fast.Dropped())
+	}
+	if got := <-slow.C(); got[0].CurrencyCode != "USD" || slow.Dropped() != 1 {
+		t.Errorf("slow got %s, dropped %d", got[0].CurrencyCode, slow.Dropped())
+	}
+
+	slow.Close()
+	slow.Close()
+	if _, ok := <-slow.C(); ok {
+		t.Error("channel of a closed subscription is open")
+	}
+	b.RatesStored(ctx, batch("RUB"))
+	if b.Subscribers() != 1 || len(fast.C()) != 1 {
+		t.Errorf("subscribers = %d, fast queued %d", b.Subscribers(), len(fast.C()))
+	}
+}
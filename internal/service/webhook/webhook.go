@@ -0,0 +1,372 @@
+// Package webhook pushes stored rates to subscribed receivers as signed JSON payloads,
+// retrying failed deliveries with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// EventRatesChanged is sent when new or changed rates are stored.
+const EventRatesChanged = "rates.changed"
+
+// Request headers of a delivery. The signature is hex HMAC-SHA256 of "<timestamp>.<body>" keyed
+// by the webhook secret, so a receiver can reject replays with an old timestamp.
+const (
+	HeaderEvent     = "X-Exr-Event"
+	HeaderDelivery  = "X-Exr-Delivery"
+	HeaderTimestamp = "X-Exr-Timestamp"
+	HeaderSignature = "X-Exr-Signature"
+)
+
+const (
+	// pollInterval is how often the queue is checked for due retries.
+	pollInterval = 5 * time.Second
+	// batchSize limits deliveries attempted in one pass.
+	batchSize = 100
+	// maxErrorLen trims receiver responses kept in the delivery log.
+	maxErrorLen = 512
+	// attemptTimeout bounds one delivery attempt, so a dead receiver holds its queue only briefly.
+	attemptTimeout = 10 * time.Second
+	// maxParallel is the number of webhooks delivered to at once.
+	maxParallel = 8
+)
+
+// Repository stores subscriptions and the delivery queue.
+type Repository interface {
+	AddWebhook(ctx context.Context, w *entity.Webhook) error
+	GetWebhooks(ctx context.Context) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	AddWebhookDelivery(ctx context.Context, d *entity.WebhookDelivery) error
+	// GetDueWebhookDeliveries returns up to limit pending deliveries whose next attempt is due, oldest first.
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	// GetWebhookDeliveries returns up to limit latest deliveries of the webhook, newest first.
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]*entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, d *entity.WebhookDelivery) error
+}
+
+// RetryPolicy controls redelivery: the n-th retry waits BaseBackoff * 2^(n-1), at most MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy gives a receiver about a day to come back.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 10, BaseBackoff: 30 * time.Second, MaxBackoff: 6 * time.Hour}
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	d := p.BaseBackoff
+	for i := 1; i < attempts && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// Dispatcher queues payloads for matching webhooks and delivers them in the background.
+type Dispatcher struct {
+	repo    Repository
+	client  *http.Client
+	l       logger.Logger
+	policy  RetryPolicy
+	now     func() time.Time
+	resolve resolveFunc
+	wake    chan struct{}
+}
+
+// NewDispatcher creates a dispatcher; call Run to start delivering. The client should dial
+// only public addresses, see NewTransport.
+func NewDispatcher(repo Repository, client *http.Client, l logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:    repo,
+		client:  client,
+		l:       l,
+		policy:  DefaultRetryPolicy(),
+		now:     time.Now,
+		resolve: lookupHost,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// SetRetryPolicy replaces DefaultRetryPolicy.
+func (d *Dispatcher) SetRetryPolicy(p RetryPolicy) {
+	d.policy = p
+}
+
+// CreateWebhook validates and stores a subscription; a secret is generated if none is given.
+// The receiver host must resolve to public addresses only.
+func (d *Dispatcher) CreateWebhook(ctx context.Context, w *entity.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("webhook url %q: %w", w.URL, internalErrors.ErrInvalidArgument)
+	}
+	if err = checkHost(ctx, d.resolve, u.Hostname()); err != nil {
+		return fmt.Errorf("webhook url %q: %w: %w", w.URL, err, internalErrors.ErrInvalidArgument)
+	}
+	if w.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err = rand.Read(buf); err != nil {
+			return fmt.Errorf("generate secret: %w", err)
+		}
+		w.Secret = hex.EncodeToString(buf)
+	}
+	w.CurrencyCode = strings.ToUpper(w.CurrencyCode)
+	w.CreatedAt = d.now().UTC()
+	return d.repo.AddWebhook(ctx, w)
+}
+
+// Webhooks returns all subscriptions in creation order.
+func (d *Dispatcher) Webhooks(ctx context.Context) ([]*entity.Webhook, error) {
+	return d.repo.GetWebhooks(ctx)
+}
+
+// DeleteWebhook removes the subscription with its delivery log.
+func (d *Dispatcher) DeleteWebhook(ctx context.Context, id int64) error {
+	return d.repo.DeleteWebhook(ctx, id)
+}
+
+// Deliveries returns up to limit latest deliveries of the webhook, newest first.
+func (d *Dispatcher) Deliveries(ctx context.Context, webhookID int64, limit int) ([]*entity.WebhookDelivery, error) {
+	return d.repo.GetWebhookDeliveries(ctx, webhookID, limit)
+}
+
+// rateDTO is a rate in the webhook payload.
+type rateDTO struct {
+	Currency   string    `json:"currency"`
+	Quote      string    `json:"quote"`
+	Source     string    `json:"source"`
+	Channel    string    `json:"channel,omitempty"`
+	Branch     string    `json:"branch,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	City       string    `json:"city,omitempty"`
+	Buy        string    `json:"buy"`
+	Sell       string    `json:"sell"`
+	BuyChange  float64   `json:"buy_change"`
+	SellChange float64   `json:"sell_change"`
+	At         time.Time `json:"at"`
+}
+
+// payload is the body of a rates.changed delivery.
+type payload struct {
+	Event string    `json:"event"`
+	At    time.Time `json:"at"`
+	Rates []rateDTO `json:"rates"`
+}
+
+// RatesStored queues a delivery with the matching rates for every webhook. It implements
+// exrate.RatesListener; errors are logged, the rates are already stored.
+func (d *Dispatcher) RatesStored(ctx context.Context, rates []*entity.ExchangeRate) {
+	hooks, err := d.repo.GetWebhooks(ctx)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		d.l.Error("webhooks: list subscriptions failed", "err", err)
+		return
+	}
+
+	now := d.now().UTC()
+	queued := false
+	for _, w := range hooks {
+		p := payload{Event: EventRatesChanged, At: now}
+		for _, r := range rates {
+			if !w.Matches(r) {
+				continue
+			}
+			p.Rates = append(p.Rates, rateDTO{
+				Currency:   r.CurrencyCode,
+				Quote:      r.Quote(),
+				Source:     r.Source,
+				Channel:    r.Channel,
+				Branch:     r.Branch,
+				BranchName: r.BranchName,
+				City:       r.City,
+				Buy:        r.Buy,
+				Sell:       r.Sell,
+				BuyChange:  r.BuyChangePrev,
+				SellChange: r.SellChangePrev,
+				At:         r.CreatedAt,
+			})
+		}
+		if len(p.Rates) == 0 {
+			continue
+		}
+		body, err := json.Marshal(p)
+		if err != nil {
+			d.l.Error("webhooks: encode payload failed", "webhook", w.ID, "err", err)
+			continue
+		}
+		err = d.repo.AddWebhookDelivery(ctx, &entity.WebhookDelivery{
+			WebhookID:     w.ID,
+			Event:         EventRatesChanged,
+			Payload:       body,
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			d.l.Error("webhooks: queue delivery failed", "webhook", w.ID, "err", err)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run delivers queued payloads until ctx is done: right after new ones are queued and
+// periodically for due retries.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.deliverDue(ctx); err != nil {
+			d.l.Error("webhooks: delivery pass failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverDue attempts every due delivery once. Webhooks are served in parallel, each one's
+// deliveries in queue order, so a slow receiver delays only its own deliveries.
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	due, err := d.repo.GetDueWebhookDeliveries(ctx, d.now().UTC(), batchSize)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	hooks, err := d.repo.GetWebhooks(ctx)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return err
+	}
+	byID := make(map[int64]*entity.Webhook, len(hooks))
+	for _, w := range hooks {
+		byID[w.ID] = w
+	}
+
+	queues := make(map[int64][]*entity.WebhookDelivery)
+	for _, delivery := range due {
+		if _, ok := byID[delivery.WebhookID]; !ok {
+			continue // подписку удалили вместе с очередью, пока шёл проход
+		}
+		queues[delivery.WebhookID] = append(queues[delivery.WebhookID], delivery)
+	}
+
+	g := new(errgroup.Group)
+	g.SetLimit(maxParallel)
+	for id, queue := range queues {
+		w, queue := byID[id], queue
+		g.Go(func() error {
+			for _, delivery := range queue {
+				d.attempt(ctx, w, delivery)
+				if err := d.repo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+					return fmt.Errorf("update delivery %d: %w", delivery.ID, err)
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// attempt posts the payload once and records the outcome in the delivery.
+func (d *Dispatcher) attempt(ctx context.Context, w *entity.Webhook, delivery *entity.WebhookDelivery) {
+	delivery.Attempts++
+	status, err := d.post(ctx, w, delivery)
+	delivery.LastStatus = status
+	now := d.now().UTC()
+	if err == nil {
+		delivery.Status, delivery.LastError, delivery.DeliveredAt = entity.DeliveryDelivered, "", now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.policy.MaxAttempts {
+		delivery.Status = entity.DeliveryFailed
+		d.l.Warn("webhooks: delivery failed", "webhook", w.ID, "delivery", delivery.ID, "attempts", delivery.Attempts, "err", err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(d.policy.backoff(delivery.Attempts))
+}
+
+func (d *Dispatcher) post(ctx context.Context, w *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(w.Secret, ts, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLen))
+	return resp.StatusCode, fmt.Errorf("receiver responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// Sign returns hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value ("sha256=<hex>") of a delivery.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	got, _ := hex.DecodeString(Sign(secret, timestamp, body))
+	return hmac.Equal(got, want)
+}
@@ -0,0 +1,335 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any)        {}
+func (nopLogger) Info(string, ...any)         {}
+func (nopLogger) Warn(string, ...any)         {}
+func (nopLogger) Error(string, ...any)        {}
+func (l nopLogger) With(...any) logger.Logger { return l }
+
+// memRepository хранит подписки и очередь в памяти
+type memRepository struct {
+	hooks      []*entity.Webhook
+	deliveries []*entity.WebhookDelivery
+}
+
+func (m *memRepository) AddWebhook(_ context.Context, w *entity.Webhook) error {
+	w.ID = int64(len(m.hooks) + 1)
+	m.hooks = append(m.hooks, w)
+	return nil
+}
+
+func (m *memRepository) GetWebhooks(context.Context) ([]*entity.Webhook, error) {
+	if len(m.hooks) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.hooks, nil
+}
+
+func (m *memRepository) DeleteWebhook(context.Context, int64) error { return nil }
+
+func (m *memRepository) AddWebhookDelivery(_ context.Context, d *entity.WebhookDelivery) error {
+	d.ID = int64(len(m.deliveries) + 1)
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memRepository) GetDueWebhookDeliveries(_ context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	var out []*entity.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == entity.DeliveryPending && !d.NextAttemptAt.After(now) && len(out) < limit {
+			c := *d
+			out = append(out, &c)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+func (m *memRepository) GetWebhookDeliveries(_ context.Context, id int64, _ int) ([]*entity.WebhookDelivery, error) {
+	var out []*entity.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.WebhookID == id {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepository) UpdateWebhookDelivery(_ context.Context, d *entity.WebhookDelivery) error {
+	c := *d
+	m.deliveries[d.ID-1] = &c
+	return nil
+}
+
+// receiver is a local webhook endpoint answering with the queued statuses, then 200.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || !Verify(rc.secret, ts, body, r.Header.Get(HeaderSignature)) {
+		rc.t.Errorf("bad signature %q at %q", r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp))
+	}
+	if r.Header.Get(HeaderEvent) != EventRatesChanged || r.Header.Get(HeaderDelivery) == "" {
+		rc.t.Errorf("unexpected headers %v", r.Header)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, http.StatusText(status))
+}
+
+func newTestDispatcher(t *testing.T, statuses ...int) (*Dispatcher, *memRepository, *receiver, string, *time.Time) {
+	rc := &receiver{t: t, secret: "s3cret", statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	repo := &memRepository{}
+	d := NewDispatcher(repo, publicNameClient(srv), nopLogger{})
+	d.resolve = publicResolver
+	now := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, repo, rc, "http://receiver.example", &now
+}
+
+// publicNameClient reaches the loopback test server under any host name, so receivers can
+// be registered with public-looking URLs.
+func publicNameClient(srv *httptest.Server) *http.Client {
+	client := srv.Client()
+	client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	return client
+}
+
+// publicResolver resolves every name to a documentation address that counts as public.
+func publicResolver(context.Context, string) ([]netip.Addr, error) {
+	return []netip.Addr{netip.MustParseAddr("203.0.113.7")}, nil
+}
+
+func TestDispatcher_DeliversSignedPayloadToMatchingWebhooks(t *testing.T) {
+	d, repo, rc, url, now := newTestDispatcher(t)
+	ctx := context.Background()
+	for _, w := range []*entity.Webhook{
+		{URL: url + "/usd", Secret: rc.secret, CurrencyCode: "usd"},
+		{URL: url + "/eur", Secret: rc.secret, CurrencyCode: "EUR"},
+		{URL: url + "/halyk", Secret: rc.secret, Source: "Halyk", Channel: "cash"},
+	} {
+		if err := d.CreateWebhook(ctx, w); err != nil {
+			t.Fatalf("CreateWebhook() error = %v", err)
+		}
+	}
+
+	d.RatesStored(ctx, []*entity.ExchangeRate{
+		{CurrencyCode: "USD", Source: "Halyk", Channel: "cash", Buy: "500", Sell: "505", BuyChangePrev: 1.5, CreatedAt: *now},
+		{CurrencyCode: "USD", Source: "Kaspi", Channel: "card", Buy: "501", Sell: "504", CreatedAt: *now},
+	})
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("queued %d deliveries, want USD and Halyk webhooks", len(repo.deliveries))
+	}
+	if err := d.deliverDue(ctx); err != nil {
+		t.Fatalf("deliverDue() error = %v", err)
+	}
+
+	for _, dl := range repo.deliveries {
+		if dl.Status != entity.DeliveryDelivered || dl.Attempts != 1 || dl.LastStatus != http.StatusOK || !dl.DeliveredAt.Equal(*now) {
+			t.Errorf("unexpected delivery %+v", dl)
+		}
+	}
+	var got []payload
+	for _, b := range rc.bodies {
+		var p payload
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		got = append(got, p)
+	}
+	sort.Slice(got, func(i, j int) bool { return len(got[i].Rates) > len(got[j].Rates) })
+	if len(got) != 2 || len(got[0].Rates) != 2 || len(got[1].Rates) != 1 || got[1].Rates[0].Source != "Halyk" {
+		t.Fatalf("unexpected payloads %+v", got)
+	}
+	if r := got[1].Rates[0]; r.Quote != "KZT" || r.Buy != "500" || r.BuyChange != 1.5 || got[1].Event != EventRatesChanged {
+		t.Errorf("unexpected rate %+v", r)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	d, repo, rc, url, now := newTestDispatcher(t, http.StatusInternalServerError, http.StatusBadGateway)
+	ctx := context.Background()
+	if err := d.CreateWebhook(ctx, &entity.Webhook{URL: url, Secret: rc.secret}); err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	d.RatesStored(ctx, []*entity.ExchangeRate{{CurrencyCode: "USD", Source: "Halyk", Buy: "500", Sell: "505", CreatedAt: *now}})
+
+	steps := []struct {
+		after      time.Duration
+		wantCalls  int
+		wantStatus string
+		wantNext   time.Duration // от текущего времени
+	}{
+		{wantCalls: 1, wantStatus: entity.DeliveryPending, wantNext: 30 * time.Second},
+		{after: 10 * time.Second, wantCalls: 1, wantStatus: entity.DeliveryPending, wantNext: 20 * time.Second},
+		{after: 20 * time.Second, wantCalls: 2, wantStatus: entity.DeliveryPending, wantNext: time.Minute},
+		{after: time.Minute, wantCalls: 3, wantStatus: entity.DeliveryDelivered},
+	}
+	for i, st := range steps {
+		*now = now.Add(st.after)
+		if err := d.deliverDue(ctx); err != nil {
+			t.Fatalf("step %d: deliverDue() error = %v", i, err)
+		}
+		dl := repo.deliveries[0]
+		if len(rc.bodies) != st.wantCalls || dl.Status != st.wantStatus {
+			t.Fatalf("step %d: %d calls, status %s; want %d, %s", i, len(rc.bodies), dl.Status, st.wantCalls, st.wantStatus)
+		}
+		if st.wantNext > 0 && !dl.NextAttemptAt.Equal(now.Add(st.wantNext)) {
+			t.Errorf("step %d: next attempt at %v, want %v", i, dl.NextAttemptAt, now.Add(st.wantNext))
+		}
+	}
+	if dl := repo.deliveries[0]; dl.Attempts != 3 || dl.LastError != "" {
+		t.Errorf("unexpected delivery log %+v", dl)
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	d, repo, rc, url, now := newTestDispatcher(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	d.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Second, MaxBackoff: time.Second})
+	ctx := context.Background()
+	if err := d.CreateWebhook(ctx, &entity.Webhook{URL: url, Secret: rc.secret}); err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	d.RatesStored(ctx, []*entity.ExchangeRate{{CurrencyCode: "USD", Source: "Halyk", Buy: "500", Sell: "505", CreatedAt: *now}})
+
+	for i := 0; i < 3; i++ {
+		if err := d.deliverDue(ctx); err != nil {
+			t.Fatalf("deliverDue() error = %v", err)
+		}
+		*now = now.Add(time.Second)
+	}
+	dl := repo.deliveries[0]
+	if dl.Status != entity.DeliveryFailed || dl.Attempts != 2 || dl.LastStatus != http.StatusServiceUnavailable ||
+		dl.LastError != "receiver responded 503 Service Unavailable: Service Unavailable" {
+		t.Errorf("unexpected delivery %+v", dl)
+	}
+}
+
+func TestDispatcher_SlowReceiverDoesNotBlockOthers(t *testing.T) {
+	fastDone := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fast" {
+			close(fastDone)
+			return
+		}
+		// Медленный получатель отвечает, только когда быстрый уже получил своё
+		select {
+		case <-fastDone:
+		case <-time.After(5 * time.Second):
+			t.Error("fast receiver waited for the slow one")
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	repo := &memRepository{}
+	d := NewDispatcher(repo, publicNameClient(srv), nopLogger{})
+	d.resolve = publicResolver
+	ctx := context.Background()
+	for _, path := range []string{"/slow", "/fast"} {
+		if err := d.CreateWebhook(ctx, &entity.Webhook{URL: "http://receiver.example" + path}); err != nil {
+			t.Fatalf("CreateWebhook() error = %v", err)
+		}
+	}
+	d.RatesStored(ctx, []*entity.ExchangeRate{{CurrencyCode: "USD", Source: "Halyk", Buy: "500", Sell: "505", CreatedAt: time.Now()}})
+
+	if err := d.deliverDue(ctx); err != nil {
+		t.Fatalf("deliverDue() error = %v", err)
+	}
+	for _, dl := range repo.deliveries {
+		if dl.Status != entity.DeliveryDelivered {
+			t.Errorf("unexpected delivery %+v", dl)
+		}
+	}
+}
+
+func TestDispatcher_CreateWebhook(t *testing.T) {
+	d := NewDispatcher(&memRepository{}, http.DefaultClient, nopLogger{})
+	d.resolve = func(_ context.Context, host string) ([]netip.Addr, error) {
+		if host == "intranet.example" {
+			return []netip.Addr{netip.MustParseAddr("203.0.113.7"), netip.MustParseAddr("10.0.0.5")}, nil
+		}
+		return publicResolver(context.Background(), host)
+	}
+	for _, u := range []string{
+		"", "ftp://example.com", "https://", "not a url",
+		"http://127.0.0.1:8080/hook", "http://[::1]/hook", "http://169.254.169.254/latest/meta-data",
+		"http://192.168.1.10/hook", "http://100.64.0.1/hook", "http://[::ffff:10.0.0.1]/hook", "https://intranet.example/hook",
+	} {
+		if err := d.CreateWebhook(context.Background(), &entity.Webhook{URL: u}); !errors.Is(err, internalErrors.ErrInvalidArgument) {
+			t.Errorf("CreateWebhook(%q) error = %v, want ErrInvalidArgument", u, err)
+		}
+	}
+	w := &entity.Webhook{URL: "https://pricing.local/hook"}
+	if err := d.CreateWebhook(context.Background(), w); err != nil || len(w.Secret) != 64 {
+		t.Errorf("CreateWebhook() secret %q, err %v", w.Secret, err)
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"203.0.113.7:443":       true,
+		"[2001:db8::1]:443":     true,
+		"127.0.0.1:80":          false,
+		"10.1.2.3:443":          false,
+		"169.254.169.254:80":    false,
+		"[fe80::1]:443":         false,
+		"[::ffff:127.0.0.1]:80": false,
+		"0.0.0.0:80":            false,
+	} {
+		if err := dialControl("tcp", addr, nil); (err == nil) != ok {
+			t.Errorf("dialControl(%s) error = %v", addr, err)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 60: 10 * time.Second} {
+		if got := p.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+// Package telegram is a Telegram bot interface to exchange rates: rate tables, best offers,
+// conversion and alert subscriptions, served by long polling of the Bot API.
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+const (
+	// pollTimeout is how long getUpdates waits for new updates.
+	pollTimeout = 25 * time.Second
+	// retryDelay is the pause after a failed getUpdates.
+	retryDelay = 5 * time.Second
+	// maxTableRows caps rate tables to keep messages readable.
+	maxTableRows = 25
+	// bestLimit is the number of offers per side in /best.
+	bestLimit = 3
+	// defaultCooldown is the cooldown of alert rules created from the bot.
+	defaultCooldown = time.Hour
+)
+
+// Service is the part of the exchange rate usecase the bot uses.
+type Service interface {
+	GetRates(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error)
+	Sources() []entity.SourceInfo
+	BestRates(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.BestRate, error)
+	Convert(
+		ctx context.Context,
+		amount float64,
+		from, to string,
+		filter *exrate.ExchangeRateFilter,
+	) (*entity.Conversion, error)
+	CreateAlertRule(ctx context.Context, rule *entity.AlertRule) error
+	AlertRules(ctx context.Context) ([]*entity.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id int64) error
+}
+
+// Store links alert rules to the chats that created them.
+type Store interface {
+	AddTelegramAlert(ctx context.Context, ruleID, chatID int64) error
+	GetTelegramAlertChat(ctx context.Context, ruleID int64) (int64, error)
+	GetTelegramAlertRules(ctx context.Context, chatID int64) ([]int64, error)
+	DeleteTelegramAlert(ctx context.Context, ruleID int64) error
+}
+
+// Bot answers chat commands and notifies chats of their alerts.
+type Bot struct {
+	client *Client
+	svc    Service
+	store  Store
+	l      logger.Logger
+	offset int64 // следующий update_id, который ждём от getUpdates
+}
+
+// NewBot creates a bot. Register it with ExchangeRateUsecase.AddAlertListener to deliver alerts.
+func NewBot(client *Client, svc Service, store Store, l logger.Logger) *Bot {
+	return &Bot{client: client, svc: svc, store: store, l: l}
+}
+
+// Run polls for updates and answers them until ctx is done.
+func (b *Bot) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := b.pollOnce(ctx, pollTimeout); err != nil && ctx.Err() == nil {
+			b.l.Error("telegram: poll failed", "err", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// pollOnce fetches one batch of updates and answers every message in it.
+func (b *Bot) pollOnce(ctx context.Context, timeout time.Duration) error {
+	updates, err := b.client.GetUpdates(ctx, b.offset, timeout)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		// Подтверждаем update до ответа: упавший ответ не должен зациклить бота
+		b.offset = u.UpdateID + 1
+		if u.Message == nil || u.Message.Text == "" {
+			continue
+		}
+		reply := b.handle(ctx, u.Message)
+		if reply == "" {
+			continue
+		}
+		if err = b.client.SendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+			b.l.Error("telegram: reply failed", "chat", u.Message.Chat.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// AlertFired sends the event to the chat that created the rule; rules created elsewhere are skipped.
+func (b *Bot) AlertFired(ctx context.Context, rule *entity.AlertRule, event *entity.AlertEvent) {
+	chatID, err := b.store.GetTelegramAlertChat(ctx, rule.ID)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		b.l.Error("telegram: alert chat lookup failed", "rule", rule.ID, "err", err)
+		return
+	}
+	text := "🔔 " + html.EscapeString(event.Message)
+	if rule.Name != "" {
+		text = "🔔 <b>" + html.EscapeString(rule.Name) + "</b>\n" + html.EscapeString(event.Message)
+	}
+	if err = b.client.SendMessage(ctx, chatID, text); err != nil {
+		b.l.Error("telegram: alert delivery failed", "rule", rule.ID, "chat", chatID, "err", err)
+	}
+}
+
+// handle answers a message; empty reply means the message is not a command.
+func (b *Bot) handle(ctx context.Context, msg *Message) string {
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// В группах команда приходит как /usd@exr_bot
+	cmd, _, _ := strings.Cut(strings.ToLower(fields[0][1:]), "@")
+	args := fields[1:]
+
+	var reply string
+	var err error
+	switch cmd {
+	case "start", "help":
+		return helpText
+	case "best":
+		reply, err = b.best(ctx, args)
+	case "convert":
+		reply, err = b.convert(ctx, args)
+	case "alert":
+		reply, err = b.createAlert(ctx, msg.Chat.ID, args)
+	case "alerts":
+		reply, err = b.alerts(ctx, msg.Chat.ID)
+	case "unalert":
+		reply, err = b.deleteAlert(ctx, msg.Chat.ID, args)
+	default:
+		if !isCurrencyCode(cmd) {
+			return "Неизвестная команда. " + helpText
+		}
+		reply, err = b.rates(ctx, strings.ToUpper(cmd), strings.Join(args, " "))
+	}
+
+	switch {
+	case errors.Is(err, internalErrors.ErrNotFound):
+		return "Нет данных."
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		return "Неверные параметры. " + helpText
+	case err != nil:
+		b.l.Error("telegram: command failed", "cmd", cmd, "chat", msg.Chat.ID, "err", err)
+		return "Не удалось выполнить команду, попробуйте позже."
+	}
+	return reply
+}
+
+const helpText = `Команды:
+/usd, /eur, /rub [город] — курсы банков
+/best [валюта] — где выгоднее купить и продать
+/convert 100 usd [kzt] — конвертация
+/alert usd sell below 480 [банк] — оповещение о курсе
+/alert usd change 1 [банк] — об изменении курса на 1%
+/alert usd spread above 2 [банк] — о спреде
+/alerts — ваши оповещения
+/unalert 5 — удалить оповещение`
+
+// rates renders the latest rates of the currency at every bank, as the web page does.
+func (b *Bot) rates(ctx context.Context, currency, city string) (string, error) {
+	rates, err := b.svc.GetRates(ctx, &exrate.ExchangeRateFilter{CurrencyCode: currency, City: city})
+	if err != nil {
+		return "", fmt.Errorf("get rates: %w", err)
+	}
+	names := b.sourceNames()
+
+	// Банк может котировать валюту в нескольких каналах, показываем самый свежий курс
+	type bank struct {
+		row rateRow
+		at  time.Time
+	}
+	banks := map[entity.SeriesKey]*bank{}
+	for _, r := range rates {
+		if r.Quote() != entity.QuoteKZT {
+			continue
+		}
+		key := entity.SeriesKey{Source: r.Source, Branch: r.Branch}
+		if prev, ok := banks[key]; ok && prev.at.After(r.CreatedAt) {
+			continue
+		}
+		row := rateRow{name: displayName(r.Source, r.BranchLabel(), names)}
+		if buy, err := strconv.ParseFloat(r.Buy, 64); err == nil && buy > 0 {
+			row.buy, row.buyChange = buy, r.BuyChangePrev/buy*100
+		}
+		if sell, err := strconv.ParseFloat(r.Sell, 64); err == nil && sell > 0 {
+			row.sell, row.sellChange = sell, r.SellChangePrev/sell*100
+		}
+		if row.buy == 0 && row.sell == 0 {
+			continue
+		}
+		banks[key] = &bank{row: row, at: r.CreatedAt}
+	}
+	if len(banks) == 0 {
+		return "", internalErrors.ErrNotFound
+	}
+
+	rows := make([]rateRow, 0, len(banks))
+	for _, bk := range banks {
+		rows = append(rows, bk.row)
+	}
+	// По возрастанию курса покупки, банки без покупки в конце
+	sort.Slice(rows, func(i, j int) bool {
+		bi, bj := rows[i].buy, rows[j].buy
+		if (bi == 0) != (bj == 0) {
+			return bj == 0
+		}
+		if bi != bj {
+			return bi < bj
+		}
+		return rows[i].name < rows[j].name
+	})
+	title := currency + "/KZT"
+	if city != "" {
+		title += ", " + city
+	}
+	if len(rows) > maxTableRows {
+		title += fmt.Sprintf(" (первые %d из %d)", maxTableRows, len(rows))
+		rows = rows[:maxTableRows]
+	}
+	return rateTable(title, rows), nil
+}
+
+// best lists where to buy and sell the currency (USD by default) in every channel.
+func (b *Bot) best(ctx context.Context, args []string) (string, error) {
+	currency := "USD"
+	if len(args) > 0 {
+		if !isCurrencyCode(args[0]) {
+			return "", internalErrors.ErrInvalidArgument
+		}
+		currency = strings.ToUpper(args[0])
+	}
+	best, err := b.svc.BestRates(ctx, &exrate.ExchangeRateFilter{CurrencyCode: currency}, bestLimit)
+	if err != nil {
+		return "", fmt.Errorf("best rates: %w", err)
+	}
+	if len(best) == 0 {
+		return "", internalErrors.ErrNotFound
+	}
+	names := b.sourceNames()
+
+	var sb strings.Builder
+	sb.WriteString("<b>Лучшие курсы " + currency + "</b>")
+	for _, br := range best {
+		sb.WriteString("\n\n<i>" + html.EscapeString(channelLabel(br.Channel)) + "</i>\n")
+		sb.WriteString("Купить: " + offerLine(br.Buy, names) + "\n")
+		sb.WriteString("Продать: " + offerLine(br.Sell, names))
+	}
+	return sb.String(), nil
+}
+
+// convert exchanges an amount: /convert 100 usd [kzt].
+func (b *Bot) convert(ctx context.Context, args []string) (string, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return "", internalErrors.ErrInvalidArgument
+	}
+	amount, err := parseNumber(args[0])
+	if err != nil {
+		return "", err
+	}
+	to := entity.QuoteKZT
+	if len(args) == 3 {
+		to = args[2]
+	}
+	conv, err := b.svc.Convert(ctx, amount, args[1], to, &exrate.ExchangeRateFilter{})
+	if err != nil {
+		return "", fmt.Errorf("convert: %w", err)
+	}
+	if len(conv.Options) == 0 && conv.Official == nil {
+		return "", internalErrors.ErrNotFound
+	}
+	names := b.sourceNames()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<b>%s %s → %s</b>\n", formatAmount(conv.Amount), conv.From, conv.To)
+	if len(conv.Options) > 0 {
+		best := conv.Options[0]
+		fmt.Fprintf(&sb, "Лучший: %s %s — %s (%s)\n", formatAmount(best.Result), conv.To,
+			html.EscapeString(displayName(best.Source, entity.BranchLabel(best.Branch, best.BranchName), names)), html.EscapeString(channelLabel(best.Channel)))
+	}
+	if conv.Official != nil {
+		fmt.Fprintf(&sb, "По курсу НБРК: %s %s\n", formatAmount(conv.Official.Result), conv.To)
+	}
+	for i, o := range conv.Options {
+		if i == bestLimit*2 {
+			break
+		}
+		fmt.Fprintf(&sb, "\n%s, %s: %s", html.EscapeString(displayName(o.Source, entity.BranchLabel(o.Branch, o.BranchName), names)),
+			html.EscapeString(channelLabel(o.Channel)), formatAmount(o.Result))
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// createAlert subscribes the chat to an alert rule:
+//
+//	/alert usd sell below 480 [source]
+//	/alert usd change 1 [source]
+//	/alert usd spread above 2 [source]
+func (b *Bot) createAlert(ctx context.Context, chatID int64, args []string) (string, error) {
+	if len(args) < 3 {
+		return "", internalErrors.ErrInvalidArgument
+	}
+	rule := &entity.AlertRule{CurrencyCode: args[0], Cooldown: defaultCooldown}
+	rest := args[2:]
+	switch kind := strings.ToLower(args[1]); kind {
+	case entity.SideBuy, entity.SideSell:
+		rule.Kind, rule.Side = entity.AlertPrice, kind
+	case entity.AlertChange:
+		rule.Kind = entity.AlertChange
+	case entity.AlertSpread:
+		rule.Kind = entity.AlertSpread
+	default:
+		return "", internalErrors.ErrInvalidArgument
+	}
+	if rule.Kind != entity.AlertChange {
+		rule.Op, rest = strings.ToLower(rest[0]), rest[1:]
+	}
+	if len(rest) == 0 || len(rest) > 2 {
+		return "", internalErrors.ErrInvalidArgument
+	}
+	threshold, err := parseNumber(rest[0])
+	if err != nil {
+		return "", err
+	}
+	rule.Threshold = threshold
+	if len(rest) == 2 {
+		// Идентификаторы источников регистрозависимы, а в чате пишут как угодно
+		if rule.Source = b.sourceID(rest[1]); rule.Source == "" {
+			return "", fmt.Errorf("source %q: %w", rest[1], internalErrors.ErrInvalidArgument)
+		}
+	}
+
+	if err = b.svc.CreateAlertRule(ctx, rule); err != nil {
+		return "", fmt.Errorf("create alert rule: %w", err)
+	}
+	if err = b.store.AddTelegramAlert(ctx, rule.ID, chatID); err != nil {
+		// Правило без чата никому не нужно
+		if derr := b.svc.DeleteAlertRule(ctx, rule.ID); derr != nil {
+			err = errors.Join(err, derr)
+		}
+		return "", fmt.Errorf("link alert rule %d: %w", rule.ID, err)
+	}
+	return fmt.Sprintf("Оповещение #%d создано: %s", rule.ID, html.EscapeString(describeRule(rule))), nil
+}
+
+// alerts lists the chat's alert rules.
+func (b *Bot) alerts(ctx context.Context, chatID int64) (string, error) {
+	ids, err := b.store.GetTelegramAlertRules(ctx, chatID)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return "Оповещений нет. Создайте: /alert usd sell below 480", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get chat alert rules: %w", err)
+	}
+	rules, err := b.svc.AlertRules(ctx)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return "", fmt.Errorf("get alert rules: %w", err)
+	}
+	byID := make(map[int64]*entity.AlertRule, len(rules))
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<b>Ваши оповещения</b>")
+	for _, id := range ids {
+		r, ok := byID[id]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n#%d %s", r.ID, html.EscapeString(describeRule(r)))
+		if r.Firing {
+			sb.WriteString(" 🔔")
+		}
+	}
+	return sb.String(), nil
+}
+
+// deleteAlert removes one of the chat's alert rules: /unalert 5.
+func (b *Bot) deleteAlert(ctx context.Context, chatID int64, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", internalErrors.ErrInvalidArgument
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+	if err != nil {
+		return "", internalErrors.ErrInvalidArgument
+	}
+	// Чужие правила не трогаем и не выдаём, что они существуют
+	owner, err := b.store.GetTelegramAlertChat(ctx, id)
+	if errors.Is(err, internalErrors.ErrNotFound) || (err == nil && owner != chatID) {
+		return fmt.Sprintf("Оповещение #%d не найдено.", id), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get alert chat: %w", err)
+	}
+	if err = b.svc.DeleteAlertRule(ctx, id); err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return "", fmt.Errorf("delete alert rule %d: %w", id, err)
+	}
+	if err = b.store.DeleteTelegramAlert(ctx, id); err != nil {
+		return "", fmt.Errorf("unlink alert rule %d: %w", id, err)
+	}
+	return fmt.Sprintf("Оповещение #%d удалено.", id), nil
+}
+
+func (b *Bot) sourceNames() map[string]string {
+	names := map[string]string{}
+	for _, info := range b.svc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	return names
+}
+
+// sourceID returns the id of the source named by s regardless of case, or "" if there is none.
+func (b *Bot) sourceID(s string) string {
+	for _, info := range b.svc.Sources() {
+		if strings.EqualFold(info.ID, s) {
+			return info.ID
+		}
+	}
+	return ""
+}
+
+// describeRule renders a rule the way it is typed: "USD sell below 480 (halyk)".
+func describeRule(r *entity.AlertRule) string {
+	var s string
+	switch r.Kind {
+	case entity.AlertPrice:
+		s = fmt.Sprintf("%s %s %s %s", r.CurrencyCode, r.Side, r.Op, formatNumber(r.Threshold))
+	case entity.AlertChange:
+		s = fmt.Sprintf("%s change %s%%", r.CurrencyCode, formatNumber(r.Threshold))
+	default:
+		s = fmt.Sprintf("%s %s %s %s%%", r.CurrencyCode, r.Kind, r.Op, formatNumber(r.Threshold))
+	}
+	if r.Source != "" {
+		s += " (" + r.Source + ")"
+	}
+	return s
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNumber accepts a decimal comma, as people type it.
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("number %q: %w", s, internalErrors.ErrInvalidArgument)
+	}
+	return v, nil
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
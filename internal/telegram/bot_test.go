@@ -0,0 +1,376 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/application/logger"
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+const testToken = "123:secret"
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any)        {}
+func (nopLogger) Info(string, ...any)         {}
+func (nopLogger) Warn(string, ...any)         {}
+func (nopLogger) Error(string, ...any)        {}
+func (l nopLogger) With(...any) logger.Logger { return l }
+
+// fakeAPI отдаёт заготовленные updates и записывает отправленные сообщения
+type fakeAPI struct {
+	mu      sync.Mutex
+	updates []Update
+	offsets []string
+	sent    []sentMessage
+}
+
+type sentMessage struct {
+	ChatID    int64  `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.URL.Path {
+	case "/bot" + testToken + "/getUpdates":
+		f.offsets = append(f.offsets, r.URL.Query().Get("offset"))
+		updates := f.updates
+		f.updates = nil
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": updates})
+	case "/bot" + testToken + "/sendMessage":
+		var m sentMessage
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.sent = append(f.sent, m)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{"message_id": len(f.sent)}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": "Not Found"})
+	}
+}
+
+type mockService struct {
+	rates []*entity.ExchangeRate
+	best  []*entity.BestRate
+	conv  *entity.Conversion
+	rules []*entity.AlertRule
+
+	convArgs string
+}
+
+func (m *mockService) GetRates(_ context.Context, f *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+	var out []*entity.ExchangeRate
+	for _, r := range m.rates {
+		if r.CurrencyCode == f.CurrencyCode && (f.City == "" || r.City == "" || r.City == f.City) {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+func (m *mockService) Sources() []entity.SourceInfo {
+	return []entity.SourceInfo{{ID: "Halyk", Name: "Halyk Bank"}, {ID: "BCC", Name: "BCC"}}
+}
+
+func (m *mockService) BestRates(context.Context, *exrate.ExchangeRateFilter, int) ([]*entity.BestRate, error) {
+	if len(m.best) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.best, nil
+}
+
+func (m *mockService) Convert(
+	_ context.Context,
+	amount float64,
+	from, to string,
+	_ *exrate.ExchangeRateFilter,
+) (*entity.Conversion, error) {
+	m.convArgs = strings.Join([]string{formatNumber(amount), from, to}, " ")
+	if m.conv == nil {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.conv, nil
+}
+
+func (m *mockService) CreateAlertRule(_ context.Context, rule *entity.AlertRule) error {
+	if rule.Threshold <= 0 {
+		return internalErrors.ErrInvalidArgument
+	}
+	rule.CurrencyCode = strings.ToUpper(rule.CurrencyCode)
+	rule.ID = int64(len(m.rules) + 1)
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *mockService) AlertRules(context.Context) ([]*entity.AlertRule, error) {
+	if len(m.rules) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.rules, nil
+}
+
+func (m *mockService) DeleteAlertRule(_ context.Context, id int64) error {
+	for i, r := range m.rules {
+		if r.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return internalErrors.ErrNotFound
+}
+
+type memStore struct {
+	chats map[int64]int64
+}
+
+func (s *memStore) AddTelegramAlert(_ context.Context, ruleID, chatID int64) error {
+	s.chats[ruleID] = chatID
+	return nil
+}
+
+func (s *memStore) GetTelegramAlertChat(_ context.Context, ruleID int64) (int64, error) {
+	chatID, ok := s.chats[ruleID]
+	if !ok {
+		return 0, internalErrors.ErrNotFound
+	}
+	return chatID, nil
+}
+
+func (s *memStore) GetTelegramAlertRules(_ context.Context, chatID int64) ([]int64, error) {
+	var out []int64
+	for ruleID, c := range s.chats {
+		if c == chatID {
+			out = append(out, ruleID)
+		}
+	}
+	if len(out) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return out, nil
+}
+
+func (s *memStore) DeleteTelegramAlert(_ context.Context, ruleID int64) error {
+	delete(s.chats, ruleID)
+	return nil
+}
+
+func newTestBot(t *testing.T, svc *mockService) (*Bot, *fakeAPI, *memStore) {
+	t.Helper()
+	api := &fakeAPI{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	store := &memStore{chats: map[int64]int64{}}
+	client := NewClient(srv.URL+"/", testToken, srv.Client())
+	return NewBot(client, svc, store, nopLogger{}), api, store
+}
+
+func ask(b *Bot, chatID int64, text string) string {
+	return b.handle(context.Background(), &Message{Chat: Chat{ID: chatID}, Text: text})
+}
+
+func TestBot_Rates(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := &mockService{rates: []*entity.ExchangeRate{
+		{Source: "Halyk", CurrencyCode: "USD", Buy: "505", Sell: "510", BuyChangePrev: 1.5, CreatedAt: at},
+		{Source: "BCC", CurrencyCode: "USD", Buy: "503.5", Sell: "509", SellChangePrev: -2, CreatedAt: at},
+		// старый курс того же банка в другом канале не показываем
+		{Source: "BCC", Channel: entity.ChannelCard, CurrencyCode: "USD", Buy: "490", Sell: "520", CreatedAt: at.Add(-time.Hour)},
+		{Source: "kurs", Branch: "Обменник <Центр>", City: "Алматы", CurrencyCode: "USD", Sell: "508", CreatedAt: at},
+		{Source: "fed", CurrencyCode: "USD", QuoteCurrency: "RUB", Buy: "90", Sell: "91", CreatedAt: at},
+	}}
+	b, _, _ := newTestBot(t, svc)
+
+	got := ask(b, 1, "/usd@exr_bot")
+	if !strings.HasPrefix(got, "<b>USD/KZT</b>\n<pre>") {
+		t.Fatalf("unexpected header: %q", got)
+	}
+	lines := strings.Split(strings.TrimSuffix(strings.SplitN(got, "<pre>", 2)[1], "\n</pre>"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 3 banks, got %q", lines)
+	}
+	for i, want := range []string{"BCC", "Halyk Bank", "Обменник &lt;Цен…"} {
+		if !strings.HasPrefix(lines[i+1], want) {
+			t.Errorf("row %d = %q, want prefix %q", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[1], "503.50") || !strings.Contains(lines[1], "509.00 ↘-0.39%") {
+		t.Errorf("BCC row = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "505.00 ↗+0.30%") {
+		t.Errorf("Halyk row = %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "—") {
+		t.Errorf("exchange office without buy rate = %q", lines[3])
+	}
+	if strings.Contains(got, "90.00") {
+		t.Errorf("foreign quote leaked into the table: %q", got)
+	}
+
+	if got := ask(b, 1, "/eur"); got != "Нет данных." {
+		t.Errorf("/eur = %q", got)
+	}
+	if got := ask(b, 1, "hello"); got != "" {
+		t.Errorf("plain text answered: %q", got)
+	}
+	if got := ask(b, 1, "/whatever"); !strings.HasPrefix(got, "Неизвестная команда") {
+		t.Errorf("/whatever = %q", got)
+	}
+}
+
+func TestBot_BestAndConvert(t *testing.T) {
+	svc := &mockService{
+		best: []*entity.BestRate{{
+			CurrencyCode: "USD",
+			Channel:      entity.ChannelCash,
+			Buy:          []entity.Offer{{Source: "BCC", Rate: 509}, {Source: "kurs", Branch: "Обменник", City: "Алматы", Rate: 509.5}},
+			Sell:         []entity.Offer{{Source: "Halyk", Rate: 505}},
+		}},
+		conv: &entity.Conversion{
+			From: "USD", To: "KZT", Amount: 100,
+			Official: &entity.ConversionOption{Source: "nbrk", Result: 50700},
+			Options: []entity.ConversionOption{
+				{Source: "Halyk", Channel: entity.ChannelCash, Result: 50500},
+				{Source: "BCC", Channel: entity.ChannelCard, Result: 50350},
+			},
+		},
+	}
+	b, _, _ := newTestBot(t, svc)
+
+	got := ask(b, 1, "/best")
+	for _, want := range []string{"Лучшие курсы USD", "<i>наличные</i>", "Купить: BCC 509.00, Обменник (Алматы) 509.50", "Продать: Halyk Bank 505.00"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("/best missing %q in %q", want, got)
+		}
+	}
+	if got := ask(b, 1, "/best dollars"); !strings.HasPrefix(got, "Неверные параметры") {
+		t.Errorf("/best dollars = %q", got)
+	}
+
+	got = ask(b, 1, "/convert 100 usd")
+	if svc.convArgs != "100 usd KZT" {
+		t.Errorf("convert called with %q", svc.convArgs)
+	}
+	for _, want := range []string{"<b>100.00 USD → KZT</b>", "Лучший: 50500.00 KZT — Halyk Bank (наличные)", "По курсу НБРК: 50700.00 KZT", "BCC, по карте: 50350.00"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("/convert missing %q in %q", want, got)
+		}
+	}
+	ask(b, 1, "/convert 1,5 eur usd")
+	if svc.convArgs != "1.5 eur usd" {
+		t.Errorf("convert called with %q", svc.convArgs)
+	}
+	for _, bad := range []string{"/convert", "/convert abc usd", "/convert -1 usd", "/convert 1 usd kzt extra"} {
+		if got := ask(b, 1, bad); !strings.HasPrefix(got, "Неверные параметры") {
+			t.Errorf("%s = %q", bad, got)
+		}
+	}
+}
+
+func TestBot_Alerts(t *testing.T) {
+	svc := &mockService{}
+	b, api, store := newTestBot(t, svc)
+
+	got := ask(b, 7, "/alert usd sell below 480,5 halyk")
+	if got != "Оповещение #1 создано: USD sell below 480.5 (Halyk)" {
+		t.Fatalf("/alert = %q", got)
+	}
+	r := svc.rules[0]
+	if r.Kind != entity.AlertPrice || r.Side != entity.SideSell || r.Op != entity.AlertBelow || r.Threshold != 480.5 ||
+		r.Source != "Halyk" || r.Cooldown != defaultCooldown {
+		t.Errorf("unexpected rule %+v", r)
+	}
+	if got := ask(b, 7, "/alert eur change 1"); got != "Оповещение #2 создано: EUR change 1%" {
+		t.Errorf("/alert change = %q", got)
+	}
+	if got := ask(b, 8, "/alert usd spread above 2"); got != "Оповещение #3 создано: USD spread above 2%" {
+		t.Errorf("/alert spread = %q", got)
+	}
+	for _, bad := range []string{"/alert usd", "/alert usd hold below 1", "/alert usd sell below", "/alert usd change 0", "/alert usd change 1 nobank"} {
+		if got := ask(b, 7, bad); !strings.HasPrefix(got, "Неверные параметры") {
+			t.Errorf("%s = %q", bad, got)
+		}
+	}
+
+	got = ask(b, 7, "/alerts")
+	if !strings.Contains(got, "#1 USD sell below 480.5 (Halyk)") || !strings.Contains(got, "#2 EUR change 1%") ||
+		strings.Contains(got, "#3") {
+		t.Errorf("/alerts = %q", got)
+	}
+
+	// Чужое оповещение удалить нельзя
+	if got := ask(b, 7, "/unalert 3"); got != "Оповещение #3 не найдено." {
+		t.Errorf("/unalert foreign = %q", got)
+	}
+	if got := ask(b, 7, "/unalert 1"); got != "Оповещение #1 удалено." {
+		t.Errorf("/unalert = %q", got)
+	}
+	if _, ok := store.chats[1]; ok || len(svc.rules) != 2 {
+		t.Errorf("rule 1 not deleted: chats %v, rules %d", store.chats, len(svc.rules))
+	}
+
+	// Оповещение уходит в чат, создавший правило
+	event := &entity.AlertEvent{Message: "Halyk, cash USD: продажа 479.50 ниже 480.5"}
+	b.AlertFired(context.Background(), svc.rules[1], event)
+	b.AlertFired(context.Background(), &entity.AlertRule{ID: 99}, event)
+	if len(api.sent) != 1 || api.sent[0].ChatID != 8 || api.sent[0].ParseMode != "HTML" ||
+		api.sent[0].Text != "🔔 "+event.Message {
+		t.Errorf("unexpected alert messages %+v", api.sent)
+	}
+}
+
+func TestBot_PollOnce(t *testing.T) {
+	svc := &mockService{}
+	b, api, _ := newTestBot(t, svc)
+	api.updates = []Update{
+		{UpdateID: 10, Message: &Message{Chat: Chat{ID: 5}, Text: "/help"}},
+		{UpdateID: 11},
+		{UpdateID: 12, Message: &Message{Chat: Chat{ID: 6}, Text: "спасибо"}},
+	}
+
+	ctx := context.Background()
+	if err := b.pollOnce(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.pollOnce(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(api.offsets, ",") != "0,13" {
+		t.Errorf("offsets = %v", api.offsets)
+	}
+	if len(api.sent) != 1 || api.sent[0].ChatID != 5 || api.sent[0].Text != helpText {
+		t.Errorf("unexpected replies %+v", api.sent)
+	}
+}
+
+func TestClient_ErrorHidesToken(t *testing.T) {
+	srv := httptest.NewServer(&fakeAPI{})
+	defer srv.Close()
+
+	err := NewClient(srv.URL, "wrong", srv.Client()).SendMessage(context.Background(), 1, "hi")
+	if err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Fatalf("expected api error, got %v", err)
+	}
+
+	srv.Close()
+	err = NewClient(srv.URL, testToken, srv.Client()).SendMessage(context.Background(), 1, "hi")
+	if err == nil || strings.Contains(err.Error(), testToken) {
+		t.Fatalf("expected error without token, got %v", err)
+	}
+}
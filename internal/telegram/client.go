@@ -0,0 +1,133 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the public Bot API; tests and self-hosted Bot API servers use their own.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// Update is an incoming update of the Bot API; only messages are used.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a chat message.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat identifies the chat a message came from.
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// apiResponse is the envelope of every Bot API response.
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// Client calls Bot API methods at {base}/bot{token}/{method}.
+type Client struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a Bot API client. The HTTP client timeout must exceed the long polling timeout.
+func NewClient(base, token string, client *http.Client) *Client {
+	return &Client{base: strings.TrimRight(base, "/"), token: token, client: client}
+}
+
+// GetUpdates long-polls for updates after offset, waiting up to timeout for new ones.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	q := url.Values{}
+	q.Set("offset", strconv.FormatInt(offset, 10))
+	q.Set("timeout", strconv.Itoa(int(timeout/time.Second)))
+	q.Set("allowed_updates", `["message"]`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.method("getUpdates")+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var updates []Update
+	if err = c.do(req, &updates); err != nil {
+		return nil, fmt.Errorf("getUpdates: %w", err)
+	}
+	return updates, nil
+}
+
+// SendMessage sends an HTML-formatted message to the chat.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.method("sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err = c.do(req, nil); err != nil {
+		return fmt.Errorf("sendMessage: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) method(name string) string {
+	return c.base + "/bot" + c.token + "/" + name
+}
+
+func (c *Client) do(req *http.Request, result any) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// В ошибке net/http есть URL запроса, а в нём токен бота
+		return fmt.Errorf("request failed: %w", redact(err, c.token))
+	}
+	defer resp.Body.Close()
+
+	var r apiResponse
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decode response (%s): %w", resp.Status, err)
+	}
+	if !r.OK {
+		return fmt.Errorf("api error (%s): %s", resp.Status, r.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// redactedError hides the bot token in an error message.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+func redact(err error, token string) error {
+	if token == "" || !strings.Contains(err.Error(), token) {
+		return err
+	}
+	return &redactedError{msg: strings.ReplaceAll(err.Error(), token, "<token>"), err: err}
+}
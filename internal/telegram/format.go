@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Mi7teR/exr/internal/entity"
+)
+
+// maxNameWidth trims long bank and exchange office names in tables.
+const maxNameWidth = 14
+
+// rateRow is a bank line of a rate table: the latest rate and its change in percent, as on the web page.
+type rateRow struct {
+	name       string
+	buy, sell  float64
+	buyChange  float64
+	sellChange float64
+}
+
+// rateCell formats a rate like RateWithIndicator on the web page: value, arrow and change.
+func rateCell(rate, change float64) string {
+	switch {
+	case rate <= 0:
+		return "—"
+	case change > 0:
+		return fmt.Sprintf("%.2f ↗+%.2f%%", rate, change)
+	case change < 0:
+		return fmt.Sprintf("%.2f ↘%.2f%%", rate, change)
+	}
+	return fmt.Sprintf("%.2f", rate)
+}
+
+// rateTable renders rows as a monospace table with a title line.
+func rateTable(title string, rows []rateRow) string {
+	cells := make([][3]string, 0, len(rows)+1)
+	cells = append(cells, [3]string{"Банк", "Покупка", "Продажа"})
+	for _, r := range rows {
+		cells = append(cells, [3]string{truncate(r.name, maxNameWidth), rateCell(r.buy, r.buyChange), rateCell(r.sell, r.sellChange)})
+	}
+	var width [3]int
+	for _, c := range cells {
+		for i, v := range c {
+			width[i] = max(width[i], utf8.RuneCountInString(v))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<b>" + html.EscapeString(title) + "</b>\n<pre>")
+	for _, c := range cells {
+		line := pad(c[0], width[0]) + "  " + pad(c[1], width[1]) + "  " + c[2]
+		b.WriteString(html.EscapeString(strings.TrimRight(line, " ")) + "\n")
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// offerLine formats the best offers of one side: "Halyk 505.00, BCC 505.50".
+func offerLine(offers []entity.Offer, names map[string]string) string {
+	if len(offers) == 0 {
+		return "—"
+	}
+	parts := make([]string, 0, len(offers))
+	for _, o := range offers {
+		name := displayName(o.Source, entity.BranchLabel(o.Branch, o.BranchName), names)
+		if o.City != "" {
+			name += " (" + o.City + ")"
+		}
+		parts = append(parts, fmt.Sprintf("%s %.2f", html.EscapeString(name), o.Rate))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// displayName names a bank as the web page does: the exchange office, the source display name or its id.
+func displayName(source, branch string, names map[string]string) string {
+	if branch != "" {
+		return branch
+	}
+	if n := names[source]; n != "" {
+		return n
+	}
+	return source
+}
+
+// channelLabel names a rate channel like the web page.
+func channelLabel(channel string) string {
+	switch channel {
+	case entity.ChannelCash:
+		return "наличные"
+	case entity.ChannelNonCash:
+		return "безналичные"
+	case entity.ChannelCard:
+		return "по карте"
+	}
+	return channel
+}
+
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Размеры SVG-графика и отступы области построения под подписи осей
+const (
+	chartWidth  = 800
+	chartHeight = 320
+	chartLeft   = 60
+	chartRight  = 16
+	chartTop    = 12
+	chartBottom = 28
+)
+
+// chartColors are assigned to series in order.
+var chartColors = []string{"#2563eb", "#dc2626", "#16a34a", "#d97706", "#7c3aed", "#0891b2", "#db2777", "#4b5563"}
+
+// ChartPoint is a value of a series at a moment.
+type ChartPoint struct {
+	At    time.Time
+	Value float64
+}
+
+// ChartSeries is a series to plot. Rates are change events, so a value holds until the next one.
+type ChartSeries struct {
+	Name   string
+	Dashed bool // опорная линия, например официальный курс
+	Points []ChartPoint
+}
+
+// ChartLine is a plotted series.
+type ChartLine struct {
+	Name   string
+	Color  string
+	Dashed bool
+	Points string // координаты для <polyline>
+}
+
+// ChartTick is an axis label at a position in SVG coordinates.
+type ChartTick struct {
+	Pos   float64
+	Label string
+}
+
+// Chart is a line chart rendered on the server as SVG.
+type Chart struct {
+	Width, Height            int
+	Left, Right, Top, Bottom float64 // границы области построения
+	Lines                    []ChartLine
+	XTicks, YTicks           []ChartTick
+}
+
+// Empty reports whether there is nothing to plot.
+func (c Chart) Empty() bool {
+	return len(c.Lines) == 0
+}
+
+// NewChart plots series over [from, to] as step lines; each line extends to the last point or to.
+func NewChart(series []ChartSeries, from, to time.Time) Chart {
+	c := Chart{
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartLeft,
+		Right:  chartWidth - chartRight,
+		Top:    chartTop,
+		Bottom: chartHeight - chartBottom,
+	}
+	if !to.After(from) {
+		return c
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, p := range s.Points {
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return c
+	}
+	// Поля сверху и снизу, чтобы линии не прилипали к краям; ровная линия — в середине
+	pad := (hi - lo) * 0.05
+	if pad == 0 {
+		pad = math.Max(math.Abs(hi)*0.01, 1)
+	}
+	lo, hi = lo-pad, hi+pad
+
+	x := func(t time.Time) float64 {
+		return c.Left + (c.Right-c.Left)*float64(t.Sub(from))/float64(to.Sub(from))
+	}
+	y := func(v float64) float64 {
+		return c.Bottom - (c.Bottom-c.Top)*(v-lo)/(hi-lo)
+	}
+
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		var b strings.Builder
+		for i, p := range s.Points {
+			px := x(p.At)
+			if i > 0 {
+				// ступенька: прежнее значение держится до нового изменения
+				fmt.Fprintf(&b, " %.1f,%.1f ", px, y(s.Points[i-1].Value))
+			}
+			fmt.Fprintf(&b, "%.1f,%.1f", px, y(p.Value))
+		}
+		fmt.Fprintf(&b, " %.1f,%.1f", c.Right, y(s.Points[len(s.Points)-1].Value))
+		c.Lines = append(c.Lines, ChartLine{
+			Name:   s.Name,
+			Color:  chartColors[len(c.Lines)%len(chartColors)],
+			Dashed: s.Dashed,
+			Points: b.String(),
+		})
+	}
+
+	const yTicks = 5
+	for i := 0; i < yTicks; i++ {
+		v := lo + (hi-lo)*float64(i)/float64(yTicks-1)
+		c.YTicks = append(c.YTicks, ChartTick{Pos: y(v), Label: fmt.Sprintf("%.2f", v)})
+	}
+	layout := "02.01"
+	if to.Sub(from) <= 48*time.Hour {
+		layout = "02.01 15:04"
+	}
+	const xTicks = 6
+	for i := 0; i < xTicks; i++ {
+		t := from.Add(time.Duration(float64(to.Sub(from)) * float64(i) / float64(xTicks-1)))
+		c.XTicks = append(c.XTicks, ChartTick{Pos: x(t), Label: t.Local().Format(layout)})
+	}
+	return c
+}
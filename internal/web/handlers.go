@@ -1,7 +1,11 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/a-h/templ"
 )
@@ -11,3 +15,55 @@ func RenderHTML(w http.ResponseWriter, r *http.Request, c templ.Component) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_ = c.Render(r.Context(), w)
 }
+
+// tabPath builds tab content URL preserving the selected city.
+func tabPath(tab, city string) string {
+	if city == "" {
+		return "/c/" + tab
+	}
+	return "/c/" + tab + "?city=" + url.QueryEscape(city)
+}
+
+// sideLabel names a rate side for the UI.
+func sideLabel(side string) string {
+	if side == "sell" {
+		return "продажа"
+	}
+	return "покупка"
+}
+
+// channelLabel names a rate channel for the UI.
+func channelLabel(channel string) string {
+	switch channel {
+	case "cash":
+		return "наличные"
+	case "non_cash":
+		return "безналичные"
+	case "card":
+		return "по карте"
+	}
+	return channel
+}
+
+// ageLabel formats how long ago a rate was confirmed.
+func ageLabel(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "только что"
+	case d < time.Hour:
+		return fmt.Sprintf("%d мин назад", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d ч назад", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d дн назад", int(d.Hours()/24))
+}
+
+// viewBox returns the SVG viewBox of a chart.
+func viewBox(c Chart) string {
+	return fmt.Sprintf("0 0 %d %d", c.Width, c.Height)
+}
+
+// coord formats an SVG coordinate.
+func coord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
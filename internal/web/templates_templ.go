@@ -8,8 +8,10 @@ package web
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import "strings"
+
 // Главная страница с вкладками валют и кнопкой обновления (новая верстка)
-func IndexPage(activeTab string) templ.Component {
+func IndexPage(activeTab string, cities []string, city string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
@@ -27,10 +29,68 @@ func IndexPage(activeTab string) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<!doctype html><html lang=\"ru\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>Курсы валют</title><script src=\"https://cdn.tailwindcss.com\"></script><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><style>\n            .htmx-indicator { display: none; }\n            .htmx-request .htmx-indicator { display: inline; }\n            .htmx-request.htmx-indicator { display: inline; }\n        </style><script>\n            function setActiveTab(clickedOrName) {\n                const tabName = typeof clickedOrName === 'string' ? clickedOrName : clickedOrName.getAttribute('data-tab');\n                const tabButtons = document.querySelectorAll('.tab-button');\n                tabButtons.forEach(btn => {\n                    const isActive = btn.getAttribute('data-tab') === tabName;\n                    btn.classList.toggle('text-blue-600', isActive);\n                    btn.classList.toggle('border-b-2', isActive);\n                    btn.classList.toggle('border-blue-600', isActive);\n                    btn.classList.toggle('text-gray-600', !isActive);\n                });\n                const refreshBtn = document.getElementById('refresh-btn');\n                if (refreshBtn) refreshBtn.setAttribute('data-tab', tabName);\n            }\n\n\n\n            function getCurrentTabName() {\n                const active = document.querySelector('.tab-button.text-blue-600');\n                return active ? active.getAttribute('data-tab') : '{ activeTab }' || 'usd';\n            }\n\n            function refreshCurrentTab() {\n                const tab = getCurrentTabName();\n                const url = '/c/' + tab;\n                htmx.ajax('GET', url, { target: '#tab-content', swap: 'innerHTML', indicator: '#tab-loader' });\n            }\n\n            document.addEventListener('DOMContentLoaded', function(){\n                setActiveTab('{ activeTab }');\n                const ts = new Date().toLocaleString('ru-RU');\n                const el = document.getElementById('last-updated');\n                if (el) el.textContent = ts;\n            });\n\n            // Обновляем timestamp и синхронизируем кнопку обновления после подгрузки таба\n            document.addEventListener('htmx:afterSwap', function (e) {\n                if (e.target && e.target.id === 'tab-content') {\n                    const ts = new Date().toLocaleString('ru-RU');\n                    const el = document.getElementById('last-updated');\n                    if (el) el.textContent = ts;\n                }\n            });\n\n            // Синхронизация активного таба по ответу\n            document.addEventListener('htmx:afterRequest', function (event) {\n                if (!event.detail.xhr || !event.detail.xhr.responseURL) return;\n                const url = new URL(event.detail.xhr.responseURL, window.location.origin);\n                const pathMatch = url.pathname.match(/^\\/c\\/(\\w+)/);\n                if (pathMatch) setActiveTab(pathMatch[1]);\n            });\n        </script></head><body class=\"bg-gray-50 min-h-screen py-8\"><div class=\"max-w-6xl mx-auto px-4\"><div class=\"flex flex-col sm:flex-row sm:items-center sm:justify-between mb-8\"><h1 class=\"text-2xl sm:text-3xl font-bold text-gray-800 mb-4 sm:mb-0\">Курсы валют Казахстана</h1>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<!doctype html><html lang=\"ru\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>Курсы валют</title><link rel=\"alternate\" type=\"application/atom+xml\" title=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs("Курс " + strings.ToUpper(activeTab) + ": Atom")
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 14, Col: 117}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" href=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs("/feeds/" + activeTab + ".atom")
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 14, Col: 158}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\"><link rel=\"alternate\" type=\"application/rss+xml\" title=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var4 string
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs("Курс " + strings.ToUpper(activeTab) + ": RSS")
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 15, Col: 115}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" href=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var5 string
+		templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs("/feeds/" + activeTab + ".rss")
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 15, Col: 155}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\"><script src=\"https://cdn.tailwindcss.com\"></script><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script src=\"https://unpkg.com/htmx.org@1.9.10/dist/ext/sse.js\"></script><style>\n            .htmx-indicator { display: none; }\n            .htmx-request .htmx-indicator { display: inline; }\n            .htmx-request.htmx-indicator { display: inline; }\n        </style><script>\n            function setActiveTab(clickedOrName) {\n                const tabName = typeof clickedOrName === 'string' ? clickedOrName : clickedOrName.getAttribute('data-tab');\n                const tabButtons = document.querySelectorAll('.tab-button');\n                tabButtons.forEach(btn => {\n                    const isActive = btn.getAttribute('data-tab') === tabName;\n                    btn.classList.toggle('text-blue-600', isActive);\n                    btn.classList.toggle('border-b-2', isActive);\n                    btn.classList.toggle('border-blue-600', isActive);\n                    btn.classList.toggle('text-gray-600', !isActive);\n                });\n                const refreshBtn = document.getElementById('refresh-btn');\n                if (refreshBtn) refreshBtn.setAttribute('data-tab', tabName);\n            }\n\n\n\n            function getCurrentTabName() {\n                const active = document.querySelector('.tab-button.text-blue-600');\n                return active ? active.getAttribute('data-tab') : '{ activeTab }' || 'usd';\n            }\n\n            // URL таба с учетом выбранного города\n            function tabURL(tab) {\n                const sel = document.getElementById('city-filter');\n                const city = sel ? sel.value : '';\n                return '/c/' + tab + (city ? '?city=' + encodeURIComponent(city) : '');\n            }\n\n            function loadTab(tab) {\n                setActiveTab(tab);\n                htmx.ajax('GET', tabURL(tab), { target: '#tab-content', swap: 'innerHTML', indicator: '#tab-loader' });\n            }\n\n            function refreshCurrentTab() {\n                loadTab(getCurrentTabName());\n            }\n\n            document.addEventListener('DOMContentLoaded', function(){\n                setActiveTab('{ activeTab }');\n                const ts = new Date().toLocaleString('ru-RU');\n                const el = document.getElementById('last-updated');\n                if (el) el.textContent = ts;\n            });\n\n            // Обновляем timestamp и синхронизируем кнопку обновления после подгрузки таба\n            // Таблица обновляется и по событиям SSE-потока\n            document.addEventListener('htmx:afterSwap', function (e) {\n                if (e.target && (e.target.id === 'tab-content' || e.target.hasAttribute('sse-swap'))) {\n                    const ts = new Date().toLocaleString('ru-RU');\n                    const el = document.getElementById('last-updated');\n                    if (el) el.textContent = ts;\n                }\n            });\n\n            // Синхронизация активного таба по ответу\n            document.addEventListener('htmx:afterRequest', function (event) {\n                if (!event.detail.xhr || !event.detail.xhr.responseURL) return;\n                const url = new URL(event.detail.xhr.responseURL, window.location.origin);\n                const pathMatch = url.pathname.match(/^\\/c\\/(\\w+)/);\n                if (pathMatch) setActiveTab(pathMatch[1]);\n            });\n        </script></head><body class=\"bg-gray-50 min-h-screen py-8\"><div class=\"max-w-6xl mx-auto px-4\"><div class=\"flex flex-col sm:flex-row sm:items-center sm:justify-between mb-8\"><h1 class=\"text-2xl sm:text-3xl font-bold text-gray-800 mb-4 sm:mb-0\">Курсы валют Казахстана</h1><div class=\"flex items-center gap-3\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if len(cities) > 0 {
+			templ_7745c5c3_Err = CityFilter(cities, city).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
 		templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, templ.ComponentScript{Call: "refreshCurrentTab()"})
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
@@ -39,12 +99,12 @@ func IndexPage(activeTab string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var2 templ.ComponentScript = templ.ComponentScript{Call: "refreshCurrentTab()"}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var2.Call)
+		var templ_7745c5c3_Var6 templ.ComponentScript = templ.ComponentScript{Call: "refreshCurrentTab()"}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var6.Call)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"flex items-center px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors\"><span>Обновить курсы</span></button></div><div id=\"currency-tabs\" class=\"bg-white rounded-lg shadow-lg overflow-hidden\"><div class=\"border-b border-gray-200\"><nav class=\"flex flex-wrap\">")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"flex items-center px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors\"><span>Обновить курсы</span></button></div></div><div id=\"currency-tabs\" class=\"bg-white rounded-lg shadow-lg overflow-hidden\"><div class=\"border-b border-gray-200\"><nav class=\"flex flex-wrap\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -56,12 +116,12 @@ func IndexPage(activeTab string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var3 string
-		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs("/c/" + activeTab)
+		var templ_7745c5c3_Var7 string
+		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(tabPath(activeTab, city))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 94, Col: 68}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 115, Col: 75}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -87,9 +147,9 @@ func CurrencyTabs(activeTab string) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var4 == nil {
-			templ_7745c5c3_Var4 = templ.NopComponent
+		templ_7745c5c3_Var8 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var8 == nil {
+			templ_7745c5c3_Var8 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div id=\"tabs\" class=\"w-full grid grid-cols-3\">")
@@ -129,23 +189,23 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var5 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var5 == nil {
-			templ_7745c5c3_Var5 = templ.NopComponent
+		templ_7745c5c3_Var9 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var9 == nil {
+			templ_7745c5c3_Var9 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		var templ_7745c5c3_Var6 = []any{"tab-button flex-1 py-3 sm:py-4 px-3 sm:px-6 text-center font-medium hover:text-gray-800 hover:bg-gray-50 focus:outline-none focus:ring-2 focus:ring-blue-500 text-sm sm:text-base " + (func() string {
+		var templ_7745c5c3_Var10 = []any{"tab-button flex-1 py-3 sm:py-4 px-3 sm:px-6 text-center font-medium hover:text-gray-800 hover:bg-gray-50 focus:outline-none focus:ring-2 focus:ring-blue-500 text-sm sm:text-base " + (func() string {
 			if isActive {
 				return "text-blue-600 border-b-2 border-blue-600"
 			} else {
 				return "text-gray-600"
 			}
 		})()}
-		templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var6...)
+		templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var10...)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, templ.ComponentScript{Call: "(function(){ setActiveTab('" + tab + "'); htmx.ajax('GET','/c/" + tab + "',{target:'#tab-content',swap:'innerHTML',indicator:'#tab-loader'}); })()"})
+		templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, templ.ComponentScript{Call: "loadTab('" + tab + "')"})
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -153,12 +213,12 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var7 string
-		templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var6).String())
+		var templ_7745c5c3_Var11 string
+		templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var10).String())
 		if templ_7745c5c3_Err != nil {
 			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 1, Col: 0}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -166,8 +226,8 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var8 templ.ComponentScript = templ.ComponentScript{Call: "(function(){ setActiveTab('" + tab + "'); htmx.ajax('GET','/c/" + tab + "',{target:'#tab-content',swap:'innerHTML',indicator:'#tab-loader'}); })()"}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var8.Call)
+		var templ_7745c5c3_Var12 templ.ComponentScript = templ.ComponentScript{Call: "loadTab('" + tab + "')"}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var12.Call)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -175,12 +235,12 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var9 string
-		templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(tab)
+		var templ_7745c5c3_Var13 string
+		templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(tab)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 123, Col: 22}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 144, Col: 22}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -188,12 +248,12 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var10 string
-		templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs("/c/" + tab)
+		var templ_7745c5c3_Var14 string
+		templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs("/c/" + tab)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 124, Col: 33}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 145, Col: 33}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -201,12 +261,12 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var11 string
-		templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(fullName)
+		var templ_7745c5c3_Var15 string
+		templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(fullName)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 126, Col: 49}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 147, Col: 49}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -214,12 +274,12 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var12 string
-		templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(shortName)
+		var templ_7745c5c3_Var16 string
+		templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(shortName)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 127, Col: 43}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 148, Col: 43}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -231,4 +291,77 @@ func TabButton(tab, fullName, shortName string, isActive bool) templ.Component {
 	})
 }
 
+// Фильтр по городу: курсы обменников зависят от города, банковские показываются везде
+func CityFilter(cities []string, selected string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var17 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var17 == nil {
+			templ_7745c5c3_Var17 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<select id=\"city-filter\" onchange=\"refreshCurrentTab()\" class=\"px-3 py-2 border border-gray-300 rounded-lg bg-white text-gray-700 focus:outline-none focus:ring-2 focus:ring-blue-500\"><option value=\"\">Все города</option> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, c := range cities {
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<option value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var18 string
+			templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs(c)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 159, Col: 29}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if c == selected {
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(" selected")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var19 string
+			templ_7745c5c3_Var19, templ_7745c5c3_Err = templ.JoinStringErrs(c)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates.templ`, Line: 159, Col: 63}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</option>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</select>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return templ_7745c5c3_Err
+	})
+}
+
 var _ = templruntime.GeneratedTemplate
@@ -1,5 +1,7 @@
 package web
 
+import "time"
+
 type CurrencyRate struct {
 	Buy        float64
 	Sell       float64
@@ -16,5 +18,131 @@ type Rates struct {
 type Bank struct {
 	Name     string
 	Location string
+	Address  string // адрес обменника, пусто для банков
+	Homepage string
+	Logo     string
 	Rates    Rates
 }
+
+// Anomaly is a row of the anomalies page.
+type Anomaly struct {
+	Source       string
+	Channel      string
+	CurrencyCode string
+	Side         string
+	Value        float64
+	Baseline     float64
+	DeviationPct float64
+	Score        float64
+	RateAt       time.Time
+	MarketMove   bool // другие источники сдвинулись так же — вероятно, движение рынка
+}
+
+// Offer is a place to exchange currency on the best rates page.
+type Offer struct {
+	Name     string
+	Location string
+	Rate     float64
+	Age      time.Duration // сколько прошло с последнего подтверждения курса
+}
+
+// BestRate is a best rates card: one currency in one channel.
+type BestRate struct {
+	CurrencyCode string
+	Channel      string
+	Buy          []Offer
+	Sell         []Offer
+}
+
+// ConvertForm is the state of the converter form.
+type ConvertForm struct {
+	Amount     string
+	From       string
+	To         string
+	City       string
+	Currencies []string
+}
+
+// ConvertOption is an amount exchanged at one bank.
+type ConvertOption struct {
+	Name    string
+	Channel string
+	City    string
+	Rate    float64
+	Result  float64
+	Via     string        // KZT для обмена в два шага
+	Age     time.Duration // возраст самого старого из использованных курсов
+}
+
+// ConvertResult is the converter output; the first option is the best one.
+type ConvertResult struct {
+	Error    string
+	Official *ConvertOption
+	Options  []ConvertOption
+}
+
+// HistorySource is a bank that can be plotted on the history page.
+type HistorySource struct {
+	ID   string
+	Name string
+}
+
+// HistoryForm is the state of the history page form.
+type HistoryForm struct {
+	Currency   string
+	From       string // YYYY-MM-DD
+	To         string
+	Sources    []string // выбранные источники
+	Sides      []string // buy, sell
+	Interval   string   // hour, day, week, month; пусто — каждое изменение
+	Currencies []string
+	Options    []HistorySource
+}
+
+// Has reports whether v is among the selected values.
+func Has(selected []string, v string) bool {
+	for _, s := range selected {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SpreadsForm is the state of the spreads page form.
+type SpreadsForm struct {
+	Currency   string
+	Channel    string
+	City       string
+	From       string // YYYY-MM-DD
+	To         string
+	Currencies []string
+	Cities     []string
+}
+
+// SpreadRow is a bank in the spread ranking, narrowest spread first.
+type SpreadRow struct {
+	Name    string
+	Channel string
+	City    string
+	Buy     float64
+	Sell    float64
+	Abs     float64
+	Pct     float64
+	Age     time.Duration
+}
+
+// SpreadWidening is a bank whose spread widened on a volatile day.
+type SpreadWidening struct {
+	Name        string
+	Channel     string
+	AvgPct      float64
+	BaselinePct float64
+}
+
+// VolatileDay is a day with a large official rate move.
+type VolatileDay struct {
+	Day     time.Time
+	MovePct float64
+	Widened []SpreadWidening
+}
@@ -0,0 +1,185 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// maxAlertRuleBody limits the size of a rule sent to POST /api/alerts.
+const maxAlertRuleBody = 4 << 10
+
+// alertRuleDTO is the JSON representation of an alert rule; cooldown is a Go duration ("30m", "2h").
+type alertRuleDTO struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Kind        string     `json:"kind"`
+	Currency    string     `json:"currency"`
+	Source      string     `json:"source,omitempty"`
+	Channel     string     `json:"channel,omitempty"`
+	Side        string     `json:"side,omitempty"`
+	Op          string     `json:"op,omitempty"`
+	Threshold   float64    `json:"threshold"`
+	Cooldown    string     `json:"cooldown,omitempty"`
+	Firing      bool       `json:"firing"`
+	LastFiredAt *time.Time `json:"last_fired_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newAlertRuleDTO(rule *entity.AlertRule) alertRuleDTO {
+	dto := alertRuleDTO{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Kind:      rule.Kind,
+		Currency:  rule.CurrencyCode,
+		Source:    rule.Source,
+		Channel:   rule.Channel,
+		Side:      rule.Side,
+		Op:        rule.Op,
+		Threshold: rule.Threshold,
+		Firing:    rule.Firing,
+		CreatedAt: rule.CreatedAt,
+	}
+	if rule.Cooldown > 0 {
+		dto.Cooldown = rule.Cooldown.String()
+	}
+	if !rule.LastFiredAt.IsZero() {
+		at := rule.LastFiredAt
+		dto.LastFiredAt = &at
+	}
+	return dto
+}
+
+// alertEventDTO is the JSON representation of an alert firing.
+type alertEventDTO struct {
+	ID       int64     `json:"id"`
+	RuleID   int64     `json:"rule_id"`
+	Source   string    `json:"source"`
+	Channel  string    `json:"channel,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Currency string    `json:"currency"`
+	Value    float64   `json:"value"`
+	Message  string    `json:"message"`
+	RateAt   time.Time `json:"rate_at"`
+	FiredAt  time.Time `json:"fired_at"`
+}
+
+// handleAPIAlertRules lists alert rules with their firing state.
+func (s *Server) handleAPIAlertRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.uc.AlertRules(r.Context())
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api alert rules failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]alertRuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, newAlertRuleDTO(rule))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleAPICreateAlertRule creates an alert rule from the JSON body.
+func (s *Server) handleAPICreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	var in alertRuleDTO
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertRuleBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", internalErrors.ErrInvalidArgument, err))
+		return
+	}
+	rule := &entity.AlertRule{
+		Name:         in.Name,
+		Kind:         in.Kind,
+		CurrencyCode: in.Currency,
+		Source:       in.Source,
+		Channel:      in.Channel,
+		Side:         in.Side,
+		Op:           in.Op,
+		Threshold:    in.Threshold,
+	}
+	if in.Cooldown != "" {
+		d, err := time.ParseDuration(in.Cooldown)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("cooldown: %w", internalErrors.ErrInvalidArgument))
+			return
+		}
+		rule.Cooldown = d
+	}
+
+	err := s.uc.CreateAlertRule(r.Context(), rule)
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		writeJSONError(w, http.StatusBadRequest, err)
+	case err != nil:
+		s.l.Error("api create alert rule failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+	default:
+		writeJSON(w, http.StatusCreated, newAlertRuleDTO(rule))
+	}
+}
+
+// handleAPIDeleteAlertRule removes an alert rule with its events.
+func (s *Server) handleAPIDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+	err = s.uc.DeleteAlertRule(r.Context(), id)
+	switch {
+	case errors.Is(err, internalErrors.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err)
+	case err != nil:
+		s.l.Error("api delete alert rule failed", "id", id, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAPIAlertEvents returns alert firings, newest first. Query: from, to (fired at).
+func (s *Server) handleAPIAlertEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := parseAPITime(q.Get("from"), false)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseAPITime(q.Get("to"), true)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := s.uc.AlertEvents(r.Context(), from, to)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api alert events failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]alertEventDTO, 0, len(events))
+	for _, e := range events {
+		out = append(out, alertEventDTO{
+			ID:       e.ID,
+			RuleID:   e.RuleID,
+			Source:   e.Source,
+			Channel:  e.Channel,
+			Branch:   e.Branch,
+			Currency: e.CurrencyCode,
+			Value:    e.Value,
+			Message:  e.Message,
+			RateAt:   e.RateAt,
+			FiredAt:  e.FiredAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,88 @@
+package webserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+const (
+	// anomalyPageDays is how far back the anomalies page looks.
+	anomalyPageDays = 7
+	// corroborationWindow is how close in time another source must move the same way
+	// for an anomaly to count as a market move rather than a single-source glitch.
+	corroborationWindow = 6 * time.Hour
+)
+
+// corroboratedBy returns other sources that moved the same currency side in the same direction
+// around the same time.
+func corroboratedBy(a *entity.Anomaly, all []*entity.Anomaly) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, o := range all {
+		if o.Source == a.Source || o.CurrencyCode != a.CurrencyCode || o.Side != a.Side {
+			continue
+		}
+		if (o.Score > 0) != (a.Score > 0) {
+			continue
+		}
+		if d := o.RateAt.Sub(a.RateAt); d > corroborationWindow || d < -corroborationWindow {
+			continue
+		}
+		if _, ok := seen[o.Source]; ok {
+			continue
+		}
+		seen[o.Source] = struct{}{}
+		out = append(out, o.Source)
+	}
+	return out
+}
+
+func (s *Server) anomalies(ctx context.Context, from, to time.Time) ([]*entity.Anomaly, error) {
+	list, err := s.uc.GetAnomalies(ctx, from, to)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, nil
+	}
+	return list, err
+}
+
+func (s *Server) handleAnomaliesPage(w http.ResponseWriter, r *http.Request) {
+	list, err := s.anomalies(r.Context(), time.Now().AddDate(0, 0, -anomalyPageDays), time.Time{})
+	if err != nil {
+		s.l.Error("get anomalies failed", "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := map[string]string{}
+	for _, info := range s.uc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	rows := make([]web.Anomaly, 0, len(list))
+	for _, a := range list {
+		name := names[a.Source]
+		if name == "" {
+			name = a.Source
+		}
+		if a.Branch != "" {
+			name += " · " + entity.BranchLabel(a.Branch, a.BranchName)
+		}
+		rows = append(rows, web.Anomaly{
+			Source:       name,
+			Channel:      a.Channel,
+			CurrencyCode: a.CurrencyCode,
+			Side:         a.Side,
+			Value:        a.Value,
+			Baseline:     a.Baseline,
+			DeviationPct: a.DeviationPct(),
+			Score:        a.Score,
+			RateAt:       a.RateAt,
+			MarketMove:   len(corroboratedBy(a, list)) > 0,
+		})
+	}
+	web.RenderHTML(w, r, web.AnomaliesPage(rows, anomalyPageDays))
+}
@@ -0,0 +1,730 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+const apiDateLayout = "2006-01-02"
+
+// rateDTO is the JSON representation of an exchange rate in the API.
+type rateDTO struct {
+	Source     string    `json:"source"`
+	Channel    string    `json:"channel,omitempty"`
+	Branch     string    `json:"branch,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	City       string    `json:"city,omitempty"`
+	Address    string    `json:"address,omitempty"`
+	Currency   string    `json:"currency"`
+	Quote      string    `json:"quote"`
+	Buy        string    `json:"buy"`
+	Sell       string    `json:"sell"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newRateDTO(r *entity.ExchangeRate) rateDTO {
+	return rateDTO{
+		Source:     r.Source,
+		Channel:    r.Channel,
+		Branch:     r.Branch,
+		BranchName: r.BranchName,
+		City:       r.City,
+		Address:    r.Address,
+		Currency:   r.CurrencyCode,
+		Quote:      r.Quote(),
+		Buy:        r.Buy,
+		Sell:       r.Sell,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// handleAPIRates returns rates as JSON.
+// Query: currency, source, channel, city, from, to (YYYY-MM-DD or RFC3339).
+func (s *Server) handleAPIRates(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseRateFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rates, err := s.uc.GetRates(r.Context(), filter)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api get rates failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+
+	out := make([]rateDTO, 0, len(rates))
+	for _, rate := range rates {
+		out = append(out, newRateDTO(rate))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// impliedDTO is the JSON representation of a KZT cross rate derived from a reference source.
+type impliedDTO struct {
+	Reference    string  `json:"reference"`
+	Via          string  `json:"via"`
+	Currency     string  `json:"currency"`
+	Rate         float64 `json:"rate"`
+	Official     float64 `json:"official,omitempty"`
+	DeviationPct float64 `json:"deviation_pct"`
+}
+
+// handleAPIImplied returns KZT cross rates implied by CBR/ECB/NBKR and their deviation from NBRK.
+func (s *Server) handleAPIImplied(w http.ResponseWriter, r *http.Request) {
+	implied, err := s.uc.ImpliedRates(r.Context())
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api implied rates failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+
+	out := make([]impliedDTO, 0, len(implied))
+	for _, ir := range implied {
+		out = append(out, impliedDTO{
+			Reference:    ir.Reference,
+			Via:          ir.Via,
+			Currency:     ir.CurrencyCode,
+			Rate:         math.Round(ir.Rate*100) / 100,
+			Official:     ir.Official,
+			DeviationPct: math.Round(ir.DeviationPct*100) / 100,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// sourceDTO is the JSON representation of source metadata.
+type sourceDTO struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Homepage   string   `json:"homepage,omitempty"`
+	Logo       string   `json:"logo,omitempty"`
+	Kind       string   `json:"kind"`
+	Currencies []string `json:"currencies"`
+	Channels   []string `json:"channels"`
+	Schedule   string   `json:"schedule,omitempty"`
+}
+
+// handleAPISources returns metadata of configured rate sources.
+func (s *Server) handleAPISources(w http.ResponseWriter, _ *http.Request) {
+	sources := s.uc.Sources()
+	out := make([]sourceDTO, 0, len(sources))
+	for _, info := range sources {
+		dto := sourceDTO{
+			ID:         info.ID,
+			Name:       info.DisplayName(),
+			Homepage:   info.Homepage,
+			Logo:       info.Logo,
+			Kind:       string(info.Kind),
+			Currencies: info.Currencies,
+			Channels:   info.Channels,
+		}
+		if info.Schedule > 0 {
+			dto.Schedule = info.Schedule.String()
+		}
+		out = append(out, dto)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// schemaNoticeDTO is the JSON representation of a schema drift notice.
+type schemaNoticeDTO struct {
+	Source   string    `json:"source"`
+	Detail   string    `json:"detail"`
+	Breaking bool      `json:"breaking"`
+	Count    int       `json:"count"`
+	Since    time.Time `json:"since"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// handleAPISchemaNotices returns sources whose upstream payload changed shape.
+func (s *Server) handleAPISchemaNotices(w http.ResponseWriter, _ *http.Request) {
+	notices := s.uc.SchemaNotices()
+	out := make([]schemaNoticeDTO, 0, len(notices))
+	for _, n := range notices {
+		out = append(out, schemaNoticeDTO{
+			Source:   n.Source,
+			Detail:   n.Detail,
+			Breaking: n.Breaking,
+			Count:    n.Count,
+			Since:    n.Since,
+			LastSeen: n.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// quarantineDTO is the JSON representation of a rate that failed validation.
+type quarantineDTO struct {
+	ID        int64     `json:"id"`
+	Rule      string    `json:"rule"`
+	Reason    string    `json:"reason"`
+	Hits      int       `json:"hits"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Rate      rateDTO   `json:"rate"`
+}
+
+// handleAPIQuarantine returns rates held back by validation. Query: from, to (last seen).
+func (s *Server) handleAPIQuarantine(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := parseAPITime(q.Get("from"), false)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseAPITime(q.Get("to"), true)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rates, err := s.uc.GetQuarantinedRates(r.Context(), from, to)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api get quarantine failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]quarantineDTO, 0, len(rates))
+	for _, qr := range rates {
+		out = append(out, quarantineDTO{
+			ID:        qr.ID,
+			Rule:      qr.Rule,
+			Reason:    qr.Reason,
+			Hits:      qr.Hits,
+			FirstSeen: qr.FirstSeen,
+			LastSeen:  qr.LastSeen,
+			Rate:      newRateDTO(qr.Rate),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleAPIReleaseQuarantined accepts a reviewed rate into regular rates.
+func (s *Server) handleAPIReleaseQuarantined(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+	err = s.uc.ReleaseQuarantinedRate(r.Context(), id)
+	switch {
+	case errors.Is(err, internalErrors.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err)
+	case err != nil:
+		s.l.Error("api release quarantined failed", "id", id, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// offerDTO is the JSON representation of a place to exchange currency.
+type offerDTO struct {
+	Source     string     `json:"source"`
+	Branch     string     `json:"branch,omitempty"`
+	BranchName string     `json:"branch_name,omitempty"`
+	City       string     `json:"city,omitempty"`
+	Address    string     `json:"address,omitempty"`
+	Rate       float64    `json:"rate"`
+	ChangedAt  time.Time  `json:"changed_at"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+}
+
+// bestRateDTO is the JSON representation of the best offers for a currency and channel.
+type bestRateDTO struct {
+	Currency string     `json:"currency"`
+	Channel  string     `json:"channel,omitempty"`
+	Buy      []offerDTO `json:"buy"`  // где купить валюту
+	Sell     []offerDTO `json:"sell"` // где продать валюту
+}
+
+func newOfferDTOs(offers []entity.Offer) []offerDTO {
+	out := make([]offerDTO, 0, len(offers))
+	for _, o := range offers {
+		dto := offerDTO{
+			Source:     o.Source,
+			Branch:     o.Branch,
+			BranchName: o.BranchName,
+			City:       o.City,
+			Address:    o.Address,
+			Rate:       o.Rate,
+			ChangedAt:  o.ChangedAt,
+		}
+		if !o.CheckedAt.IsZero() {
+			checked := o.CheckedAt
+			dto.CheckedAt = &checked
+		}
+		out = append(out, dto)
+	}
+	return out
+}
+
+// handleAPIBest returns where to buy (lowest sell) and sell (highest buy) each currency per channel.
+// Query: currency, channel, city, limit (offers per side, best first).
+func (s *Server) handleAPIBest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+			return
+		}
+		limit = n
+	}
+	filter := &exrate.ExchangeRateFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Channel:      q.Get("channel"),
+		City:         q.Get("city"),
+	}
+
+	best, err := s.uc.BestRates(r.Context(), filter, limit)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api best rates failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]bestRateDTO, 0, len(best))
+	for _, b := range best {
+		out = append(out, bestRateDTO{
+			Currency: b.CurrencyCode,
+			Channel:  b.Channel,
+			Buy:      newOfferDTOs(b.Buy),
+			Sell:     newOfferDTOs(b.Sell),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// conversionOptionDTO is the JSON representation of an amount exchanged at one source.
+type conversionOptionDTO struct {
+	Source     string    `json:"source"`
+	Branch     string    `json:"branch,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	Channel    string    `json:"channel,omitempty"`
+	City       string    `json:"city,omitempty"`
+	Rate       float64   `json:"rate"`
+	Result     float64   `json:"result"`
+	Via        string    `json:"via,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// conversionDTO is the JSON representation of a conversion; best is the first option.
+type conversionDTO struct {
+	From     string                `json:"from"`
+	To       string                `json:"to"`
+	Amount   float64               `json:"amount"`
+	Official *conversionOptionDTO  `json:"official,omitempty"`
+	Best     *conversionOptionDTO  `json:"best,omitempty"`
+	Options  []conversionOptionDTO `json:"options"`
+}
+
+func newConversionOptionDTO(o entity.ConversionOption) conversionOptionDTO {
+	return conversionOptionDTO{
+		Source:     o.Source,
+		Branch:     o.Branch,
+		BranchName: o.BranchName,
+		Channel:    o.Channel,
+		City:       o.City,
+		Rate:       math.Round(o.Rate*1e6) / 1e6,
+		Result:     math.Round(o.Result*100) / 100,
+		Via:        o.Via,
+		ChangedAt:  o.ChangedAt,
+	}
+}
+
+// handleAPIConvert converts an amount at every bank and at the official rate.
+// Query: amount, from, to, city, channel.
+func (s *Server) handleAPIConvert(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	amount, err := strconv.ParseFloat(q.Get("amount"), 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+	filter := &exrate.ExchangeRateFilter{City: q.Get("city"), Channel: q.Get("channel")}
+
+	conv, err := s.uc.Convert(r.Context(), amount, q.Get("from"), q.Get("to"), filter)
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	case errors.Is(err, internalErrors.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		s.l.Error("api convert failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+
+	out := conversionDTO{
+		From:    conv.From,
+		To:      conv.To,
+		Amount:  conv.Amount,
+		Options: make([]conversionOptionDTO, 0, len(conv.Options)),
+	}
+	if conv.Official != nil {
+		official := newConversionOptionDTO(*conv.Official)
+		out.Official = &official
+	}
+	for _, o := range conv.Options {
+		out.Options = append(out.Options, newConversionOptionDTO(o))
+	}
+	if len(out.Options) > 0 {
+		out.Best = &out.Options[0]
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// candleDTO is the JSON representation of an OHLC candle.
+type candleDTO struct {
+	Source   string    `json:"source"`
+	Channel  string    `json:"channel,omitempty"`
+	Currency string    `json:"currency"`
+	Side     string    `json:"side"`
+	Interval string    `json:"interval"`
+	Start    time.Time `json:"start"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	Changes  int       `json:"changes"`
+}
+
+// handleAPICandles returns open/high/low/close candles of a source's currency.
+// Query: currency, source (required), interval (hour, day, week, month; default day), side, channel, from, to.
+func (s *Server) handleAPICandles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := &exrate.CandleFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Source:       q.Get("source"),
+		Channel:      q.Get("channel"),
+		Side:         q.Get("side"),
+		Interval:     entity.CandleInterval(q.Get("interval")),
+	}
+	if filter.Interval == "" {
+		filter.Interval = entity.CandleDay
+	}
+	var err error
+	if filter.StartDate, err = parseAPITime(q.Get("from"), false); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.EndDate, err = parseAPITime(q.Get("to"), true); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	candles, err := s.uc.Candles(r.Context(), filter)
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	case err != nil && !errors.Is(err, internalErrors.ErrNotFound):
+		s.l.Error("api candles failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]candleDTO, 0, len(candles))
+	for _, c := range candles {
+		out = append(out, candleDTO{
+			Source:   c.Source,
+			Channel:  c.Channel,
+			Currency: c.CurrencyCode,
+			Side:     c.Side,
+			Interval: string(c.Interval),
+			Start:    c.Start,
+			Open:     c.Open,
+			High:     c.High,
+			Low:      c.Low,
+			Close:    c.Close,
+			Changes:  c.Changes,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// spreadDTO is the JSON representation of a rate's spread.
+type spreadDTO struct {
+	Source     string    `json:"source"`
+	Branch     string    `json:"branch,omitempty"`
+	BranchName string    `json:"branch_name,omitempty"`
+	City       string    `json:"city,omitempty"`
+	Channel    string    `json:"channel,omitempty"`
+	Currency   string    `json:"currency"`
+	Buy        float64   `json:"buy"`
+	Sell       float64   `json:"sell"`
+	Abs        float64   `json:"abs"`
+	Pct        float64   `json:"pct"`
+	At         time.Time `json:"at"`
+}
+
+// handleAPISpreads ranks the latest rates by spread, narrowest first. Query: currency, channel, city.
+func (s *Server) handleAPISpreads(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	spreads, err := s.uc.Spreads(r.Context(), &exrate.ExchangeRateFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Channel:      q.Get("channel"),
+		City:         q.Get("city"),
+	})
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api spreads failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]spreadDTO, 0, len(spreads))
+	for _, sp := range spreads {
+		out = append(out, spreadDTO{
+			Source:     sp.Source,
+			Branch:     sp.Branch,
+			BranchName: sp.BranchName,
+			City:       sp.City,
+			Channel:    sp.Channel,
+			Currency:   sp.CurrencyCode,
+			Buy:        sp.Buy,
+			Sell:       sp.Sell,
+			Abs:        math.Round(sp.Abs*100) / 100,
+			Pct:        math.Round(sp.Pct*1000) / 1000,
+			At:         sp.At,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// spreadDayDTO is the JSON representation of a series' spread over a day.
+type spreadDayDTO struct {
+	Source  string  `json:"source"`
+	Channel string  `json:"channel,omitempty"`
+	Day     string  `json:"day"`
+	AvgPct  float64 `json:"avg_pct"`
+	MinPct  float64 `json:"min_pct"`
+	MaxPct  float64 `json:"max_pct"`
+	Changes int     `json:"changes"`
+}
+
+// spreadWideningDTO is the JSON representation of a series that widened its spread on a volatile day.
+type spreadWideningDTO struct {
+	Source      string  `json:"source"`
+	Channel     string  `json:"channel,omitempty"`
+	AvgPct      float64 `json:"avg_pct"`
+	BaselinePct float64 `json:"baseline_pct"`
+}
+
+// volatileDayDTO is the JSON representation of a volatile day.
+type volatileDayDTO struct {
+	Day             string              `json:"day"`
+	OfficialMovePct float64             `json:"official_move_pct"`
+	Widened         []spreadWideningDTO `json:"widened"`
+}
+
+// spreadHistoryDTO is the JSON representation of the spread history.
+type spreadHistoryDTO struct {
+	Days         []spreadDayDTO   `json:"days"`
+	VolatileDays []volatileDayDTO `json:"volatile_days"`
+}
+
+// handleAPISpreadHistory returns daily spreads per bank and the banks that widened them on volatile days.
+// Query: currency (required), source, channel, from, to, volatile_pct (official daily range, default 0.5).
+func (s *Server) handleAPISpreadHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSpreadFilter(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	history, err := s.uc.SpreadHistory(r.Context(), filter)
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	case errors.Is(err, internalErrors.ErrNotFound):
+		history = &entity.SpreadHistory{}
+	case err != nil:
+		s.l.Error("api spread history failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	out := spreadHistoryDTO{
+		Days:         make([]spreadDayDTO, 0, len(history.Days)),
+		VolatileDays: make([]volatileDayDTO, 0, len(history.VolatileDays)),
+	}
+	for _, d := range history.Days {
+		out.Days = append(out.Days, spreadDayDTO{
+			Source:  d.Source,
+			Channel: d.Channel,
+			Day:     d.Day.Format(apiDateLayout),
+			AvgPct:  round(d.AvgPct),
+			MinPct:  round(d.MinPct),
+			MaxPct:  round(d.MaxPct),
+			Changes: d.Changes,
+		})
+	}
+	for _, v := range history.VolatileDays {
+		dto := volatileDayDTO{
+			Day:             v.Day.Format(apiDateLayout),
+			OfficialMovePct: round(v.OfficialMovePct),
+			Widened:         make([]spreadWideningDTO, 0, len(v.Widened)),
+		}
+		for _, wd := range v.Widened {
+			dto.Widened = append(dto.Widened, spreadWideningDTO{
+				Source:      wd.Source,
+				Channel:     wd.Channel,
+				AvgPct:      round(wd.AvgPct),
+				BaselinePct: round(wd.BaselinePct),
+			})
+		}
+		out.VolatileDays = append(out.VolatileDays, dto)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// anomalyDTO is the JSON representation of an anomaly event.
+type anomalyDTO struct {
+	ID             int64     `json:"id"`
+	Source         string    `json:"source"`
+	Channel        string    `json:"channel,omitempty"`
+	Branch         string    `json:"branch,omitempty"`
+	BranchName     string    `json:"branch_name,omitempty"`
+	Currency       string    `json:"currency"`
+	Side           string    `json:"side"`
+	Value          float64   `json:"value"`
+	Baseline       float64   `json:"baseline"`
+	DeviationPct   float64   `json:"deviation_pct"`
+	Score          float64   `json:"score"`
+	Method         string    `json:"method"`
+	RateAt         time.Time `json:"rate_at"`
+	DetectedAt     time.Time `json:"detected_at"`
+	CorroboratedBy []string  `json:"corroborated_by"`
+}
+
+// handleAPIAnomalies returns unusual rate moves. Query: from, to (detection time).
+// corroborated_by lists other sources that moved the same way at the same time:
+// empty suggests a glitch of one source rather than a market move.
+func (s *Server) handleAPIAnomalies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := parseAPITime(q.Get("from"), false)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseAPITime(q.Get("to"), true)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	list, err := s.anomalies(r.Context(), from, to)
+	if err != nil {
+		s.l.Error("api get anomalies failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]anomalyDTO, 0, len(list))
+	for _, a := range list {
+		by := corroboratedBy(a, list)
+		if by == nil {
+			by = []string{}
+		}
+		out = append(out, anomalyDTO{
+			ID:             a.ID,
+			Source:         a.Source,
+			Channel:        a.Channel,
+			Branch:         a.Branch,
+			BranchName:     a.BranchName,
+			Currency:       a.CurrencyCode,
+			Side:           a.Side,
+			Value:          a.Value,
+			Baseline:       a.Baseline,
+			DeviationPct:   math.Round(a.DeviationPct()*100) / 100,
+			Score:          math.Round(a.Score*100) / 100,
+			Method:         a.Method,
+			RateAt:         a.RateAt,
+			DetectedAt:     a.DetectedAt,
+			CorroboratedBy: by,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func parseRateFilter(r *http.Request) (*exrate.ExchangeRateFilter, error) {
+	q := r.URL.Query()
+	filter := &exrate.ExchangeRateFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Source:       q.Get("source"),
+		Channel:      q.Get("channel"),
+		City:         q.Get("city"),
+	}
+	var err error
+	if filter.StartDate, err = parseAPITime(q.Get("from"), false); err != nil {
+		return nil, err
+	}
+	if filter.EndDate, err = parseAPITime(q.Get("to"), true); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func parseSpreadFilter(r *http.Request) (*exrate.SpreadFilter, error) {
+	q := r.URL.Query()
+	filter := &exrate.SpreadFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Source:       q.Get("source"),
+		Channel:      q.Get("channel"),
+	}
+	var err error
+	if filter.StartDate, err = parseAPITime(q.Get("from"), false); err != nil {
+		return nil, err
+	}
+	if filter.EndDate, err = parseAPITime(q.Get("to"), true); err != nil {
+		return nil, err
+	}
+	if v := q.Get("volatile_pct"); v != "" {
+		if filter.VolatileMovePct, err = strconv.ParseFloat(v, 64); err != nil || filter.VolatileMovePct <= 0 {
+			return nil, internalErrors.ErrInvalidArgument
+		}
+	}
+	return filter, nil
+}
+
+// parseAPITime parses RFC3339 or a plain date; a plain date used as upper bound covers the whole day.
+func parseAPITime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(apiDateLayout, v)
+	if err != nil {
+		return time.Time{}, internalErrors.ErrInvalidArgument
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,666 @@
+package webserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// testAdminToken opens admin endpoints in tests.
+const testAdminToken = "t0ken"
+
+// newAdminRequest is a request to an admin endpoint carrying testAdminToken.
+func newAdminRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	return req
+}
+
+func TestServer_HandleAPIRates(t *testing.T) {
+	created := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	var gotFilter *exrate.ExchangeRateFilter
+	service := &mockExchangeRateService{
+		getRatesFunc: func(_ context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			gotFilter = filter
+			return []*entity.ExchangeRate{{
+				CurrencyCode: "USD",
+				Buy:          "536.5",
+				Sell:         "539",
+				Source:       "KursKZ",
+				Branch:       "17",
+				BranchName:   "Алтын",
+				City:         "Алматы",
+				Address:      "пр. Абая, 10",
+				CreatedAt:    created,
+			}}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rates?currency=usd&city=Алматы&from=2024-11-01&to=2024-11-01", nil)
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "USD", gotFilter.CurrencyCode)
+	assert.Equal(t, "Алматы", gotFilter.City)
+	assert.Equal(t, created.Add(-12*time.Hour), gotFilter.StartDate)
+	assert.Equal(t, created.Add(12*time.Hour-time.Nanosecond), gotFilter.EndDate)
+
+	var got []rateDTO
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "17", got[0].Branch)
+	assert.Equal(t, "Алтын", got[0].BranchName)
+	assert.Equal(t, "пр. Абая, 10", got[0].Address)
+}
+
+func TestServer_HandleAPIRates_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "bad date", url: "/api/rates?from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "not found is empty list", url: "/api/rates", err: internalErrors.ErrNotFound, wantStatus: http.StatusOK, wantBody: "[]\n"},
+		{name: "internal", url: "/api/rates", err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockExchangeRateService{
+				getRatesFunc: func(context.Context, *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+					return nil, tt.err
+				},
+			}
+			server := NewServer(&mockLogger{}, service)
+			rr := httptest.NewRecorder()
+			server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_GatherBanks_Offices(t *testing.T) {
+	service := &mockExchangeRateService{
+		getRatesFunc: func(_ context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			assert.Equal(t, "Алматы", filter.City)
+			return []*entity.ExchangeRate{
+				{CurrencyCode: "USD", Buy: "536", Sell: "539", Source: "KursKZ", Branch: "17", BranchName: "Алтын", City: "Алматы",
+					Address: "пр. Абая, 10"},
+				{CurrencyCode: "USD", Buy: "537", Sell: "540", Source: "KursKZ", Branch: "18", City: "Алматы"},
+				{CurrencyCode: "USD", Buy: "535", Sell: "541", Source: "Halyk"},
+				{CurrencyCode: "USD", QuoteCurrency: "RUB", Buy: "96.9", Sell: "96.9", Source: "CBR"},
+			}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	banks, err := server.gatherBanks(context.Background(), "usd", "Алматы")
+	require.NoError(t, err)
+	require.Len(t, banks, 3)
+	assert.Equal(t, "Halyk", banks[0].Name)
+	assert.Equal(t, "KZ", banks[0].Location)
+	assert.Equal(t, "Алтын", banks[1].Name)
+	assert.Equal(t, "Алматы", banks[1].Location)
+	assert.Equal(t, "пр. Абая, 10", banks[1].Address)
+	assert.Equal(t, "18", banks[2].Name) // без названия — id обменника
+}
+
+func TestServer_HandleAPIImplied(t *testing.T) {
+	service := &mockExchangeRateService{
+		impliedFunc: func(context.Context) ([]*entity.ImpliedRate, error) {
+			return []*entity.ImpliedRate{{
+				Reference: "CBR", Via: "RUB", CurrencyCode: "USD",
+				Rate: 485.123, Official: 480, DeviationPct: 1.06729,
+			}}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/implied", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t,
+		`[{"reference":"CBR","via":"RUB","currency":"USD","rate":485.12,"official":480,"deviation_pct":1.07}]`,
+		rr.Body.String())
+
+	service.impliedFunc = func(context.Context) ([]*entity.ImpliedRate, error) {
+		return nil, errors.New("db down")
+	}
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/implied", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestServer_HandleAPISources(t *testing.T) {
+	service := &mockExchangeRateService{sources: []entity.SourceInfo{{
+		ID: "HomeKZ", Name: "Home Credit Bank", Homepage: "https://home.kz", Kind: entity.SourceKindBank,
+		Currencies: []string{"USD"}, Channels: []string{entity.ChannelCash}, Schedule: 30 * time.Minute,
+	}}}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sources", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":"HomeKZ","name":"Home Credit Bank","homepage":"https://home.kz","kind":"bank",
+		"currencies":["USD"],"channels":["cash"],"schedule":"30m0s"}]`, rr.Body.String())
+}
+
+func TestServer_HandleAPISchemaNotices(t *testing.T) {
+	seen := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{notices: []entity.SchemaNotice{{
+		Source: "Halyk", Detail: "upstream schema changed: mismatched data.currencyHistory",
+		Breaking: true, Count: 3, Since: seen, LastSeen: seen.Add(time.Hour),
+	}}}
+	server := NewServer(&mockLogger{}, service)
+	server.SetAdminToken(testAdminToken)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodGet, "/api/admin/schema", ""))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"source":"Halyk","detail":"upstream schema changed: mismatched data.currencyHistory",
+		"breaking":true,"count":3,"since":"2024-11-01T09:00:00Z","last_seen":"2024-11-01T10:00:00Z"}]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodGet, "/debug/vars", ""))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"memstats"`)
+}
+
+func TestServer_HandleAPIQuarantine(t *testing.T) {
+	seen := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{quarantined: []*entity.QuarantinedRate{{
+		ID:   7,
+		Rate: &entity.ExchangeRate{CurrencyCode: "USD", Buy: "545", Sell: "540", Source: "Halyk", Channel: "cash", CreatedAt: seen},
+		Rule: entity.RuleBuyAboveSell, Reason: "buy 545 above sell 540", Hits: 2, FirstSeen: seen, LastSeen: seen,
+	}}}
+	server := NewServer(&mockLogger{}, service)
+	server.SetAdminToken(testAdminToken)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodGet, "/api/admin/quarantine", ""))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":7,"rule":"buy_above_sell","reason":"buy 545 above sell 540","hits":2,
+		"first_seen":"2024-11-01T09:00:00Z","last_seen":"2024-11-01T09:00:00Z",
+		"rate":{"source":"Halyk","channel":"cash","currency":"USD","quote":"KZT","buy":"545","sell":"540",
+		"created_at":"2024-11-01T09:00:00Z"}}]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/quarantine/7/release", ""))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, []int64{7}, service.released)
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodPost, "/api/admin/quarantine/8/release", ""))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, newAdminRequest(http.MethodGet, "/api/admin/quarantine?from=yesterday", ""))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_HandleAPIBest(t *testing.T) {
+	changed := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		sources: []entity.SourceInfo{{ID: "BCC", Name: "Bank CenterCredit"}},
+		best: []*entity.BestRate{{
+			CurrencyCode: "USD",
+			Channel:      entity.ChannelCash,
+			Buy:          []entity.Offer{{Source: "BCC", Rate: 521, ChangedAt: changed, CheckedAt: changed.Add(time.Hour)}},
+			Sell:         []entity.Offer{{Source: "MIG", Branch: "Обменник на Абая", City: "Алматы", Rate: 517, ChangedAt: changed}},
+		}},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/best?currency=usd&channel=cash&limit=1", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"currency":"USD","channel":"cash",
+		"buy":[{"source":"BCC","rate":521,"changed_at":"2024-11-01T09:00:00Z","checked_at":"2024-11-01T10:00:00Z"}],
+		"sell":[{"source":"MIG","branch":"Обменник на Абая","city":"Алматы","rate":517,"changed_at":"2024-11-01T09:00:00Z"}]}]`,
+		rr.Body.String())
+	assert.Equal(t, &exrate.ExchangeRateFilter{CurrencyCode: "USD", Channel: "cash"}, service.bestFilter)
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/best", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Bank CenterCredit")
+	assert.Contains(t, rr.Body.String(), "Обменник на Абая")
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/best?limit=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_HandleAPIConvert(t *testing.T) {
+	changed := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		convertFunc: func(_ context.Context, amount float64, from, to string, filter *exrate.ExchangeRateFilter) (*entity.Conversion, error) {
+			if from == to {
+				return nil, internalErrors.ErrInvalidArgument
+			}
+			assert.Equal(t, "Алматы", filter.City)
+			return &entity.Conversion{
+				From: "EUR", To: "USD", Amount: amount,
+				Official: &entity.ConversionOption{Source: "NBRK", Channel: "official", Rate: 1.08, Result: 108, Via: "KZT", ChangedAt: changed},
+				Options: []entity.ConversionOption{
+					{Source: "Halyk", Channel: "cash", Rate: 1.059406, Result: 105.940594, Via: "KZT", ChangedAt: changed},
+				},
+			}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/convert?amount=100&from=eur&to=usd&city=%D0%90%D0%BB%D0%BC%D0%B0%D1%82%D1%8B", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	halyk := `{"source":"Halyk","channel":"cash","rate":1.059406,"result":105.94,"via":"KZT","changed_at":"2024-11-01T09:00:00Z"}`
+	assert.JSONEq(t, `{"from":"EUR","to":"USD","amount":100,
+		"official":{"source":"NBRK","channel":"official","rate":1.08,"result":108,"via":"KZT","changed_at":"2024-11-01T09:00:00Z"},
+		"best":`+halyk+`,"options":[`+halyk+`]}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/convert?amount=100&from=EUR&to=USD&city=%D0%90%D0%BB%D0%BC%D0%B0%D1%82%D1%8B", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "105.94")
+	assert.Contains(t, rr.Body.String(), "НБРК: 108.00 USD")
+
+	for _, url := range []string{"/api/convert?amount=lots&from=USD&to=KZT", "/api/convert?amount=1&from=USD&to=USD"} {
+		rr = httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestServer_HandleAPICandles(t *testing.T) {
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		candlesFunc: func(_ context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error) {
+			if filter.Source == "" || !filter.Interval.Valid() {
+				return nil, internalErrors.ErrInvalidArgument
+			}
+			assert.Equal(t, &exrate.CandleFilter{
+				CurrencyCode: "USD", Source: "Halyk", Side: "buy", Interval: entity.CandleWeek,
+				StartDate: day, EndDate: day.Add(24*time.Hour - time.Nanosecond),
+			}, filter)
+			return []*entity.Candle{{
+				Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Side: "buy", Interval: entity.CandleWeek,
+				Start: day, Open: 490, High: 492, Low: 488, Close: 491, Changes: 3,
+			}}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet,
+		"/api/candles?currency=usd&source=Halyk&side=buy&interval=week&from=2024-11-04&to=2024-11-04", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"source":"Halyk","channel":"cash","currency":"USD","side":"buy","interval":"week",
+		"start":"2024-11-04T00:00:00Z","open":490,"high":492,"low":488,"close":491,"changes":3}]`, rr.Body.String())
+
+	for _, url := range []string{"/api/candles?currency=USD", "/api/candles?currency=USD&source=Halyk&interval=minute"} {
+		rr = httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestServer_HandleAPISpreads(t *testing.T) {
+	at := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		spreadsFunc: func(_ context.Context, filter *exrate.ExchangeRateFilter) ([]entity.Spread, error) {
+			assert.Equal(t, &exrate.ExchangeRateFilter{CurrencyCode: "USD", Channel: "cash", City: "Алматы"}, filter)
+			return []entity.Spread{{
+				Source: "Halyk", City: "Алматы", Channel: "cash", CurrencyCode: "USD",
+				Buy: 495, Sell: 500, Abs: 5, Pct: 1.0050251, At: at,
+			}}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/spreads?currency=usd&channel=cash&city=%D0%90%D0%BB%D0%BC%D0%B0%D1%82%D1%8B", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"source":"Halyk","city":"Алматы","channel":"cash","currency":"USD",
+		"buy":495,"sell":500,"abs":5,"pct":1.005,"at":"2024-11-04T09:00:00Z"}]`, rr.Body.String())
+}
+
+func TestServer_HandleAPISpreadHistory(t *testing.T) {
+	day := time.Date(2024, 11, 4, 0, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		historyFunc: func(_ context.Context, filter *exrate.SpreadFilter) (*entity.SpreadHistory, error) {
+			assert.Equal(t, &exrate.SpreadFilter{
+				CurrencyCode: "USD", Channel: "cash", VolatileMovePct: 1,
+				StartDate: day, EndDate: day.Add(48*time.Hour - time.Nanosecond),
+			}, filter)
+			return &entity.SpreadHistory{
+				Days: []*entity.SpreadDay{
+					{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Day: day, AvgPct: 1, MinPct: 0.8, MaxPct: 1.2, Changes: 4},
+					{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Day: day.AddDate(0, 0, 1), AvgPct: 2.5, MinPct: 2, MaxPct: 3, Changes: 6},
+				},
+				VolatileDays: []*entity.VolatileDay{{
+					Day: day.AddDate(0, 0, 1), OfficialMovePct: 1.8,
+					Widened: []entity.SpreadWidening{{Source: "Halyk", Channel: "cash", AvgPct: 2.5, BaselinePct: 1}},
+				}},
+			}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet,
+		"/api/spreads/history?currency=usd&channel=cash&from=2024-11-04&to=2024-11-05&volatile_pct=1", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"days":[
+		{"source":"Halyk","channel":"cash","day":"2024-11-04","avg_pct":1,"min_pct":0.8,"max_pct":1.2,"changes":4},
+		{"source":"Halyk","channel":"cash","day":"2024-11-05","avg_pct":2.5,"min_pct":2,"max_pct":3,"changes":6}],
+		"volatile_days":[{"day":"2024-11-05","official_move_pct":1.8,
+		"widened":[{"source":"Halyk","channel":"cash","avg_pct":2.5,"baseline_pct":1}]}]}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet,
+		"/spreads?currency=usd&channel=cash&from=2024-11-04&to=2024-11-05&volatile_pct=1", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<polyline")
+	assert.Contains(t, rr.Body.String(), "05.11.2024")
+	assert.Contains(t, rr.Body.String(), "2.50% вместо 1.00%")
+
+	for _, url := range []string{"/api/spreads/history?currency=USD&volatile_pct=-1", "/api/spreads/history?currency=USD&volatile_pct=x", "/spreads?from=yesterday"} {
+		rr = httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestServer_HandleAPIAnomalies(t *testing.T) {
+	at := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	spike := func(source string, rateAt time.Time, value float64) *entity.Anomaly {
+		return &entity.Anomaly{
+			Source: source, Channel: "cash", CurrencyCode: "USD", Side: entity.SideSell,
+			Value: value, Baseline: 500, Score: 8, Method: "mad", RateAt: rateAt, DetectedAt: rateAt,
+		}
+	}
+	service := &mockExchangeRateService{anomalies: []*entity.Anomaly{
+		spike("Halyk", at, 525),
+		spike("BCC", at.Add(2*time.Hour), 524),
+		spike("Kaspi", at.Add(24*time.Hour), 550), // в одиночку, на следующий день
+	}}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/anomalies", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var got []anomalyDTO
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	require.Len(t, got, 3)
+	assert.Equal(t, []string{"BCC"}, got[0].CorroboratedBy)
+	assert.Equal(t, []string{"Halyk"}, got[1].CorroboratedBy)
+	assert.Empty(t, got[2].CorroboratedBy)
+	assert.InDelta(t, 5.0, got[0].DeviationPct, 1e-9)
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/anomalies", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "движение рынка")
+	assert.Contains(t, rr.Body.String(), "возможен сбой источника")
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/anomalies?to=tomorrow", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_HandleAPIAlerts(t *testing.T) {
+	at := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{alertEvents: []*entity.AlertEvent{{
+		ID: 7, RuleID: 1, Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Value: 479.5,
+		Message: "Halyk, cash USD: продажа 479.50 ниже 480", RateAt: at, FiredAt: at,
+	}}}
+	server := NewServer(&mockLogger{}, service)
+	server.SetAdminToken(testAdminToken)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, newAdminRequest(method, url, body))
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/api/alerts",
+		`{"kind":"price","currency":"USD","side":"sell","op":"below","threshold":480,"cooldown":"2h"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	require.Len(t, service.alertRules, 1)
+	assert.Equal(t, &entity.AlertRule{
+		ID: 1, Kind: "price", CurrencyCode: "USD", Side: "sell", Op: "below", Threshold: 480, Cooldown: 2 * time.Hour,
+	}, service.alertRules[0])
+
+	rr = do(http.MethodGet, "/api/alerts", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":1,"kind":"price","currency":"USD","side":"sell","op":"below","threshold":480,
+		"cooldown":"2h0m0s","firing":false,"created_at":"0001-01-01T00:00:00Z"}]`, rr.Body.String())
+
+	rr = do(http.MethodGet, "/api/alerts/events?from=2024-11-01", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var events []alertEventDTO
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &events))
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(1), events[0].RuleID)
+	assert.Equal(t, 479.5, events[0].Value)
+
+	for _, body := range []string{`{"kind":"price"`, `{"kind":"price","cooldown":"soon"}`, `{"kind":"price","color":"red"}`, `{"currency":"USD"}`} {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/alerts", body).Code, body)
+	}
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/alerts/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/alerts/1", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodDelete, "/api/alerts/x", "").Code)
+}
+
+// mockWebhookService реализует интерфейс WebhookService для тестов
+type mockWebhookService struct {
+	hooks      []*entity.Webhook
+	deliveries []*entity.WebhookDelivery
+	limit      int
+}
+
+func (m *mockWebhookService) CreateWebhook(_ context.Context, w *entity.Webhook) error {
+	if w.URL == "" {
+		return internalErrors.ErrInvalidArgument
+	}
+	w.ID, w.Secret = int64(len(m.hooks)+1), "generated"
+	m.hooks = append(m.hooks, w)
+	return nil
+}
+
+func (m *mockWebhookService) Webhooks(context.Context) ([]*entity.Webhook, error) {
+	return m.hooks, nil
+}
+
+func (m *mockWebhookService) DeleteWebhook(_ context.Context, id int64) error {
+	if id != 1 {
+		return internalErrors.ErrNotFound
+	}
+	return nil
+}
+
+func (m *mockWebhookService) Deliveries(_ context.Context, _ int64, limit int) ([]*entity.WebhookDelivery, error) {
+	m.limit = limit
+	return m.deliveries, nil
+}
+
+func TestServer_HandleAPIWebhooks(t *testing.T) {
+	at := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	hooks := &mockWebhookService{deliveries: []*entity.WebhookDelivery{{
+		ID: 3, WebhookID: 1, Event: "rates.changed", Payload: []byte(`{"rates":[]}`), Status: entity.DeliveryPending,
+		Attempts: 2, NextAttemptAt: at.Add(time.Minute), LastStatus: 502, LastError: "receiver responded 502", CreatedAt: at,
+	}}}
+	server := NewServer(&mockLogger{}, &mockExchangeRateService{})
+	server.SetAdminToken(testAdminToken)
+	server.SetWebhooks(hooks)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, newAdminRequest(method, url, body))
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/api/admin/webhooks", `{"url":"https://pricing.local/hook","currency":"USD","channel":"cash"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `{"id":1,"url":"https://pricing.local/hook","secret":"generated","currency":"USD","channel":"cash",
+		"created_at":"0001-01-01T00:00:00Z"}`, rr.Body.String())
+
+	rr = do(http.MethodGet, "/api/admin/webhooks", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "generated", "secret must not be listed")
+
+	rr = do(http.MethodGet, "/api/admin/webhooks/1/deliveries?limit=5", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 5, hooks.limit)
+	assert.JSONEq(t, `[{"id":3,"event":"rates.changed","status":"pending","attempts":2,
+		"next_attempt_at":"2024-11-01T09:01:00Z","last_status":502,"last_error":"receiver responded 502",
+		"created_at":"2024-11-01T09:00:00Z","payload":{"rates":[]}}]`, rr.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/admin/webhooks", `{"currency":"USD"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/api/admin/webhooks/1/deliveries?limit=0", "").Code)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/admin/webhooks/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/admin/webhooks/2", "").Code)
+
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/webhooks", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestServer_AdminToken(t *testing.T) {
+	server := NewServer(&mockLogger{}, &mockExchangeRateService{})
+	server.SetAdminToken("s3cret")
+
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/schema", nil)
+		req.Header.Set("Authorization", auth)
+		rr := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, "Authorization %q", auth)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/schema", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Публичное API токен не требует
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sources", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Правила оповещений видны всем, а меняет их только админ
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/alerts", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/alerts",
+		strings.NewReader(`{"kind":"change","currency":"USD","threshold":1}`)))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/alerts/1", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestServer_AdminToken_Unset(t *testing.T) {
+	server := NewServer(&mockLogger{}, &mockExchangeRateService{})
+
+	// Без токена служебные маршруты закрыты для любого запроса
+	for _, req := range []*http.Request{
+		newAdminRequest(http.MethodGet, "/api/admin/schema", ""),
+		newAdminRequest(http.MethodPost, "/api/admin/quarantine/7/release", ""),
+		newAdminRequest(http.MethodPost, "/api/alerts", `{"kind":"change","currency":"USD","threshold":1}`),
+		httptest.NewRequest(http.MethodGet, "/debug/vars", nil),
+	} {
+		rr := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "%s %s", req.Method, req.URL)
+	}
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/alerts", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestServer_GatherBanks_SourceMetadata(t *testing.T) {
+	older := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+	service := &mockExchangeRateService{
+		sources: []entity.SourceInfo{{ID: "HomeKZ", Name: "Home Credit Bank", Homepage: "https://home.kz"}},
+		getRatesFunc: func(context.Context, *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			return []*entity.ExchangeRate{
+				{CurrencyCode: "USD", Buy: "480", Sell: "485", Source: "HomeKZ", Channel: "cash", CreatedAt: older.Add(time.Hour)},
+				{CurrencyCode: "USD", Buy: "470", Sell: "490", Source: "HomeKZ", CreatedAt: older}, // до появления каналов
+			}, nil
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	banks, err := server.gatherBanks(context.Background(), "usd", "")
+	require.NoError(t, err)
+	require.Len(t, banks, 1)
+	assert.Equal(t, "Home Credit Bank", banks[0].Name)
+	assert.Equal(t, "https://home.kz", banks[0].Homepage)
+	assert.Equal(t, 480.0, banks[0].Rates.USD.Buy) // свежий курс из канала cash
+}
+
+func TestServer_HandleAPIExport(t *testing.T) {
+	service := &mockExchangeRateService{exported: []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: "cash", CurrencyCode: "USD", Buy: "497.5", Sell: "506", CreatedAt: time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC)},
+	}}
+	server := NewServer(&mockLogger{}, service)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	rr := get("/api/export?currency=usd&source=Halyk&from=2024-11-01&to=2024-11-30&tz=Asia/Almaty")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="exr-usd-halyk-2024-11-01-2024-11-30.csv"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "created_at,source,branch,branch_name,channel,currency,quote,buy,sell,city,address\n"+
+		"2024-11-04T14:30:00+05:00,Halyk,,,cash,USD,KZT,497.5000,506.0000,,\n", rr.Body.String())
+	// Даты без времени — границы суток в поясе выгрузки
+	require.NotNil(t, service.exportFilter)
+	assert.Equal(t, "USD", service.exportFilter.CurrencyCode)
+	assert.Equal(t, time.Date(2024, 10, 31, 19, 0, 0, 0, time.UTC), service.exportFilter.StartDate)
+	assert.Equal(t, time.Date(2024, 11, 30, 18, 59, 59, 999999999, time.UTC), service.exportFilter.EndDate)
+
+	rr = get("/api/export?format=jsonl&tz=UTC")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"created_at":"2024-11-04T09:30:00Z"`)
+	assert.Contains(t, rr.Body.String(), `"buy":"497.5000"`)
+
+	rr = get("/api/export?format=xlsx")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Body.String(), "PK"), "xlsx is a zip archive")
+
+	for _, target := range []string{
+		"/api/export?format=xls",
+		"/api/export?tz=Mars/Olympus",
+		"/api/export?from=yesterday",
+		"/api/export?from=2024-11-30&to=2024-11-01",
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(target).Code, target)
+	}
+}
@@ -0,0 +1,64 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+// handleBestPage shows where to buy and sell each currency. Query: city.
+func (s *Server) handleBestPage(w http.ResponseWriter, r *http.Request) {
+	city := r.URL.Query().Get("city")
+	best, err := s.uc.BestRates(r.Context(), &exrate.ExchangeRateFilter{City: city}, 0)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("get best rates failed", "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := map[string]string{}
+	for _, info := range s.uc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	now := time.Now()
+	offers := func(list []entity.Offer) []web.Offer {
+		out := make([]web.Offer, 0, len(list))
+		for _, o := range list {
+			name := names[o.Source]
+			if name == "" {
+				name = o.Source
+			}
+			if o.Branch != "" {
+				name = entity.BranchLabel(o.Branch, o.BranchName)
+			}
+			seen := o.ChangedAt
+			if o.CheckedAt.After(seen) {
+				seen = o.CheckedAt
+			}
+			out = append(out, web.Offer{
+				Name:     name,
+				Location: strings.TrimSpace(strings.Join([]string{o.City, o.Address}, " ")),
+				Rate:     o.Rate,
+				Age:      now.Sub(seen),
+			})
+		}
+		return out
+	}
+
+	rows := make([]web.BestRate, 0, len(best))
+	for _, b := range best {
+		rows = append(rows, web.BestRate{
+			CurrencyCode: b.CurrencyCode,
+			Channel:      b.Channel,
+			Buy:          offers(b.Buy),
+			Sell:         offers(b.Sell),
+		})
+	}
+	web.RenderHTML(w, r, web.BestPage(rows, s.cities(r.Context()), city))
+}
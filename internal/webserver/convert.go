@@ -0,0 +1,86 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+// handleConvertPage shows the converter form and, once submitted, the amount at every bank.
+// Query: amount, from, to, city.
+func (s *Server) handleConvertPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	form := web.ConvertForm{
+		Amount: q.Get("amount"),
+		From:   strings.ToUpper(q.Get("from")),
+		To:     strings.ToUpper(q.Get("to")),
+		City:   q.Get("city"),
+	}
+	if form.From == "" {
+		form.From = "USD"
+	}
+	if form.To == "" {
+		form.To = entity.QuoteKZT
+	}
+
+	form.Currencies = s.currencies(true)
+	names := map[string]string{}
+	for _, info := range s.uc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+
+	var result *web.ConvertResult
+	if form.Amount != "" {
+		result = &web.ConvertResult{}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(form.Amount, ",", "."), 64)
+		if err != nil {
+			amount = 0 // отклонит usecase
+		}
+		conv, err := s.uc.Convert(r.Context(), amount, form.From, form.To, &exrate.ExchangeRateFilter{City: form.City})
+		switch {
+		case errors.Is(err, internalErrors.ErrInvalidArgument):
+			result.Error = "Укажите положительную сумму и разные валюты"
+		case errors.Is(err, internalErrors.ErrNotFound):
+			result.Error = "Нет курсов для этой пары валют"
+		case err != nil:
+			s.l.Error("convert failed", "err", err)
+			http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+			return
+		default:
+			now := time.Now()
+			option := func(o entity.ConversionOption) web.ConvertOption {
+				name := names[o.Source]
+				if name == "" {
+					name = o.Source
+				}
+				if o.Branch != "" {
+					name = entity.BranchLabel(o.Branch, o.BranchName)
+				}
+				return web.ConvertOption{
+					Name:    name,
+					Channel: o.Channel,
+					City:    o.City,
+					Rate:    o.Rate,
+					Result:  o.Result,
+					Via:     o.Via,
+					Age:     now.Sub(o.ChangedAt),
+				}
+			}
+			if conv.Official != nil {
+				official := option(*conv.Official)
+				result.Official = &official
+			}
+			for _, o := range conv.Options {
+				result.Options = append(result.Options, option(o))
+			}
+		}
+	}
+	web.RenderHTML(w, r, web.ConvertPage(form, s.cities(r.Context()), result))
+}
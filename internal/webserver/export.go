@@ -0,0 +1,90 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/export"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// handleAPIExport streams stored rates as a file for spreadsheets.
+// Query: format (csv, jsonl, xlsx; csv by default), currency, source, channel, city, from, to,
+// tz (IANA zone of timestamps and plain dates, server zone by default).
+func (s *Server) handleAPIExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := export.CSV
+	if v := q.Get("format"); v != "" {
+		f, err := export.ParseFormat(v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		format = f
+	}
+	loc := time.Local
+	if v := q.Get("tz"); v != "" {
+		l, err := time.LoadLocation(v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+			return
+		}
+		loc = l
+	}
+	filter := &exrate.ExchangeRateFilter{
+		CurrencyCode: strings.ToUpper(q.Get("currency")),
+		Source:       q.Get("source"),
+		Channel:      q.Get("channel"),
+		City:         q.Get("city"),
+	}
+	var err error
+	if filter.StartDate, err = export.ParseTime(q.Get("from"), false, loc); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.EndDate, err = export.ParseTime(q.Get("to"), true, loc); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	// После первого байта статус уже не поменять: неверный диапазон проверяем заранее
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.EndDate.Before(filter.StartDate) {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportFileName(filter, format, loc)+`"`)
+	n, err := export.Export(r.Context(), s.uc, filter, w, format, loc)
+	if err != nil && !errors.Is(err, r.Context().Err()) {
+		// Клиент получит обрезанный файл: CSV и JSONL без последних строк, XLSX не откроется
+		s.l.Error("api export failed", "rows", n, "err", err)
+	}
+}
+
+// exportFileName names the download after the filter, e.g. exr-usd-halyk-2024-11-01-2024-11-30.csv,
+// with dates in the zone of the export.
+func exportFileName(filter *exrate.ExchangeRateFilter, f export.Format, loc *time.Location) string {
+	parts := []string{"exr"}
+	for _, p := range []string{filter.CurrencyCode, filter.Source, filter.Channel} {
+		if p != "" {
+			parts = append(parts, strings.ToLower(p))
+		}
+	}
+	if !filter.StartDate.IsZero() {
+		parts = append(parts, filter.StartDate.In(loc).Format(apiDateLayout))
+	}
+	if !filter.EndDate.IsZero() {
+		parts = append(parts, filter.EndDate.In(loc).Format(apiDateLayout))
+	}
+	name := strings.Join(parts, "-") + "." + string(f)
+	// В заголовок попадает ввод пользователя: оставляем только безопасные символы
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && (r == '-' || r == '.' || r == '_' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,355 @@
+package webserver
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+// Feed formats.
+const (
+	feedAtom = "atom"
+	feedRSS  = "rss"
+)
+
+// feedMaxAge lets readers and proxies cache a feed for one refresh tick.
+const feedMaxAge = refreshTick
+
+// feed is a format-neutral list of rate changes.
+type feed struct {
+	Title   string
+	Link    string // страница на сайте
+	Self    string // адрес самой ленты
+	ID      string
+	Updated time.Time
+	Entries []feedEntry
+}
+
+type feedEntry struct {
+	ID      string
+	Title   string
+	Summary string
+	Link    string
+	At      time.Time
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Self          rssLink   `xml:"http://www.w3.org/2005/Atom link"` // ссылка на ленту, как советует валидатор RSS
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// handleCurrencyFeed serves recent changes of a currency across all sources as Atom or RSS.
+// Query: limit.
+func (s *Server) handleCurrencyFeed(w http.ResponseWriter, r *http.Request) {
+	currency := strings.ToUpper(chi.URLParam(r, "currency"))
+	if len(currency) != 3 {
+		http.Error(w, internalErrors.ErrInvalidArgument.Error(), http.StatusBadRequest)
+		return
+	}
+	base := requestBaseURL(r)
+	s.serveFeed(w, r, &exrate.ExchangeRateFilter{CurrencyCode: currency}, feed{
+		Title: "Курс " + currency + " в банках Казахстана",
+		Link:  base + "/c/" + strings.ToLower(currency),
+		ID:    feedTagID(r, "currency/"+currency),
+	})
+}
+
+// handleBankFeed serves recent changes of all currencies of one source as Atom or RSS.
+// Query: limit.
+func (s *Server) handleBankFeed(w http.ResponseWriter, r *http.Request) {
+	source := chi.URLParam(r, "source")
+	var info entity.SourceInfo
+	for _, si := range s.uc.Sources() {
+		if strings.EqualFold(si.ID, source) {
+			info = si
+			break
+		}
+	}
+	if info.ID == "" {
+		http.Error(w, internalErrors.ErrNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	link := info.Homepage
+	if link == "" {
+		link = requestBaseURL(r) + "/"
+	}
+	s.serveFeed(w, r, &exrate.ExchangeRateFilter{Source: info.ID}, feed{
+		Title: "Курсы валют: " + info.DisplayName(),
+		Link:  link,
+		ID:    feedTagID(r, "bank/"+info.ID),
+	})
+}
+
+// serveFeed fills the feed with changes matching the filter and writes it in the format of the route.
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request, filter *exrate.ExchangeRateFilter, f feed) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, internalErrors.ErrInvalidArgument.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	changes, err := s.uc.RecentChanges(r.Context(), filter, limit)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("feed changes failed", "path", r.URL.Path, "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := map[string]string{}
+	for _, info := range s.uc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	base := requestBaseURL(r)
+	f.Self = base + r.URL.RequestURI()
+	// Пустая лента обновлена сейчас, иначе — последним изменением
+	f.Updated = time.Now().UTC()
+	for i, c := range changes {
+		if i == 0 {
+			f.Updated = c.CreatedAt.UTC()
+		}
+		f.Entries = append(f.Entries, newFeedEntry(r, base, names, c))
+	}
+
+	format := chi.URLParam(r, "format")
+	var doc any
+	switch format {
+	case feedAtom:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		doc = f.atom()
+	case feedRSS:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		doc = f.rss()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedMaxAge.Seconds())))
+	w.Header().Set("Last-Modified", f.Updated.Format(http.TimeFormat))
+	_, _ = w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err = enc.Encode(doc); err != nil {
+		s.l.Warn("feed write failed", "path", r.URL.Path, "err", err)
+	}
+}
+
+// newFeedEntry describes one stored rate: its value and the change against the previous rate of the series.
+func newFeedEntry(r *http.Request, base string, names map[string]string, c *entity.ExchangeRate) feedEntry {
+	name := names[c.Source]
+	if name == "" {
+		name = c.Source
+	}
+	if c.Branch != "" {
+		name += ", " + c.BranchLabel()
+	}
+	pair := c.CurrencyCode
+	if c.Quote() != entity.QuoteKZT {
+		pair += "/" + c.Quote()
+	}
+	title := name + " · " + pair
+	if label := feedChannelLabel(c.Channel); label != "" {
+		title += " " + label
+	}
+	title += ": " + feedRate(c.Buy) + " / " + feedRate(c.Sell)
+
+	summary := "Покупка " + feedRate(c.Buy) + " (" + feedDelta(c.BuyChangePrev) + "), продажа " +
+		feedRate(c.Sell) + " (" + feedDelta(c.SellChangePrev) + ")"
+	if c.City != "" {
+		summary += ". " + c.City
+		if c.Address != "" {
+			summary += ", " + c.Address
+		}
+	}
+	summary += "."
+
+	key := strings.Join([]string{c.Source, c.Branch, c.Channel, c.CurrencyCode}, "/")
+	return feedEntry{
+		ID:      feedTagID(r, key+"/"+c.CreatedAt.UTC().Format(time.RFC3339Nano)),
+		Title:   title,
+		Summary: summary,
+		Link:    base + "/c/" + strings.ToLower(c.CurrencyCode),
+		At:      c.CreatedAt.UTC(),
+	}
+}
+
+func (f feed) atom() atomFeed {
+	out := atomFeed{
+		Title:   f.Title,
+		ID:      f.ID,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Author: atomAuthor{Name: "exr"},
+	}
+	for _, e := range f.Entries {
+		out.Entries = append(out.Entries, atomEntry{
+			Title:   e.Title,
+			ID:      e.ID,
+			Updated: e.At.Format(time.RFC3339),
+			Link:    atomLink{Href: e.Link, Rel: "alternate", Type: "text/html"},
+			Summary: e.Summary,
+		})
+	}
+	return out
+}
+
+func (f feed) rss() rssFeed {
+	out := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Title + ": изменения курсов покупки и продажи",
+			Language:      "ru",
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+			TTL:           int(feedMaxAge.Minutes()),
+			Self:          rssLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, e := range f.Entries {
+		out.Channel.Items = append(out.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{Value: e.ID},
+			PubDate:     e.At.Format(time.RFC1123Z),
+			Description: e.Summary,
+		})
+	}
+	return out
+}
+
+// requestBaseURL is the scheme and host the client used, honouring a TLS-terminating proxy.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// feedTagID builds a stable tag URI (RFC 4151) for a feed or an entry.
+func feedTagID(r *http.Request, specific string) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return "tag:" + host + ",2024:" + (&url.URL{Path: specific}).EscapedPath()
+}
+
+// feedRate formats a stored rate with at least two decimals.
+func feedRate(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return "—"
+	}
+	// Курсы с копейками мельче сотых (рубль, юань) не округляем
+	if s := strconv.FormatFloat(f, 'f', -1, 64); strings.Contains(s, ".") && len(s)-strings.IndexByte(s, '.') > 3 {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// feedDelta formats a change of a rate with its sign.
+func feedDelta(d float64) string {
+	// Разность строк-курсов во float даёт хвосты вида 2.4999999
+	d = math.Round(d*1e4) / 1e4
+	if d == 0 {
+		return "без изменений"
+	}
+	s := strconv.FormatFloat(d, 'f', -1, 64)
+	if d > 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+func feedChannelLabel(channel string) string {
+	switch channel {
+	case entity.ChannelCash:
+		return "наличные"
+	case entity.ChannelNonCash:
+		return "безналичные"
+	case entity.ChannelCard:
+		return "по карте"
+	case entity.ChannelOfficial:
+		return "официальный"
+	}
+	return channel
+}
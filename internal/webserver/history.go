@@ -0,0 +1,261 @@
+package webserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+const (
+	// historyDefaultDays is the range shown when the form has no dates.
+	historyDefaultDays = 30
+	// historyDefaultSources is how many banks are plotted when none are selected.
+	historyDefaultSources = 3
+)
+
+// handleHistoryPage plots rate history of chosen banks with the NBRK rate as a reference line.
+// Query: currency, from, to (YYYY-MM-DD or RFC3339), source (repeated), side (buy, sell; repeated),
+// interval (hour, day, week, month: plot candle closes instead of every change).
+func (s *Server) handleHistoryPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := parseAPITime(q.Get("from"), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseAPITime(q.Get("to"), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() || !from.Before(to) {
+		from = to.AddDate(0, 0, -historyDefaultDays)
+	}
+
+	form := web.HistoryForm{
+		Currency:   strings.ToUpper(q.Get("currency")),
+		From:       from.Format(apiDateLayout),
+		To:         to.Format(apiDateLayout),
+		Sources:    q["source"],
+		Sides:      q["side"],
+		Interval:   q.Get("interval"),
+		Currencies: s.currencies(false),
+	}
+	if form.Currency == "" {
+		form.Currency = "USD"
+	}
+	if form.Interval != "" && !entity.CandleInterval(form.Interval).Valid() {
+		http.Error(w, internalErrors.ErrInvalidArgument.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(form.Sides) == 0 {
+		form.Sides = []string{entity.SideBuy, entity.SideSell}
+	}
+
+	names := map[string]string{}
+	officialName := exrate.OfficialSource
+	for _, info := range s.uc.Sources() {
+		if info.ID == exrate.OfficialSource {
+			officialName = info.DisplayName()
+		}
+		// Иностранные ЦБ котируют не в тенге, НБРК рисуется опорной линией
+		if info.Kind == entity.SourceKindCentralBank {
+			continue
+		}
+		names[info.ID] = info.DisplayName()
+		form.Options = append(form.Options, web.HistorySource{ID: info.ID, Name: info.DisplayName()})
+		if len(q["source"]) == 0 && len(form.Sources) < historyDefaultSources && quotes(info, form.Currency) {
+			form.Sources = append(form.Sources, info.ID)
+		}
+	}
+
+	var series []web.ChartSeries
+	for _, id := range form.Sources {
+		name := names[id]
+		if name == "" {
+			name = id
+		}
+		got, err := s.historySeries(r.Context(), form, id, name, form.Sides, from, to)
+		if err != nil {
+			s.l.Error("get history failed", "source", id, "err", err)
+			http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+			return
+		}
+		series = append(series, got...)
+	}
+	// У официального курса одно значение, его покупка — опорная линия
+	official, err := s.historySeries(r.Context(), form, exrate.OfficialSource, officialName,
+		[]string{entity.SideBuy}, from, to)
+	if err != nil {
+		s.l.Error("get official history failed", "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(official) > 0 {
+		ref := official[0]
+		ref.Name, ref.Dashed = officialName, true
+		series = append(series, ref)
+	}
+
+	web.RenderHTML(w, r, web.HistoryPage(form, web.NewChart(series, from, to)))
+}
+
+// historySeries returns the source's series for the chosen sides: raw changes, or closes of candles
+// when the form asks for an interval.
+func (s *Server) historySeries(
+	ctx context.Context,
+	form web.HistoryForm,
+	source, name string,
+	sides []string,
+	from, to time.Time,
+) ([]web.ChartSeries, error) {
+	if form.Interval == "" {
+		rates, err := s.history(ctx, form.Currency, source, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return rateSeries(name, rates, sides), nil
+	}
+
+	candles, err := s.uc.Candles(ctx, &exrate.CandleFilter{
+		CurrencyCode: form.Currency,
+		Source:       source,
+		Interval:     entity.CandleInterval(form.Interval),
+		StartDate:    from,
+		EndDate:      to,
+	})
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return candleSeries(name, candles, sides), nil
+}
+
+// history returns rates of the source in range, oldest first, each channel starting with the rate
+// in force at from; offices of aggregators are left out.
+func (s *Server) history(ctx context.Context, currency, source string, from, to time.Time) ([]*entity.ExchangeRate, error) {
+	// Курс, действовавший на начало периода, — первая точка линии: иначе она начиналась бы с первого изменения
+	seeds, err := s.uc.RatesAt(ctx, currency, source, from)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, err
+	}
+	rates, err := s.uc.GetRates(ctx, &exrate.ExchangeRateFilter{
+		CurrencyCode: currency,
+		Source:       source,
+		StartDate:    from,
+		EndDate:      to,
+	})
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		return nil, err
+	}
+	out := make([]*entity.ExchangeRate, 0, len(seeds)+len(rates))
+	for _, r := range seeds {
+		seed := *r
+		seed.CreatedAt = from
+		out = append(out, &seed)
+	}
+	for _, r := range rates {
+		if r.Branch == "" && r.Quote() == entity.QuoteKZT {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// rateSeries splits rates of one source into a series per channel and side.
+func rateSeries(name string, rates []*entity.ExchangeRate, sides []string) []web.ChartSeries {
+	var channels []string
+	byChannel := map[string][]*entity.ExchangeRate{}
+	for _, r := range rates {
+		if _, ok := byChannel[r.Channel]; !ok {
+			channels = append(channels, r.Channel)
+		}
+		byChannel[r.Channel] = append(byChannel[r.Channel], r)
+	}
+
+	var out []web.ChartSeries
+	for _, ch := range channels {
+		for _, side := range sides {
+			s := web.ChartSeries{Name: name}
+			if len(channels) > 1 && ch != "" {
+				s.Name += " · " + ch
+			}
+			s.Name += sideSuffix(side)
+			for _, r := range byChannel[ch] {
+				raw := r.Buy
+				if side == entity.SideSell {
+					raw = r.Sell
+				}
+				if v, err := strconv.ParseFloat(raw, 64); err == nil && v > 0 {
+					s.Points = append(s.Points, web.ChartPoint{At: r.CreatedAt, Value: v})
+				}
+			}
+			if len(s.Points) > 0 {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// candleSeries plots closes of candles per channel and side; candles come sorted by channel, side, start.
+func candleSeries(name string, candles []*entity.Candle, sides []string) []web.ChartSeries {
+	channels := map[string]struct{}{}
+	for _, c := range candles {
+		channels[c.Channel] = struct{}{}
+	}
+	var out []web.ChartSeries
+	var channel, side string
+	for _, c := range candles {
+		if !web.Has(sides, c.Side) {
+			continue
+		}
+		if len(out) == 0 || c.Channel != channel || c.Side != side {
+			channel, side = c.Channel, c.Side
+			s := web.ChartSeries{Name: name}
+			if len(channels) > 1 && channel != "" {
+				s.Name += " · " + channel
+			}
+			s.Name += sideSuffix(side)
+			out = append(out, s)
+		}
+		last := &out[len(out)-1]
+		last.Points = append(last.Points, web.ChartPoint{At: c.Start, Value: c.Close})
+	}
+	return out
+}
+
+func sideSuffix(side string) string {
+	if side == entity.SideSell {
+		return " · продажа"
+	}
+	return " · покупка"
+}
+
+// quotes reports whether the source declares the currency; sources without a list may quote anything.
+func quotes(info entity.SourceInfo, currency string) bool {
+	if len(info.Currencies) == 0 {
+		return true
+	}
+	for _, c := range info.Currencies {
+		if strings.EqualFold(c, currency) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,13 @@ package webserver
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,39 +19,126 @@ import (
 	"github.com/Mi7teR/exr/internal/web"
 )
 
+// refreshTick is how often the usecase is asked to poll the drivers that are due by their schedule.
+const refreshTick = 5 * time.Minute
+
 // ExchangeRateService определяет интерфейс для работы с курсами валют
 type ExchangeRateService interface {
 	GetRates(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error)
+	LatestRates(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error)
+	RatesAt(ctx context.Context, currencyCode, source string, at time.Time) ([]*entity.ExchangeRate, error)
 	AddRates(ctx context.Context) error
+	ImpliedRates(ctx context.Context) ([]*entity.ImpliedRate, error)
+	Sources() []entity.SourceInfo
+	SchemaNotices() []entity.SchemaNotice
+	GetQuarantinedRates(ctx context.Context, startDate, endDate time.Time) ([]*entity.QuarantinedRate, error)
+	ReleaseQuarantinedRate(ctx context.Context, id int64) error
+	GetAnomalies(ctx context.Context, startDate, endDate time.Time) ([]*entity.Anomaly, error)
+	BestRates(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.BestRate, error)
+	Convert(ctx context.Context, amount float64, from, to string, filter *exrate.ExchangeRateFilter) (*entity.Conversion, error)
+	Candles(ctx context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error)
+	Spreads(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]entity.Spread, error)
+	SpreadHistory(ctx context.Context, filter *exrate.SpreadFilter) (*entity.SpreadHistory, error)
+	CreateAlertRule(ctx context.Context, rule *entity.AlertRule) error
+	AlertRules(ctx context.Context) ([]*entity.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id int64) error
+	AlertEvents(ctx context.Context, startDate, endDate time.Time) ([]*entity.AlertEvent, error)
+	RecentChanges(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.ExchangeRate, error)
+	ExportRates(ctx context.Context, filter *exrate.ExchangeRateFilter, fn func(rate *entity.ExchangeRate) error) error
 }
 
 type Server struct {
-	l      logger.Logger
-	uc     ExchangeRateService
-	router *chi.Mux
+	l          logger.Logger
+	uc         ExchangeRateService
+	router     *chi.Mux
+	adminToken string
+	webhooks   WebhookService
+	live       RateStream
 }
 
 func NewServer(l logger.Logger, uc ExchangeRateService) *Server {
 	return &Server{l: l, uc: uc}
 }
 
+// SetAdminToken protects admin endpoints with "Authorization: Bearer <token>"; empty disables them.
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
 func (s *Server) createRouter() *chi.Mux {
 	router := chi.NewRouter()
 
 	// ЧПУ маршруты
-	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_ = web.IndexPage("usd").Render(r.Context(), w)
-	})
+	router.Get("/", s.handleCurrencyPage)
 	router.Get("/c/{currency}", s.handleCurrencyPage)
+	router.Get("/best", s.handleBestPage)
+	router.Get("/convert", s.handleConvertPage)
+	router.Get("/history", s.handleHistoryPage)
+	router.Get("/spreads", s.handleSpreadsPage)
+	router.Get("/anomalies", s.handleAnomaliesPage)
+	router.Get("/feeds/{currency}.{format:atom|rss}", s.handleCurrencyFeed)
+	router.Get("/feeds/bank/{source}.{format:atom|rss}", s.handleBankFeed)
+	if s.live != nil {
+		router.Get("/c/{currency}/stream", s.handleBanksStream)
+		router.Get("/api/rates/stream", s.handleAPIRatesStream)
+		router.Get("/api/ws", s.handleWebSocket)
+	}
+
+	// JSON API
+	router.Get("/api/rates", s.handleAPIRates)
+	router.Get("/api/implied", s.handleAPIImplied)
+	router.Get("/api/sources", s.handleAPISources)
+	router.Get("/api/best", s.handleAPIBest)
+	router.Get("/api/convert", s.handleAPIConvert)
+	router.Get("/api/candles", s.handleAPICandles)
+	router.Get("/api/spreads", s.handleAPISpreads)
+	router.Get("/api/spreads/history", s.handleAPISpreadHistory)
+	router.Get("/api/anomalies", s.handleAPIAnomalies)
+	router.Get("/api/alerts", s.handleAPIAlertRules)
+	router.Get("/api/alerts/events", s.handleAPIAlertEvents)
+	router.Get("/api/export", s.handleAPIExport)
+
+	// Служебное: правка правил оповещений, уведомления об изменении формата ответов банков, карантин курсов,
+	// вебхуки и метрики expvar
+	router.Group(func(admin chi.Router) {
+		admin.Use(s.requireAdmin)
+		admin.Post("/api/alerts", s.handleAPICreateAlertRule)
+		admin.Delete("/api/alerts/{id}", s.handleAPIDeleteAlertRule)
+		admin.Get("/api/admin/schema", s.handleAPISchemaNotices)
+		admin.Get("/api/admin/quarantine", s.handleAPIQuarantine)
+		admin.Post("/api/admin/quarantine/{id}/release", s.handleAPIReleaseQuarantined)
+		if s.webhooks != nil {
+			admin.Get("/api/admin/webhooks", s.handleAPIWebhooks)
+			admin.Post("/api/admin/webhooks", s.handleAPICreateWebhook)
+			admin.Delete("/api/admin/webhooks/{id}", s.handleAPIDeleteWebhook)
+			admin.Get("/api/admin/webhooks/{id}/deliveries", s.handleAPIWebhookDeliveries)
+		}
+		admin.Handle("/debug/vars", expvar.Handler())
+	})
 
 	return router
 }
 
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Без токена служебные маршруты закрыты, а не открыты всем
+		if s.adminToken == "" {
+			writeJSONError(w, http.StatusForbidden, errors.New("admin endpoints are disabled: admin token is not set"))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) Start(addr string) error {
 	s.router = s.createRouter()
 
-	// Фоновое обновление каждые 30 минут
+	// Фоновое обновление, драйверы опрашиваются по своему расписанию
 	go s.startBackgroundRefresh()
 
 	return http.ListenAndServe(addr, s.router)
@@ -64,7 +155,7 @@ func (s *Server) GetRouter() http.Handler {
 func (s *Server) startBackgroundRefresh() {
 	// первичная загрузка
 	s.refreshOnce()
-	ticker := time.NewTicker(30 * time.Minute)
+	ticker := time.NewTicker(refreshTick)
 	defer ticker.Stop()
 	for range ticker.C {
 		s.refreshOnce()
@@ -84,36 +175,106 @@ func (s *Server) handleCurrencyPage(w http.ResponseWriter, r *http.Request) {
 	if currency == "" {
 		currency = "usd"
 	}
+	city := r.URL.Query().Get("city")
 
 	// Если это HTMX-запрос, возвращаем только содержимое таба
 	if r.Header.Get("HX-Request") == "true" || r.Header.Get("Hx-Request") == "true" {
-		banks, err := s.gatherBanks(r.Context(), currency)
+		banks, err := s.gatherBanks(r.Context(), currency, city)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_ = web.TabContent(banks, currency).Render(r.Context(), w)
+		_ = web.TabContent(banks, currency, s.liveURL(currency, city)).Render(r.Context(), w)
 		return
 	}
 
 	// Иначе рендерим полную страницу
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	_ = web.IndexPage(currency).Render(r.Context(), w)
+	_ = web.IndexPage(currency, s.cities(r.Context()), city).Render(r.Context(), w)
+}
+
+// cities returns sorted list of cities that have office rates, for the city filter.
+func (s *Server) cities(ctx context.Context) []string {
+	rates, err := s.uc.GetRates(ctx, &exrate.ExchangeRateFilter{})
+	if err != nil {
+		return nil
+	}
+	seen := map[string]struct{}{}
+	var out []string
+	for _, r := range rates {
+		if r.City == "" {
+			continue
+		}
+		if _, ok := seen[r.City]; ok {
+			continue
+		}
+		seen[r.City] = struct{}{}
+		out = append(out, r.City)
+	}
+	sort.Strings(out)
+	return out
 }
 
-func (s *Server) gatherBanks(ctx context.Context, currency string) ([]web.Bank, error) {
-	filter := &exrate.ExchangeRateFilter{StartDate: time.Time{}, EndDate: time.Time{}}
+// currencies returns sorted foreign currencies declared by the sources, tenge first if asked.
+func (s *Server) currencies(withKZT bool) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, info := range s.uc.Sources() {
+		for _, c := range info.Currencies {
+			if _, ok := seen[c]; ok || c == entity.QuoteKZT {
+				continue
+			}
+			seen[c] = struct{}{}
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	if withKZT {
+		out = append([]string{entity.QuoteKZT}, out...)
+	}
+	return out
+}
+
+func (s *Server) gatherBanks(ctx context.Context, currency, city string) ([]web.Bank, error) {
+	filter := &exrate.ExchangeRateFilter{StartDate: time.Time{}, EndDate: time.Time{}, City: city}
 	rates, err := s.uc.GetRates(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("get rates: %w", err)
 	}
-	banksMap := map[string]*web.Bank{}
+	sources := map[string]entity.SourceInfo{}
+	for _, info := range s.uc.Sources() {
+		sources[info.ID] = info
+	}
+	banksMap := map[entity.SeriesKey]*web.Bank{}
+	// Банк может котировать валюту в нескольких каналах, показываем самый свежий курс
+	shownAt := map[entity.SeriesKey]time.Time{}
 	for _, r := range rates {
-		b, ok := banksMap[r.Source]
+		// Иностранные ЦБ котируют не в тенге, на странице банков им не место
+		if r.Quote() != entity.QuoteKZT {
+			continue
+		}
+		// Банк целиком или отдельный обменник внутри агрегатора
+		key := entity.SeriesKey{Source: r.Source, Branch: r.Branch}
+		rateKey := entity.SeriesKey{Source: r.Source, Branch: r.Branch, CurrencyCode: r.CurrencyCode}
+		if at, ok := shownAt[rateKey]; ok && at.After(r.CreatedAt) {
+			continue
+		}
+		shownAt[rateKey] = r.CreatedAt
+		b, ok := banksMap[key]
 		if !ok {
-			b = &web.Bank{Name: r.Source, Location: "KZ"}
-			banksMap[r.Source] = b
+			info, known := sources[r.Source]
+			b = &web.Bank{Name: r.Source, Location: "KZ", Address: r.Address}
+			if known {
+				b.Name, b.Homepage, b.Logo = info.DisplayName(), info.Homepage, info.Logo
+			}
+			if r.Branch != "" {
+				b.Name = r.BranchLabel()
+			}
+			if r.City != "" {
+				b.Location = r.City
+			}
+			banksMap[key] = b
 		}
 
 		// Парсим курсы покупки и продажи
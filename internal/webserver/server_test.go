@@ -1,15 +1,24 @@
 package webserver
 
 import (
+	"bufio"
 	"context"
+	"encoding/xml"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+
 	"github.com/Mi7teR/exr/internal/application/logger"
 	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
 	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/service/pubsub"
 )
 
 // mockLogger реализует интерфейс logger.Logger для тестов
@@ -24,7 +33,152 @@ func (m *mockLogger) With(args ...any) logger.Logger { return m }
 // mockExchangeRateService реализует интерфейс ExchangeRateService для тестов
 type mockExchangeRateService struct {
 	getRatesFunc func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error)
+	latestFunc   func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error)
+	ratesAtFunc  func(ctx context.Context, currencyCode, source string, at time.Time) ([]*entity.ExchangeRate, error)
 	addRatesFunc func(ctx context.Context) error
+	impliedFunc  func(ctx context.Context) ([]*entity.ImpliedRate, error)
+	sources      []entity.SourceInfo
+	notices      []entity.SchemaNotice
+	quarantined  []*entity.QuarantinedRate
+	released     []int64
+	anomalies    []*entity.Anomaly
+	best         []*entity.BestRate
+	bestFilter   *exrate.ExchangeRateFilter
+	convertFunc  func(ctx context.Context, amount float64, from, to string, filter *exrate.ExchangeRateFilter) (*entity.Conversion, error)
+	candlesFunc  func(ctx context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error)
+	spreadsFunc  func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]entity.Spread, error)
+	historyFunc  func(ctx context.Context, filter *exrate.SpreadFilter) (*entity.SpreadHistory, error)
+	alertRules   []*entity.AlertRule
+	alertEvents  []*entity.AlertEvent
+	changesFunc  func(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.ExchangeRate, error)
+	exported     []*entity.ExchangeRate
+	exportFilter *exrate.ExchangeRateFilter
+}
+
+func (m *mockExchangeRateService) ExportRates(
+	ctx context.Context,
+	filter *exrate.ExchangeRateFilter,
+	fn func(rate *entity.ExchangeRate) error,
+) error {
+	m.exportFilter = filter
+	for _, r := range m.exported {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockExchangeRateService) RecentChanges(
+	ctx context.Context,
+	filter *exrate.ExchangeRateFilter,
+	limit int,
+) ([]*entity.ExchangeRate, error) {
+	if m.changesFunc != nil {
+		return m.changesFunc(ctx, filter, limit)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) Spreads(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]entity.Spread, error) {
+	if m.spreadsFunc != nil {
+		return m.spreadsFunc(ctx, filter)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) SpreadHistory(ctx context.Context, filter *exrate.SpreadFilter) (*entity.SpreadHistory, error) {
+	if m.historyFunc != nil {
+		return m.historyFunc(ctx, filter)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) CreateAlertRule(ctx context.Context, rule *entity.AlertRule) error {
+	if rule.Kind == "" {
+		return internalErrors.ErrInvalidArgument
+	}
+	rule.ID = int64(len(m.alertRules) + 1)
+	m.alertRules = append(m.alertRules, rule)
+	return nil
+}
+
+func (m *mockExchangeRateService) AlertRules(ctx context.Context) ([]*entity.AlertRule, error) {
+	if len(m.alertRules) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.alertRules, nil
+}
+
+func (m *mockExchangeRateService) DeleteAlertRule(ctx context.Context, id int64) error {
+	for i, r := range m.alertRules {
+		if r.ID == id {
+			m.alertRules = append(m.alertRules[:i], m.alertRules[i+1:]...)
+			return nil
+		}
+	}
+	return internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) AlertEvents(ctx context.Context, startDate, endDate time.Time) ([]*entity.AlertEvent, error) {
+	if len(m.alertEvents) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.alertEvents, nil
+}
+
+func (m *mockExchangeRateService) Candles(ctx context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error) {
+	if m.candlesFunc != nil {
+		return m.candlesFunc(ctx, filter)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) Convert(ctx context.Context, amount float64, from, to string, filter *exrate.ExchangeRateFilter) (*entity.Conversion, error) {
+	if m.convertFunc != nil {
+		return m.convertFunc(ctx, amount, from, to, filter)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) BestRates(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.BestRate, error) {
+	m.bestFilter = filter
+	if len(m.best) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.best, nil
+}
+
+func (m *mockExchangeRateService) GetAnomalies(ctx context.Context, startDate, endDate time.Time) ([]*entity.Anomaly, error) {
+	if len(m.anomalies) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.anomalies, nil
+}
+
+func (m *mockExchangeRateService) Sources() []entity.SourceInfo {
+	return m.sources
+}
+
+func (m *mockExchangeRateService) SchemaNotices() []entity.SchemaNotice {
+	return m.notices
+}
+
+func (m *mockExchangeRateService) GetQuarantinedRates(ctx context.Context, startDate, endDate time.Time) ([]*entity.QuarantinedRate, error) {
+	if len(m.quarantined) == 0 {
+		return nil, internalErrors.ErrNotFound
+	}
+	return m.quarantined, nil
+}
+
+func (m *mockExchangeRateService) ReleaseQuarantinedRate(ctx context.Context, id int64) error {
+	for _, q := range m.quarantined {
+		if q.ID == id {
+			m.released = append(m.released, id)
+			return nil
+		}
+	}
+	return internalErrors.ErrNotFound
 }
 
 func (m *mockExchangeRateService) GetRates(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
@@ -34,6 +188,24 @@ func (m *mockExchangeRateService) GetRates(ctx context.Context, filter *exrate.E
 	return nil, nil
 }
 
+func (m *mockExchangeRateService) LatestRates(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+	if m.latestFunc != nil {
+		return m.latestFunc(ctx, filter)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
+func (m *mockExchangeRateService) RatesAt(
+	ctx context.Context,
+	currencyCode, source string,
+	at time.Time,
+) ([]*entity.ExchangeRate, error) {
+	if m.ratesAtFunc != nil {
+		return m.ratesAtFunc(ctx, currencyCode, source, at)
+	}
+	return nil, internalErrors.ErrNotFound
+}
+
 func (m *mockExchangeRateService) AddRates(ctx context.Context) error {
 	if m.addRatesFunc != nil {
 		return m.addRatesFunc(ctx)
@@ -41,6 +213,13 @@ func (m *mockExchangeRateService) AddRates(ctx context.Context) error {
 	return nil
 }
 
+func (m *mockExchangeRateService) ImpliedRates(ctx context.Context) ([]*entity.ImpliedRate, error) {
+	if m.impliedFunc != nil {
+		return m.impliedFunc(ctx)
+	}
+	return nil, nil
+}
+
 func TestNewServer(t *testing.T) {
 	logger := &mockLogger{}
 	service := &mockExchangeRateService{}
@@ -202,7 +381,7 @@ func TestServer_GatherBanks(t *testing.T) {
 	server := NewServer(logger, service)
 
 	// Тест сбора банков для USD
-	banks, err := server.gatherBanks(context.Background(), "usd")
+	banks, err := server.gatherBanks(context.Background(), "usd", "")
 	if err != nil {
 		t.Fatalf("gatherBanks returned error: %v", err)
 	}
@@ -236,7 +415,7 @@ func TestServer_GatherBanks(t *testing.T) {
 	}
 
 	// Тест сбора банков для EUR (должен вернуть только банки с EUR курсами)
-	banks, err = server.gatherBanks(context.Background(), "eur")
+	banks, err = server.gatherBanks(context.Background(), "eur", "")
 	if err != nil {
 		t.Fatalf("gatherBanks returned error: %v", err)
 	}
@@ -289,9 +468,441 @@ func BenchmarkServer_GatherBanks(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := server.gatherBanks(context.Background(), "usd")
+		_, err := server.gatherBanks(context.Background(), "usd", "")
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+func TestServer_HandleHistoryPage(t *testing.T) {
+	day := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	var queried []string
+	service := &mockExchangeRateService{
+		sources: []entity.SourceInfo{
+			{ID: "BCC", Name: "Bank CenterCredit", Kind: entity.SourceKindBank, Currencies: []string{"USD"}},
+			{ID: "CBR", Kind: entity.SourceKindCentralBank, Currencies: []string{"USD"}},
+			{ID: "Halyk", Kind: entity.SourceKindBank, Currencies: []string{"USD", "EUR"}},
+			{ID: "NBRK", Name: "Нацбанк РК", Kind: entity.SourceKindCentralBank},
+		},
+		ratesAtFunc: func(_ context.Context, code, source string, at time.Time) ([]*entity.ExchangeRate, error) {
+			if code != "USD" || !at.Equal(day) {
+				t.Errorf("unexpected rates at %s %s %v", code, source, at)
+			}
+			if source != "Halyk" {
+				return nil, internalErrors.ErrNotFound
+			}
+			// Курс, действовавший на начало периода, установлен днём раньше
+			return []*entity.ExchangeRate{{Source: "Halyk", CurrencyCode: "USD", Buy: "490", Sell: "500", CreatedAt: day.Add(-20 * time.Hour)}}, nil
+		},
+		getRatesFunc: func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			queried = append(queried, filter.Source)
+			if filter.CurrencyCode != "USD" || !filter.StartDate.Equal(day) || !filter.EndDate.Equal(day.Add(72*time.Hour-time.Nanosecond)) {
+				t.Errorf("unexpected filter %+v", filter)
+			}
+			switch filter.Source {
+			case "Halyk":
+				// новые первыми, как отдаёт репозиторий; обменник агрегатора на график не попадает
+				return []*entity.ExchangeRate{
+					{Source: "Halyk", CurrencyCode: "USD", Buy: "498", Sell: "503", CreatedAt: day.Add(36 * time.Hour)},
+					{Source: "Halyk", CurrencyCode: "USD", Buy: "495", Sell: "501", CreatedAt: day.Add(12 * time.Hour)},
+					{Source: "Halyk", Branch: "Обменник", CurrencyCode: "USD", Buy: "1", Sell: "2", CreatedAt: day},
+				}, nil
+			case "NBRK":
+				return []*entity.ExchangeRate{{Source: "NBRK", CurrencyCode: "USD", Buy: "499", Sell: "499", CreatedAt: day}}, nil
+			}
+			return nil, internalErrors.ErrNotFound
+		},
+	}
+	server := NewServer(&mockLogger{}, service)
+
+	rr := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?currency=usd&from=2024-11-01&to=2024-11-03&source=Halyk&side=buy", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if n := strings.Count(body, "<polyline"); n != 2 {
+		t.Errorf("got %d lines, want Halyk buy and NBRK", n)
+	}
+	if !strings.Contains(body, "<title>Halyk · покупка</title>") || !strings.Contains(body, `stroke-dasharray="6 4"`) {
+		t.Error("missing Halyk buy series or dashed NBRK reference line")
+	}
+	if strings.Contains(body, "<title>Halyk · продажа</title>") {
+		t.Error("sell series plotted though only buy was selected")
+	}
+	if strings.Join(queried, ",") != "Halyk,NBRK" {
+		t.Errorf("queried sources %v", queried)
+	}
+	// Линия начинается курсом на начало периода, опорная подписана названием из реестра
+	halyk := regexp.MustCompile(`<polyline points="([^"]*)"[^>]*><title>Halyk · покупка</title>`).FindStringSubmatch(body)
+	// Ступеньки: курс на начало периода и два изменения, по две точки на каждое
+	if halyk == nil || len(strings.Fields(halyk[1])) != 6 {
+		t.Errorf("Halyk line = %v, want the rate in force at from and two changes", halyk)
+	}
+	if !strings.Contains(body, "<title>Нацбанк РК</title>") {
+		t.Error("reference line not labelled with the registry name")
+	}
+	if strings.Contains(body, "<script") {
+		t.Error("history page loads scripts")
+	}
+
+	// Без выбора рисуются банки, котирующие валюту; иностранные ЦБ в список не входят
+	queried = nil
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?from=2024-11-01&to=2024-11-03", nil))
+	if strings.Join(queried, ",") != "BCC,Halyk,NBRK" {
+		t.Errorf("default sources queried %v", queried)
+	}
+	if strings.Contains(rr.Body.String(), `value="CBR"`) {
+		t.Error("foreign central bank offered as a source")
+	}
+
+	// С шагом график строится по закрытиям свечей
+	service.candlesFunc = func(_ context.Context, filter *exrate.CandleFilter) ([]*entity.Candle, error) {
+		if filter.Interval != entity.CandleDay || filter.CurrencyCode != "USD" {
+			t.Errorf("unexpected candle filter %+v", filter)
+		}
+		var out []*entity.Candle
+		for _, side := range []string{entity.SideBuy, entity.SideSell} {
+			for i := 0; i < 3; i++ {
+				out = append(out, &entity.Candle{
+					Source: filter.Source, Channel: "cash", Side: side, Start: day.AddDate(0, 0, i), Close: 500 + float64(i),
+				})
+			}
+		}
+		return out, nil
+	}
+	rr = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/history?from=2024-11-01&to=2024-11-03&source=Halyk&interval=day", nil))
+	body = rr.Body.String()
+	if n := strings.Count(body, "<polyline"); n != 3 {
+		t.Errorf("candles: got %d lines, want Halyk buy, sell and NBRK", n)
+	}
+	if !strings.Contains(body, "<title>Halyk · продажа</title>") || !strings.Contains(body, `<option value="day" selected>`) {
+		t.Error("candle series or selected interval missing")
+	}
+
+	for _, url := range []string{"/history?from=yesterday", "/history?interval=minute"} {
+		rr = httptest.NewRecorder()
+		server.GetRouter().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d", url, rr.Code)
+		}
+	}
+}
+
+// readEvent reads the next SSE event, skipping heartbeats.
+func readEvent(t *testing.T, r *bufio.Reader) (name, data string) {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, strings.Join(lines, "\n")
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			lines = append(lines, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestServer_RateStreams(t *testing.T) {
+	rates := []*entity.ExchangeRate{
+		{Source: "Kaspi", CurrencyCode: "USD", Buy: "490.50", Sell: "495.00", BuyChangePrev: 1.5, CreatedAt: time.Now()},
+	}
+	service := &mockExchangeRateService{
+		getRatesFunc: func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			return rates, nil
+		},
+	}
+	broker := pubsub.NewBroker()
+	server := NewServer(&mockLogger{}, service)
+	server.SetRateStream(broker)
+	srv := httptest.NewServer(server.GetRouter())
+	defer srv.Close()
+
+	// Вкладка подключается к потоку своей валюты и города
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/c/usd?city=Алматы", nil)
+	req.Header.Set("HX-Request", "true")
+	server.GetRouter().ServeHTTP(rr, req)
+	if !strings.Contains(rr.Body.String(), `sse-connect="/c/usd/stream?city=%D0%90%D0%BB%D0%BC%D0%B0%D1%82%D1%8B"`) {
+		t.Errorf("tab content is not wired to the stream:\n%s", rr.Body.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	open := func(path string) *bufio.Reader {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("%s content type %q", path, ct)
+		}
+		return bufio.NewReader(resp.Body)
+	}
+	api := open("/api/rates/stream?currency=usd&channel=cash")
+	banks := open("/c/usd/stream")
+	for broker.Subscribers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	broker.RatesStored(ctx, []*entity.ExchangeRate{{Source: "Kaspi", CurrencyCode: "EUR", Channel: entity.ChannelCash, Buy: "530"}})
+	broker.RatesStored(ctx, []*entity.ExchangeRate{
+		{Source: "Kaspi", CurrencyCode: "USD", Channel: entity.ChannelCard, Buy: "491"},
+		{Source: "Kaspi", CurrencyCode: "USD", Channel: entity.ChannelCash, Buy: "490.50", BuyChangePrev: 1.5},
+	})
+
+	name, data := readEvent(t, api)
+	if name != "rates" || !strings.Contains(data, `"currency":"USD"`) || !strings.Contains(data, `"buy_change":1.5`) ||
+		strings.Contains(data, "EUR") || strings.Contains(data, "card") {
+		t.Errorf("api stream event %s: %s", name, data)
+	}
+	name, data = readEvent(t, banks)
+	if name != "banks" || !strings.Contains(data, "Kaspi") || !strings.Contains(data, "490.50") {
+		t.Errorf("banks stream event %s: %s", name, data)
+	}
+
+	cancel()
+	for broker.Subscribers() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServer_WebSocketFeed(t *testing.T) {
+	latest := []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "500", Sell: "505", BuyChangePrev: 1.5},
+		{Source: "Kaspi", Channel: entity.ChannelCard, CurrencyCode: "USD", Buy: "501", Sell: "504"},
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "540", Sell: "548"},
+	}
+	service := &mockExchangeRateService{
+		latestFunc: func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			var out []*entity.ExchangeRate
+			for _, r := range latest {
+				if (filter.CurrencyCode == "" || r.CurrencyCode == filter.CurrencyCode) &&
+					(filter.Source == "" || r.Source == filter.Source) &&
+					(filter.Channel == "" || r.Channel == filter.Channel) {
+					out = append(out, r)
+				}
+			}
+			if len(out) == 0 {
+				return nil, internalErrors.ErrNotFound
+			}
+			return out, nil
+		},
+	}
+	broker := pubsub.NewBroker()
+	server := NewServer(&mockLogger{}, service)
+	server.SetRateStream(broker)
+	srv := httptest.NewServer(server.GetRouter())
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	send := func(msg string) {
+		t.Helper()
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recv := func() wsServerMessage {
+		t.Helper()
+		var msg wsServerMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	send(`{"type":"subscribe","filters":[{"currency":"usd","channel":"cash"}]}`)
+	msg := recv()
+	if msg.Type != wsSnapshot || len(msg.Rates) != 1 || msg.Rates[0].Source != "Halyk" || msg.Rates[0].Currency != "USD" ||
+		msg.Rates[0].BuyChange != 1.5 || len(msg.Filters) != 1 || msg.Filters[0].Currency != "USD" {
+		t.Fatalf("unexpected snapshot %+v", msg)
+	}
+
+	// Обновления приходят только по подпискам
+	ctx := context.Background()
+	broker.RatesStored(ctx, []*entity.ExchangeRate{
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "EUR", Buy: "541"},
+		{Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "502", BuyChangePrev: 2},
+	})
+	msg = recv()
+	if msg.Type != wsUpdate || len(msg.Rates) != 1 || msg.Rates[0].Buy != "502" || msg.Rates[0].BuyChange != 2 {
+		t.Fatalf("unexpected update %+v", msg)
+	}
+
+	send(`{"type":"ping"}`)
+	if msg = recv(); msg.Type != wsPong {
+		t.Errorf("got %s, want pong", msg.Type)
+	}
+	send(`{"type":"subscribe","filters":[{"source":"Kaspi"}]}`)
+	if msg = recv(); msg.Type != wsSnapshot || len(msg.Rates) != 1 || msg.Rates[0].Source != "Kaspi" {
+		t.Errorf("unexpected second snapshot %+v", msg)
+	}
+	send(`{"type":"unsubscribe","filters":[{"currency":"USD","channel":"cash"}]}`)
+	if msg = recv(); msg.Type != wsUnsubscribe || len(msg.Filters) != 1 || msg.Filters[0].Source != "Kaspi" {
+		t.Errorf("unexpected unsubscribe reply %+v", msg)
+	}
+	send(`{"type":"subscribe"`)
+	if msg = recv(); msg.Type != wsError {
+		t.Errorf("broken JSON: got %+v", msg)
+	}
+	send(`{"type":"buy"}`)
+	if msg = recv(); msg.Type != wsError || !strings.Contains(msg.Error, "buy") {
+		t.Errorf("unknown type: got %+v", msg)
+	}
+}
+
+func TestServer_WebSocketFeed_SlowClient(t *testing.T) {
+	var snapshots int
+	service := &mockExchangeRateService{
+		latestFunc: func(ctx context.Context, filter *exrate.ExchangeRateFilter) ([]*entity.ExchangeRate, error) {
+			snapshots++
+			return []*entity.ExchangeRate{{Source: "Halyk", CurrencyCode: "USD", Buy: "500"}}, nil
+		},
+	}
+	broker := pubsub.NewBroker()
+	server := NewServer(&mockLogger{}, service)
+	server.SetRateStream(broker)
+	srv := httptest.NewServer(server.GetRouter())
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err = conn.WriteJSON(wsClientMessage{Type: wsSubscribe, Filters: []wsFilter{{Currency: "USD"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var msg wsServerMessage
+	if err = conn.ReadJSON(&msg); err != nil || msg.Type != wsSnapshot {
+		t.Fatalf("snapshot: %+v, %v", msg, err)
+	}
+
+	// Публикация не ждёт клиента: лишние пачки сверх буфера пропускаются
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10*streamBuffer; i++ {
+			broker.RatesStored(context.Background(), []*entity.ExchangeRate{{Source: "Halyk", CurrencyCode: "USD", Buy: fmt.Sprint(500 + i)}})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing blocked on a slow client")
+	}
+
+	// Клиент получает часть обновлений и снапшот вместо пропущенных
+	resynced := false
+	for !resynced {
+		if err = conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("no resync snapshot: %v", err)
+		}
+		resynced = msg.Type == wsSnapshot && msg.Resync
+	}
+}
+
+func TestServer_Feeds(t *testing.T) {
+	at := time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC)
+	var gotFilter *exrate.ExchangeRateFilter
+	var gotLimit int
+	service := &mockExchangeRateService{
+		sources: []entity.SourceInfo{{ID: "Halyk", Name: "Halyk Bank", Homepage: "https://halykbank.kz"}},
+		changesFunc: func(ctx context.Context, filter *exrate.ExchangeRateFilter, limit int) ([]*entity.ExchangeRate, error) {
+			gotFilter, gotLimit = filter, limit
+			return []*entity.ExchangeRate{
+				{
+					Source: "Halyk", Channel: entity.ChannelCash, CurrencyCode: "USD", Buy: "497.5", Sell: "506",
+					CreatedAt: at, BuyChangePrev: 2.4999999, SellChangePrev: -1,
+				},
+				{Source: "Halyk", Channel: entity.ChannelCard, CurrencyCode: "USD", Buy: "500", Sell: "503", CreatedAt: at.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	router := NewServer(&mockLogger{}, service).GetRouter()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://exr.example:8080"+path, nil))
+		return rec
+	}
+
+	rec := get("/feeds/usd.atom?limit=10")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("atom: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if gotFilter.CurrencyCode != "USD" || gotFilter.Source != "" || gotLimit != 10 {
+		t.Errorf("filter %+v, limit %d", gotFilter, gotLimit)
+	}
+	var atom atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &atom); err != nil {
+		t.Fatal(err)
+	}
+	if atom.Updated != "2024-11-04T09:30:00Z" || len(atom.Entries) != 2 || atom.ID != "tag:exr.example,2024:currency/USD" {
+		t.Fatalf("unexpected atom feed %+v", atom)
+	}
+	e := atom.Entries[0]
+	if e.Title != "Halyk Bank · USD наличные: 497.50 / 506.00" ||
+		e.Summary != "Покупка 497.50 (+2.5), продажа 506.00 (-1)." ||
+		e.Link.Href != "http://exr.example:8080/c/usd" ||
+		e.ID != "tag:exr.example,2024:Halyk//cash/USD/2024-11-04T09:30:00Z" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if s := atom.Entries[1].Summary; s != "Покупка 500.00 (без изменений), продажа 503.00 (без изменений)." {
+		t.Errorf("unchanged entry summary %q", s)
+	}
+
+	rec = get("/feeds/bank/halyk.rss")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/rss+xml") {
+		t.Fatalf("rss: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if gotFilter.Source != "Halyk" || gotFilter.CurrencyCode != "" || gotLimit != 0 {
+		t.Errorf("bank filter %+v, limit %d", gotFilter, gotLimit)
+	}
+	var rss rssFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &rss); err != nil {
+		t.Fatal(err)
+	}
+	if rss.Version != "2.0" || rss.Channel.Title != "Курсы валют: Halyk Bank" || rss.Channel.Link != "https://halykbank.kz" ||
+		!strings.Contains(rec.Body.String(), `href="http://exr.example:8080/feeds/bank/halyk.rss" rel="self"`) ||
+		len(rss.Channel.Items) != 2 || rss.Channel.Items[0].PubDate != "Mon, 04 Nov 2024 09:30:00 +0000" ||
+		rss.Channel.Items[0].GUID.Value != e.ID {
+		t.Errorf("unexpected rss feed %+v", rss.Channel)
+	}
+
+	for path, want := range map[string]int{
+		"/feeds/bank/Kaspi.atom":    http.StatusNotFound,
+		"/feeds/usd.json":           http.StatusNotFound,
+		"/feeds/dollar.atom":        http.StatusBadRequest,
+		"/feeds/usd.rss?limit=much": http.StatusBadRequest,
+	} {
+		if rec = get(path); rec.Code != want {
+			t.Errorf("%s: status %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	// Лента без изменений остаётся валидной
+	service.changesFunc = nil
+	rec = get("/feeds/eur.atom")
+	atom = atomFeed{}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &atom); rec.Code != http.StatusOK || err != nil || len(atom.Entries) != 0 {
+		t.Errorf("empty feed: %d, %v, %d entries", rec.Code, err, len(atom.Entries))
+	}
+}
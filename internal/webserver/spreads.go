@@ -0,0 +1,121 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+// handleSpreadsPage ranks banks by current spread and shows the spread trend with volatile days.
+// Query: currency, channel, city, from, to.
+func (s *Server) handleSpreadsPage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSpreadFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.CurrencyCode == "" {
+		filter.CurrencyCode = "USD"
+	}
+	if filter.EndDate.IsZero() {
+		filter.EndDate = time.Now().UTC()
+	}
+	if filter.StartDate.IsZero() || !filter.StartDate.Before(filter.EndDate) {
+		filter.StartDate = filter.EndDate.AddDate(0, 0, -historyDefaultDays)
+	}
+	form := web.SpreadsForm{
+		Currency:   filter.CurrencyCode,
+		Channel:    filter.Channel,
+		City:       r.URL.Query().Get("city"),
+		From:       filter.StartDate.Format(apiDateLayout),
+		To:         filter.EndDate.Format(apiDateLayout),
+		Currencies: s.currencies(false),
+		Cities:     s.cities(r.Context()),
+	}
+
+	names := map[string]string{}
+	for _, info := range s.uc.Sources() {
+		names[info.ID] = info.DisplayName()
+	}
+	name := func(source, branch string) string {
+		if branch != "" {
+			return branch
+		}
+		if n := names[source]; n != "" {
+			return n
+		}
+		return source
+	}
+
+	spreads, err := s.uc.Spreads(r.Context(), &exrate.ExchangeRateFilter{
+		CurrencyCode: filter.CurrencyCode,
+		Channel:      filter.Channel,
+		City:         form.City,
+	})
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("get spreads failed", "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	ranking := make([]web.SpreadRow, 0, len(spreads))
+	for _, sp := range spreads {
+		ranking = append(ranking, web.SpreadRow{
+			Name:    name(sp.Source, entity.BranchLabel(sp.Branch, sp.BranchName)),
+			Channel: sp.Channel,
+			City:    sp.City,
+			Buy:     sp.Buy,
+			Sell:    sp.Sell,
+			Abs:     sp.Abs,
+			Pct:     sp.Pct,
+			Age:     now.Sub(sp.At),
+		})
+	}
+
+	history, err := s.uc.SpreadHistory(r.Context(), filter)
+	if errors.Is(err, internalErrors.ErrNotFound) {
+		history, err = &entity.SpreadHistory{}, nil
+	}
+	if err != nil {
+		s.l.Error("get spread history failed", "err", err)
+		http.Error(w, internalErrors.ErrInternal.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Линия на каждую серию: средний спред дня в процентах
+	var series []web.ChartSeries
+	var source, channel string
+	for _, d := range history.Days {
+		if len(series) == 0 || d.Source != source || d.Channel != channel {
+			source, channel = d.Source, d.Channel
+			label := name(source, "")
+			if filter.Channel == "" && channel != "" {
+				label += " · " + channel
+			}
+			series = append(series, web.ChartSeries{Name: label})
+		}
+		last := &series[len(series)-1]
+		last.Points = append(last.Points, web.ChartPoint{At: d.Day, Value: d.AvgPct})
+	}
+
+	volatile := make([]web.VolatileDay, 0, len(history.VolatileDays))
+	for _, v := range history.VolatileDays {
+		row := web.VolatileDay{Day: v.Day, MovePct: v.OfficialMovePct}
+		for _, wd := range v.Widened {
+			row.Widened = append(row.Widened, web.SpreadWidening{
+				Name:        name(wd.Source, ""),
+				Channel:     wd.Channel,
+				AvgPct:      wd.AvgPct,
+				BaselinePct: wd.BaselinePct,
+			})
+		}
+		volatile = append(volatile, row)
+	}
+
+	web.RenderHTML(w, r, web.SpreadsPage(form, ranking, web.NewChart(series, filter.StartDate, filter.EndDate), volatile))
+}
@@ -0,0 +1,196 @@
+package webserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	"github.com/Mi7teR/exr/internal/service/pubsub"
+	"github.com/Mi7teR/exr/internal/web"
+)
+
+const (
+	// streamBuffer is how many batches of rates a stream subscriber may fall behind by.
+	streamBuffer = 16
+	// streamHeartbeat keeps idle streams open through proxies.
+	streamHeartbeat = 15 * time.Second
+)
+
+// RateStream delivers rates as ingestion stores them.
+type RateStream interface {
+	Subscribe(buffer int) *pubsub.Subscription
+}
+
+// SetRateStream enables live updates: SSE endpoints and the live bank table.
+func (s *Server) SetRateStream(rs RateStream) {
+	s.live = rs
+}
+
+// liveURL is the address of the bank table stream for the page, empty without a stream.
+func (s *Server) liveURL(currency, city string) string {
+	if s.live == nil {
+		return ""
+	}
+	u := "/c/" + url.PathEscape(currency) + "/stream"
+	if city != "" {
+		u += "?city=" + url.QueryEscape(city)
+	}
+	return u
+}
+
+// sseWriter writes Server-Sent Events and flushes each one.
+type sseWriter struct {
+	w http.ResponseWriter
+	f http.Flusher
+}
+
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, bool) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx иначе буферизует поток
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &sseWriter{w: w, f: f}, true
+}
+
+// event sends a named event; every line of data becomes a data field.
+func (s *sseWriter) event(name string, data []byte) error {
+	var b bytes.Buffer
+	b.WriteString("event: " + name + "\n")
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		b.WriteString("data: " + strings.TrimRight(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
+
+// ping sends a comment line that clients ignore.
+func (s *sseWriter) ping() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
+
+// streamRateDTO is a stored rate with its change against the previous one.
+type streamRateDTO struct {
+	rateDTO
+	BuyChange  float64 `json:"buy_change"`
+	SellChange float64 `json:"sell_change"`
+}
+
+func newStreamRateDTO(r *entity.ExchangeRate) streamRateDTO {
+	return streamRateDTO{rateDTO: newRateDTO(r), BuyChange: r.BuyChangePrev, SellChange: r.SellChangePrev}
+}
+
+// handleAPIRatesStream streams "rates" events with JSON arrays of newly stored rates.
+// Query: currency, source, channel.
+func (s *Server) handleAPIRatesStream(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	currency, source, channel := q.Get("currency"), q.Get("source"), q.Get("channel")
+	match := func(rate *entity.ExchangeRate) bool {
+		return (currency == "" || strings.EqualFold(rate.CurrencyCode, currency)) &&
+			(source == "" || strings.EqualFold(rate.Source, source)) &&
+			(channel == "" || strings.EqualFold(rate.Channel, channel))
+	}
+
+	s.stream(w, r, func(sse *sseWriter, rates []*entity.ExchangeRate) error {
+		out := make([]streamRateDTO, 0, len(rates))
+		for _, rate := range rates {
+			if match(rate) {
+				out = append(out, newStreamRateDTO(rate))
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		return sse.event("rates", data)
+	})
+}
+
+// handleBanksStream streams "banks" events with the re-rendered bank table of the currency
+// page whenever its currency changes. Query: city.
+func (s *Server) handleBanksStream(w http.ResponseWriter, r *http.Request) {
+	currency := chi.URLParam(r, "currency")
+	city := r.URL.Query().Get("city")
+
+	s.stream(w, r, func(sse *sseWriter, rates []*entity.ExchangeRate) error {
+		changed := false
+		for _, rate := range rates {
+			if strings.EqualFold(rate.CurrencyCode, currency) && (city == "" || rate.City == "" || strings.EqualFold(rate.City, city)) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return nil
+		}
+		banks, err := s.gatherBanks(r.Context(), currency, city)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err = web.UniversalRatesView(banks, currency).Render(r.Context(), &buf); err != nil {
+			return err
+		}
+		return sse.event("banks", buf.Bytes())
+	})
+}
+
+// stream subscribes to stored rates and passes every batch to send until the client goes away.
+func (s *Server) stream(
+	w http.ResponseWriter,
+	r *http.Request,
+	send func(sse *sseWriter, rates []*entity.ExchangeRate) error,
+) {
+	sse, ok := newSSEWriter(w)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	sub := s.live.Subscribe(streamBuffer)
+	defer sub.Close()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := sse.ping(); err != nil {
+				return
+			}
+		case rates, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if err := send(sse, rates); err != nil {
+				s.l.Warn("stream send failed", "path", r.URL.Path, "err", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package webserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+)
+
+// defaultDeliveryLimit is the number of deliveries in the log by default.
+const defaultDeliveryLimit = 50
+
+// WebhookService manages webhook subscriptions and their delivery log.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, w *entity.Webhook) error
+	Webhooks(ctx context.Context) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	Deliveries(ctx context.Context, webhookID int64, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+// SetWebhooks enables the webhook admin endpoints.
+func (s *Server) SetWebhooks(ws WebhookService) {
+	s.webhooks = ws
+}
+
+// webhookDTO is the JSON representation of a webhook; the secret is shown only on creation.
+type webhookDTO struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookDTO(w *entity.Webhook) webhookDTO {
+	return webhookDTO{
+		ID:        w.ID,
+		URL:       w.URL,
+		Currency:  w.CurrencyCode,
+		Source:    w.Source,
+		Channel:   w.Channel,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// deliveryDTO is the JSON representation of a delivery log entry.
+type deliveryDTO struct {
+	ID            int64           `json:"id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatus    int             `json:"last_status,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// handleAPIWebhooks lists webhook subscriptions without their secrets.
+func (s *Server) handleAPIWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := s.webhooks.Webhooks(r.Context())
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api webhooks failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]webhookDTO, 0, len(hooks))
+	for _, h := range hooks {
+		out = append(out, newWebhookDTO(h))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleAPICreateWebhook subscribes a receiver; the response carries the signing secret.
+func (s *Server) handleAPICreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var in webhookDTO
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertRuleBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", internalErrors.ErrInvalidArgument, err))
+		return
+	}
+	hook := &entity.Webhook{
+		URL:          in.URL,
+		Secret:       in.Secret,
+		CurrencyCode: in.Currency,
+		Source:       in.Source,
+		Channel:      in.Channel,
+	}
+	err := s.webhooks.CreateWebhook(r.Context(), hook)
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidArgument):
+		writeJSONError(w, http.StatusBadRequest, err)
+	case err != nil:
+		s.l.Error("api create webhook failed", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+	default:
+		out := newWebhookDTO(hook)
+		out.Secret = hook.Secret
+		writeJSON(w, http.StatusCreated, out)
+	}
+}
+
+// handleAPIDeleteWebhook unsubscribes a receiver and drops its queue.
+func (s *Server) handleAPIDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+	err = s.webhooks.DeleteWebhook(r.Context(), id)
+	switch {
+	case errors.Is(err, internalErrors.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err)
+	case err != nil:
+		s.l.Error("api delete webhook failed", "id", id, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAPIWebhookDeliveries returns the delivery log of a webhook, newest first. Query: limit.
+func (s *Server) handleAPIWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+		return
+	}
+	limit := defaultDeliveryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			writeJSONError(w, http.StatusBadRequest, internalErrors.ErrInvalidArgument)
+			return
+		}
+	}
+
+	deliveries, err := s.webhooks.Deliveries(r.Context(), id, limit)
+	if err != nil && !errors.Is(err, internalErrors.ErrNotFound) {
+		s.l.Error("api webhook deliveries failed", "id", id, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, internalErrors.ErrInternal)
+		return
+	}
+	out := make([]deliveryDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		dto := deliveryDTO{
+			ID:         d.ID,
+			Event:      d.Event,
+			Status:     d.Status,
+			Attempts:   d.Attempts,
+			LastStatus: d.LastStatus,
+			LastError:  d.LastError,
+			CreatedAt:  d.CreatedAt,
+			Payload:    json.RawMessage(d.Payload),
+		}
+		if d.Status == entity.DeliveryPending {
+			next := d.NextAttemptAt
+			dto.NextAttemptAt = &next
+		}
+		if !d.DeliveredAt.IsZero() {
+			delivered := d.DeliveredAt
+			dto.DeliveredAt = &delivered
+		}
+		out = append(out, dto)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
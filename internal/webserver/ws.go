@@ -0,0 +1,267 @@
+package webserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Mi7teR/exr/internal/entity"
+	internalErrors "github.com/Mi7teR/exr/internal/errors"
+	"github.com/Mi7teR/exr/internal/service/exrate"
+)
+
+const (
+	// wsWriteWait is how long a message may take to reach a client; a slower client is disconnected.
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is how long a client may stay silent, pongs included.
+	wsPongWait = 2 * streamHeartbeat
+	// wsMaxMessage caps client messages.
+	wsMaxMessage = 16 << 10
+	// wsMaxFilters caps subscriptions of one connection.
+	wsMaxFilters = 50
+)
+
+// Message types of the WebSocket feed.
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+	wsPing        = "ping"
+	wsPong        = "pong"
+	wsSnapshot    = "snapshot"
+	wsUpdate      = "update"
+	wsHeartbeat   = "heartbeat"
+	wsError       = "error"
+)
+
+var wsUpgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 4096}
+
+// wsFilter selects rates by currency, source and channel; empty fields match any.
+type wsFilter struct {
+	Currency string `json:"currency,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+}
+
+func (f wsFilter) normalize() wsFilter {
+	return wsFilter{Currency: strings.ToUpper(f.Currency), Source: f.Source, Channel: strings.ToLower(f.Channel)}
+}
+
+func (f wsFilter) match(r *entity.ExchangeRate) bool {
+	return (f.Currency == "" || strings.EqualFold(r.CurrencyCode, f.Currency)) &&
+		(f.Source == "" || strings.EqualFold(r.Source, f.Source)) &&
+		(f.Channel == "" || strings.EqualFold(r.Channel, f.Channel))
+}
+
+// wsClientMessage is a message from a client.
+type wsClientMessage struct {
+	Type    string     `json:"type"`
+	Filters []wsFilter `json:"filters,omitempty"`
+
+	bad error // сообщение не разобралось
+}
+
+// wsServerMessage is a message to a client.
+type wsServerMessage struct {
+	Type    string          `json:"type"`
+	Filters []wsFilter      `json:"filters,omitempty"`
+	Rates   []streamRateDTO `json:"rates,omitempty"`
+	Resync  bool            `json:"resync,omitempty"` // снапшот после пропущенных обновлений
+	At      *time.Time      `json:"at,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// handleWebSocket serves the bidirectional feed: clients subscribe to currency/source/channel
+// filters, receive a snapshot of the latest matching rates and then every stored change.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Подписываемся до первого снапшота, чтобы не потерять изменения между ними
+	sub := s.live.Subscribe(streamBuffer)
+	defer sub.Close()
+
+	msgs := make(chan wsClientMessage)
+	go s.wsRead(ctx, cancel, conn, msgs)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var filters []wsFilter
+	dropped := sub.Dropped()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-msgs:
+			filters, err = s.wsHandle(ctx, conn, filters, msg)
+		case <-heartbeat.C:
+			now := time.Now().UTC()
+			if err = wsWrite(conn, wsServerMessage{Type: wsHeartbeat, At: &now}); err == nil {
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+			}
+		case rates, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			// Клиент не успевал читать и пропустил пачки: вместо них — свежий снапшот
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				if len(filters) > 0 {
+					err = s.wsSnapshot(ctx, conn, filters, true)
+				}
+				break
+			}
+			err = wsUpdateRates(conn, filters, rates)
+		}
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				s.l.Debug("websocket closed", "err", err)
+			}
+			return
+		}
+	}
+}
+
+// wsRead passes client messages to the connection loop and cancels ctx when the client goes away.
+func (s *Server) wsRead(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, msgs chan<- wsClientMessage) {
+	defer cancel()
+	conn.SetReadLimit(wsMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		var msg wsClientMessage
+		if err = json.Unmarshal(data, &msg); err != nil {
+			// Битый JSON не повод рвать соединение
+			msg = wsClientMessage{bad: err}
+		}
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// wsHandle applies a client message and returns the new filters.
+func (s *Server) wsHandle(ctx context.Context, conn *websocket.Conn, filters []wsFilter, msg wsClientMessage) ([]wsFilter, error) {
+	if msg.bad != nil {
+		return filters, wsWriteError(conn, fmt.Errorf("%w: %v", internalErrors.ErrInvalidArgument, msg.bad))
+	}
+	switch msg.Type {
+	case wsPing:
+		return filters, wsWrite(conn, wsServerMessage{Type: wsPong})
+	case wsSubscribe:
+		if len(msg.Filters) == 0 {
+			msg.Filters = []wsFilter{{}}
+		}
+		added := make([]wsFilter, 0, len(msg.Filters))
+		for _, f := range msg.Filters {
+			f = f.normalize()
+			if !containsFilter(filters, f) && !containsFilter(added, f) {
+				added = append(added, f)
+			}
+		}
+		if len(filters)+len(added) > wsMaxFilters {
+			return filters, wsWriteError(conn, fmt.Errorf("more than %d filters: %w", wsMaxFilters, internalErrors.ErrInvalidArgument))
+		}
+		filters = append(filters, added...)
+		return filters, s.wsSnapshot(ctx, conn, added, false)
+	case wsUnsubscribe:
+		kept := filters[:0]
+		for _, f := range filters {
+			if !containsFilter(msg.Filters, f) {
+				kept = append(kept, f)
+			}
+		}
+		if len(msg.Filters) == 0 {
+			kept = nil
+		}
+		return kept, wsWrite(conn, wsServerMessage{Type: wsUnsubscribe, Filters: kept})
+	}
+	return filters, wsWriteError(conn, fmt.Errorf("message type %q: %w", msg.Type, internalErrors.ErrInvalidArgument))
+}
+
+// wsSnapshot sends the latest rates matching the filters.
+func (s *Server) wsSnapshot(ctx context.Context, conn *websocket.Conn, filters []wsFilter, resync bool) error {
+	seen := map[entity.SeriesKey]struct{}{}
+	rates := make([]streamRateDTO, 0)
+	for _, f := range filters {
+		latest, err := s.uc.LatestRates(ctx, &exrate.ExchangeRateFilter{
+			CurrencyCode: f.Currency,
+			Source:       f.Source,
+			Channel:      f.Channel,
+		})
+		if errors.Is(err, internalErrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.l.Error("websocket snapshot failed", "filter", f, "err", err)
+			return wsWriteError(conn, internalErrors.ErrInternal)
+		}
+		for _, r := range latest {
+			key := r.Key()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			rates = append(rates, newStreamRateDTO(r))
+		}
+	}
+	return wsWrite(conn, wsServerMessage{Type: wsSnapshot, Filters: filters, Rates: rates, Resync: resync})
+}
+
+// wsUpdateRates sends the stored rates matching any filter.
+func wsUpdateRates(conn *websocket.Conn, filters []wsFilter, rates []*entity.ExchangeRate) error {
+	var out []streamRateDTO
+	for _, r := range rates {
+		for _, f := range filters {
+			if f.match(r) {
+				out = append(out, newStreamRateDTO(r))
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return wsWrite(conn, wsServerMessage{Type: wsUpdate, Rates: out})
+}
+
+func wsWrite(conn *websocket.Conn, msg wsServerMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(msg)
+}
+
+func wsWriteError(conn *websocket.Conn, err error) error {
+	return wsWrite(conn, wsServerMessage{Type: wsError, Error: err.Error()})
+}
+
+func containsFilter(filters []wsFilter, f wsFilter) bool {
+	for _, x := range filters {
+		if x.normalize() == f.normalize() {
+			return true
+		}
+	}
+	return false
+}